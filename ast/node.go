@@ -0,0 +1,425 @@
+/*
+Package ast declares types representing a JavaScript AST.
+
+The tree is produced by the parser package:
+
+	program, err := parser.ParseFile("example.js", `var abc = 1 + 1`)
+	if err != nil {
+		...
+	}
+	for _, statement := range program.Body {
+		...
+	}
+
+Every node records the position (line and column) at which it begins in the source.
+
+This interface is experimental and may change.
+*/
+package ast
+
+// Position is the location of a node in the source, where both Line and Column
+// are 1-based.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Pos returns the position itself, so that every node embedding a Position
+// satisfies the Node interface.
+func (self Position) Pos() Position {
+	return self
+}
+
+// All nodes implement the Node interface.
+type Node interface {
+	Pos() Position // The position of the first character belonging to the node
+}
+
+// ========== //
+// Expression //
+// ========== //
+
+type (
+	// All expression nodes implement the Expression interface.
+	Expression interface {
+		Node
+		_expressionNode()
+	}
+
+	// ArrayLiteral is [ ... ], where a missing element is an *EmptyExpression.
+	ArrayLiteral struct {
+		Position
+		Value []Expression
+	}
+
+	// AssignExpression is Left = Right, or a compound assignment like Left += Right.
+	AssignExpression struct {
+		Position
+		Operator string // "=", "+=", "-=", ...
+		Left     Expression
+		Right    Expression
+	}
+
+	// BinaryExpression is an arithmetic, bitwise, logical, relational or equality operation.
+	BinaryExpression struct {
+		Position
+		Operator string // "+", "&&", "===", "instanceof", ...
+		Left     Expression
+		Right    Expression
+	}
+
+	BooleanLiteral struct {
+		Position
+		Value bool
+	}
+
+	// BracketExpression is Left[Member].
+	BracketExpression struct {
+		Position
+		Left   Expression
+		Member Expression
+	}
+
+	CallExpression struct {
+		Position
+		Callee       Expression
+		ArgumentList []Expression
+	}
+
+	// ConditionalExpression is Test ? Consequent : Alternate.
+	ConditionalExpression struct {
+		Position
+		Test       Expression
+		Consequent Expression
+		Alternate  Expression
+	}
+
+	// DotExpression is Left.Member.
+	DotExpression struct {
+		Position
+		Left   Expression
+		Member string
+	}
+
+	// EmptyExpression is an elided element in an array literal, e.g. [ 1, , 3 ].
+	EmptyExpression struct {
+		Position
+	}
+
+	// FunctionLiteral is a function declaration or expression.
+	// Name is nil for an anonymous function.
+	FunctionLiteral struct {
+		Position
+		Name            *Identifier
+		ParameterList   []*Identifier
+		Body            *BlockStatement
+		DeclarationList []Declaration
+		Strict          bool // Whether the function is strict mode code (see Program)
+	}
+
+	Identifier struct {
+		Position
+		Name string
+	}
+
+	NewExpression struct {
+		Position
+		Callee       Expression
+		ArgumentList []Expression
+	}
+
+	NullLiteral struct {
+		Position
+	}
+
+	// NumberLiteral holds the literal as it appears in the source, e.g. 0x1F or 1e10.
+	NumberLiteral struct {
+		Position
+		Literal string
+	}
+
+	ObjectLiteral struct {
+		Position
+		Value []*Property
+	}
+
+	RegExpLiteral struct {
+		Position
+		Pattern string
+		Flags   string
+	}
+
+	// SequenceExpression is a comma-separated list of expressions.
+	SequenceExpression struct {
+		Position
+		Sequence []Expression
+	}
+
+	// StringLiteral holds the (unescaped) value of the string.
+	StringLiteral struct {
+		Position
+		Literal string // The source of the string, with its quotes (and escapes)
+		Value   string
+	}
+
+	ThisExpression struct {
+		Position
+	}
+
+	// UnaryExpression is a prefix (or, for ++ and --, postfix) operation.
+	UnaryExpression struct {
+		Position
+		Operator string // "!", "-", "typeof", "++", ...
+		Operand  Expression
+		Postfix  bool
+	}
+
+	// VariableExpression is a single declaration from a var statement,
+	// or the left-hand side of for (var ... in ...).
+	VariableExpression struct {
+		Position
+		Name        string
+		Initializer Expression // nil if there is no initializer
+	}
+)
+
+// Property is a single key: value pair of an object literal.
+// Key is an *Identifier, *StringLiteral, or *NumberLiteral.
+type Property struct {
+	Position
+	Key   Expression
+	Kind  string     // "value", or "get" or "set" (for an accessor)
+	Value Expression // A *FunctionLiteral, for an accessor
+}
+
+// _expressionNode
+
+func (*ArrayLiteral) _expressionNode()          {}
+func (*AssignExpression) _expressionNode()      {}
+func (*BinaryExpression) _expressionNode()      {}
+func (*BooleanLiteral) _expressionNode()        {}
+func (*BracketExpression) _expressionNode()     {}
+func (*CallExpression) _expressionNode()        {}
+func (*ConditionalExpression) _expressionNode() {}
+func (*DotExpression) _expressionNode()         {}
+func (*EmptyExpression) _expressionNode()       {}
+func (*FunctionLiteral) _expressionNode()       {}
+func (*Identifier) _expressionNode()            {}
+func (*NewExpression) _expressionNode()         {}
+func (*NullLiteral) _expressionNode()           {}
+func (*NumberLiteral) _expressionNode()         {}
+func (*ObjectLiteral) _expressionNode()         {}
+func (*RegExpLiteral) _expressionNode()         {}
+func (*SequenceExpression) _expressionNode()    {}
+func (*StringLiteral) _expressionNode()         {}
+func (*ThisExpression) _expressionNode()        {}
+func (*UnaryExpression) _expressionNode()       {}
+func (*VariableExpression) _expressionNode()    {}
+
+// ========= //
+// Statement //
+// ========= //
+
+type (
+	// All statement nodes implement the Statement interface.
+	Statement interface {
+		Node
+		_statementNode()
+	}
+
+	BlockStatement struct {
+		Position
+		List []Statement
+	}
+
+	// BreakStatement is break, with an optional Label.
+	BreakStatement struct {
+		Position
+		Label *Identifier
+	}
+
+	// CaseStatement is a clause of a switch statement. Test is nil for the default clause.
+	CaseStatement struct {
+		Position
+		Test       Expression
+		Consequent []Statement
+	}
+
+	CatchStatement struct {
+		Position
+		Parameter *Identifier
+		Body      *BlockStatement
+	}
+
+	// ContinueStatement is continue, with an optional Label.
+	ContinueStatement struct {
+		Position
+		Label *Identifier
+	}
+
+	// DebuggerStatement is debugger, which pauses execution if a debugger is attached.
+	DebuggerStatement struct {
+		Position
+	}
+
+	DoWhileStatement struct {
+		Position
+		Test Expression
+		Body Statement
+	}
+
+	EmptyStatement struct {
+		Position
+	}
+
+	ExpressionStatement struct {
+		Position
+		Expression Expression
+	}
+
+	// ForInStatement is for (Into in Source) Body, where Into is an
+	// *Identifier, *DotExpression, *BracketExpression, or *VariableExpression.
+	ForInStatement struct {
+		Position
+		Into   Expression
+		Source Expression
+		Body   Statement
+	}
+
+	// ForStatement is for (Initializer; Test; Update) Body, where Initializer
+	// is nil, an Expression, or a *VariableStatement.
+	ForStatement struct {
+		Position
+		Initializer Node
+		Test        Expression
+		Update      Expression
+		Body        Statement
+	}
+
+	// FunctionStatement is a function declaration appearing in a statement list.
+	FunctionStatement struct {
+		Position
+		Function *FunctionLiteral
+	}
+
+	IfStatement struct {
+		Position
+		Test       Expression
+		Consequent Statement
+		Alternate  Statement // nil if there is no else
+	}
+
+	LabelledStatement struct {
+		Position
+		Label     *Identifier
+		Statement Statement
+	}
+
+	ReturnStatement struct {
+		Position
+		Argument Expression // nil if there is no argument
+	}
+
+	// SwitchStatement is switch (Discriminant) { ... }, where Default is the index
+	// of the default clause in Body (or -1 if there is none).
+	SwitchStatement struct {
+		Position
+		Discriminant Expression
+		Default      int
+		Body         []*CaseStatement
+	}
+
+	ThrowStatement struct {
+		Position
+		Argument Expression
+	}
+
+	// TryStatement is try Body, followed by Catch and/or Finally.
+	TryStatement struct {
+		Position
+		Body    *BlockStatement
+		Catch   *CatchStatement
+		Finally *BlockStatement
+	}
+
+	VariableStatement struct {
+		Position
+		List []*VariableExpression
+	}
+
+	WhileStatement struct {
+		Position
+		Test Expression
+		Body Statement
+	}
+
+	WithStatement struct {
+		Position
+		Object Expression
+		Body   Statement
+	}
+)
+
+// _statementNode
+
+func (*BlockStatement) _statementNode()      {}
+func (*BreakStatement) _statementNode()      {}
+func (*CaseStatement) _statementNode()       {}
+func (*CatchStatement) _statementNode()      {}
+func (*ContinueStatement) _statementNode()   {}
+func (*DebuggerStatement) _statementNode()   {}
+func (*DoWhileStatement) _statementNode()    {}
+func (*EmptyStatement) _statementNode()      {}
+func (*ExpressionStatement) _statementNode() {}
+func (*ForInStatement) _statementNode()      {}
+func (*ForStatement) _statementNode()        {}
+func (*FunctionStatement) _statementNode()   {}
+func (*IfStatement) _statementNode()         {}
+func (*LabelledStatement) _statementNode()   {}
+func (*ReturnStatement) _statementNode()     {}
+func (*SwitchStatement) _statementNode()     {}
+func (*ThrowStatement) _statementNode()      {}
+func (*TryStatement) _statementNode()        {}
+func (*VariableStatement) _statementNode()   {}
+func (*WhileStatement) _statementNode()      {}
+func (*WithStatement) _statementNode()       {}
+
+// =========== //
+// Declaration //
+// =========== //
+
+type (
+	// A Declaration is hoisted to the top of the enclosing program or function.
+	Declaration interface {
+		_declarationNode()
+	}
+
+	FunctionDeclaration struct {
+		Function *FunctionLiteral
+	}
+
+	VariableDeclaration struct {
+		List []*VariableExpression
+	}
+)
+
+// _declarationNode
+
+func (*FunctionDeclaration) _declarationNode() {}
+func (*VariableDeclaration) _declarationNode() {}
+
+// ==== //
+// Node //
+// ==== //
+
+// Program is the root of the tree for a single source (file).
+type Program struct {
+	Position
+	Filename        string
+	Body            []Statement
+	DeclarationList []Declaration
+
+	// Strict is whether the program begins with a "use strict" directive. A
+	// function within strict code (or beginning with the directive) is also strict.
+	Strict bool
+}
@@ -1,8 +1,8 @@
 package otto
 
 import (
+	"bytes"
 	"strconv"
-	"strings"
 )
 
 // Array
@@ -42,7 +42,7 @@ func builtinArray_toLocaleString(call FunctionCall) Value {
 	if length == 0 {
 		return toValue_string("")
 	}
-	stringList := make([]string, 0, length)
+	var result bytes.Buffer
 	for index := int64(0); index < length; index += 1 {
 		value := thisObject.get(arrayIndexToString(index))
 		stringValue := ""
@@ -56,9 +56,13 @@ func builtinArray_toLocaleString(call FunctionCall) Value {
 			}
 			stringValue = toLocaleString.call(toValue_object(object)).toString()
 		}
-		stringList = append(stringList, stringValue)
+		call.runtime.allocate(len(stringValue) + len(separator))
+		if index > 0 {
+			result.WriteString(separator)
+		}
+		result.WriteString(stringValue)
 	}
-	return toValue_string(strings.Join(stringList, separator))
+	return toValue_string(result.String())
 }
 
 func builtinArray_concat(call FunctionCall) Value {
@@ -151,7 +155,7 @@ func builtinArray_join(call FunctionCall) Value {
 	if length == 0 {
 		return toValue_string("")
 	}
-	stringList := make([]string, 0, length)
+	var result bytes.Buffer
 	for index := int64(0); index < length; index += 1 {
 		value := thisObject.get(arrayIndexToString(index))
 		stringValue := ""
@@ -160,9 +164,13 @@ func builtinArray_join(call FunctionCall) Value {
 		default:
 			stringValue = toString(value)
 		}
-		stringList = append(stringList, stringValue)
+		call.runtime.allocate(len(stringValue) + len(separator))
+		if index > 0 {
+			result.WriteString(separator)
+		}
+		result.WriteString(stringValue)
 	}
-	return toValue_string(strings.Join(stringList, separator))
+	return toValue_string(result.String())
 }
 
 func builtinArray_splice(call FunctionCall) Value {
@@ -1,7 +1,6 @@
 package otto
 
 import (
-	"regexp"
 	"strings"
 	"unicode"
 )
@@ -27,27 +26,16 @@ func argumentList2parameterList(argumentList []Value) []string {
 	return parameterList
 }
 
-var matchIdentifier = regexp.MustCompile(`^[$_\p{L}][$_\p{L}\d}]*$`)
-
 func builtinNewFunctionNative(runtime *_runtime, argumentList []Value) *_object {
 	parameterList := []string(nil)
 	bodySource := ""
 	argumentCount := len(argumentList)
 	if argumentCount > 0 {
 		parameterList = argumentList2parameterList(argumentList[0 : argumentCount-1])
-		for _, value := range parameterList {
-			// TODO: This is extremely hacky... maybe go through the parser directly?
-			if !matchIdentifier.MatchString(value) || keywordTable[value] {
-				panic(newSyntaxError(value))
-			}
-		}
 		bodySource = toString(argumentList[argumentCount-1])
 	}
 
-	parser := newParser()
-	parser.lexer.Source = bodySource
-	_programNode := parser.ParseAsFunction()
-	return runtime.newNodeFunction(_programNode.toFunction(parameterList), runtime.GlobalEnvironment)
+	return runtime.newNodeFunction(parseFunction(parameterList, bodySource), runtime.GlobalEnvironment)
 }
 
 func builtinFunction_toString(call FunctionCall) Value {
@@ -184,10 +184,12 @@ func builtinJSON_stringify(call FunctionCall) Value {
 	if err != nil {
 		panic(newTypeError(err.Error()))
 	}
+	call.runtime.allocate(len(valueJSON))
 	if ctx.gap != "" {
 		valueJSON1 := bytes.Buffer{}
 		json.Indent(&valueJSON1, valueJSON, "", ctx.gap)
 		valueJSON = valueJSON1.Bytes()
+		call.runtime.allocate(len(valueJSON))
 	}
 	return toValue_string(string(valueJSON))
 }
@@ -216,18 +218,25 @@ func builtinJSON_stringifyWalk(ctx _builtinJSON_stringifyContext, key string, ho
 		}
 	}
 
+	// The (Go) value of the walk, and the JSON it becomes, count toward the memory limit
+	runtime := ctx.call.runtime
 	switch value._valueType {
 	case valueBoolean:
+		runtime.allocate(valueSize)
 		return toBoolean(value), true
 	case valueString:
-		return toString(value), true
+		value := toString(value)
+		runtime.allocate(valueSize + len(value))
+		return value, true
 	case valueNumber:
 		value := toFloat(value)
+		runtime.allocate(valueSize)
 		if math.IsNaN(value) || math.IsInf(value, 0) {
 			return nil, true
 		}
 		return value, true
 	case valueNull:
+		runtime.allocate(valueSize)
 		return nil, true
 	case valueObject:
 		holder := value._object()
@@ -242,6 +251,7 @@ func builtinJSON_stringifyWalk(ctx _builtinJSON_stringifyContext, key string, ho
 		}
 		if isArray(holder) {
 			length := holder.get("length").value.(uint32)
+			runtime.allocate(int(length) * valueSize)
 			array := make([]interface{}, length)
 			for index, _ := range array {
 				name := arrayIndexToString(int64(index))
@@ -255,6 +265,7 @@ func builtinJSON_stringifyWalk(ctx _builtinJSON_stringifyContext, key string, ho
 				for _, name := range ctx.propertyList {
 					value, exists := builtinJSON_stringifyWalk(ctx, name, holder)
 					if exists {
+						runtime.allocate(valueSize + len(name))
 						object[name] = value
 					}
 				}
@@ -264,6 +275,7 @@ func builtinJSON_stringifyWalk(ctx _builtinJSON_stringifyContext, key string, ho
 				holder.enumerate(false, func(name string) bool {
 					value, exists := builtinJSON_stringifyWalk(ctx, name, holder)
 					if exists {
+						runtime.allocate(valueSize + len(name))
 						object[name] = value
 					}
 					return true
@@ -69,6 +69,7 @@ func builtinString_concat(call FunctionCall) Value {
 	for _, item := range call.ArgumentList {
 		value.WriteString(toString(item))
 	}
+	call.runtime.allocate(value.Len())
 	return toValue_string(value.String())
 }
 
@@ -150,7 +151,7 @@ func builtinString_match(call FunctionCall) Value {
 		for index := 0; index < matchCount; index++ {
 			valueArray[index] = toValue_string(target[result[index][0]:result[index][1]])
 		}
-		matcher.put("lastIndex", toValue_int(result[matchCount-1][1]), true)
+		matcher.put("lastIndex", toValue_int(call.runtime.utf16Index(target, result[matchCount-1][1])), true)
 		return toValue_object(call.runtime.newArrayOf(valueArray))
 	}
 }
@@ -197,7 +198,7 @@ func builtinString_replace(call FunctionCall) Value {
 	searchObject := searchValue._object()
 
 	// TODO If a capture is -1?
-	var search *regexp.Regexp
+	var search _regExpMatcher
 	global := false
 	find := 1
 	if searchValue.IsObject() && searchObject.class == "RegExp" {
@@ -210,7 +211,7 @@ func builtinString_replace(call FunctionCall) Value {
 		search = regexp.MustCompile(regexp.QuoteMeta(toString(searchValue)))
 	}
 
-	found := search.FindAllSubmatchIndex(target, find)
+	found := search.FindAllStringSubmatchIndex(string(target), find)
 	if found == nil {
 		return toValue_string(string(target)) // !match
 	}
@@ -218,6 +219,11 @@ func builtinString_replace(call FunctionCall) Value {
 	{
 		lastIndex := 0
 		result := []byte{}
+		charged := 0
+		allocate := func() {
+			call.runtime.allocate(len(result) - charged)
+			charged = len(result)
+		}
 
 		replaceValue := call.Argument(1)
 		if replaceValue.isCallable() {
@@ -241,6 +247,7 @@ func builtinString_replace(call FunctionCall) Value {
 				argumentList[matchCount+1] = toValue_string(target)
 				replacement := toString(replace.Call(UndefinedValue(), argumentList))
 				result = append(result, []byte(replacement)...)
+				allocate()
 				lastIndex = match[1]
 			}
 
@@ -248,12 +255,14 @@ func builtinString_replace(call FunctionCall) Value {
 			replace := []byte(toString(replaceValue))
 			for _, match := range found {
 				result = builtinString_findAndReplaceString(result, lastIndex, match, target, replace)
+				allocate()
 				lastIndex = match[1]
 			}
 		}
 
 		if lastIndex != len(target) {
 			result = append(result, target[lastIndex:]...)
+			allocate()
 		}
 
 		if global && searchObject != nil {
@@ -316,6 +325,7 @@ func builtinString_split(call FunctionCall) Value {
 		search := separatorValue._object().regExpValue().regularExpression
 		valueArray := []Value{}
 		result := search.FindAllStringSubmatchIndex(target, -1)
+		call.runtime.allocate(len(result) * valueSize)
 		lastIndex := 0
 		found := 0
 
@@ -378,6 +388,11 @@ func builtinString_split(call FunctionCall) Value {
 			excess = true
 		}
 
+		count := strings.Count(target, separator) + 1
+		if splitLimit > 0 && count > splitLimit {
+			count = splitLimit
+		}
+		call.runtime.allocate(count * valueSize)
 		split := strings.SplitN(target, separator, splitLimit)
 
 		if excess && len(split) > limit {
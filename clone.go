@@ -64,6 +64,21 @@ func (runtime *_runtime) clone() *_runtime {
 
 	self.EnterGlobalExecutionContext()
 
+	self.limits = runtime.limits
+	self.fieldNameMapper = runtime.fieldNameMapper
+	if runtime.console != nil {
+		self.console = newRuntimeConsole(runtime.console.Console)
+	}
+	if runtime.modules != nil {
+		self.modules = newModuleSystem(runtime.modules.loader)
+		for filename, module := range runtime.modules.cache {
+			self.modules.cache[filename] = clone.object(module)
+		}
+		for name, exports := range runtime.modules.native {
+			self.modules.native[name] = clone.object(exports)
+		}
+	}
+
 	self.eval = self.GlobalObject.property["eval"].value.(Value).value.(*_object)
 	self.GlobalObject.prototype = self.Global.ObjectPrototype
 
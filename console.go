@@ -2,50 +2,244 @@ package otto
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 )
 
+// ConsoleLevel is the level of a message written to the console.
+type ConsoleLevel int
+
+const (
+	ConsoleDebug ConsoleLevel = iota // console.debug
+	ConsoleLog                       // console.log, console.dir, console.time, console.count, ...
+	ConsoleInfo                      // console.info
+	ConsoleWarn                      // console.warn
+	ConsoleError                     // console.error, console.assert, console.trace
+)
+
+func (self ConsoleLevel) String() string {
+	switch self {
+	case ConsoleDebug:
+		return "debug"
+	case ConsoleLog:
+		return "log"
+	case ConsoleInfo:
+		return "info"
+	case ConsoleWarn:
+		return "warn"
+	case ConsoleError:
+		return "error"
+	}
+	return fmt.Sprintf("ConsoleLevel(%d)", int(self))
+}
+
+// Console is the destination of everything written by the console object of
+// a runtime (console.log, console.error, etc.)
+//
+// The message is already formatted (and indented, if within a console.group),
+// but does not have a trailing newline.
+//
+//		type logConsole struct{}
+//
+//		func (logConsole) Print(level otto.ConsoleLevel, message string) {
+//			log.Printf("[%s] %s", level, message)
+//		}
+//
+//		Otto.SetConsole(logConsole{})
+type Console interface {
+	Print(level ConsoleLevel, message string)
+}
+
+// NewConsole returns a Console that writes debug, log, and info messages to stdout,
+// and warn and error messages to stderr.
+//
+// This is the console of a new runtime, writing to os.Stdout and os.Stderr.
+//
+// Note that this is a change: console.warn and console.error used to write to
+// stdout, along with everything else. To keep every message on stdout:
+//
+//		Otto.SetConsole(otto.NewConsole(os.Stdout, os.Stdout))
+func NewConsole(stdout, stderr io.Writer) Console {
+	return &_writerConsole{
+		stdout: stdout,
+		stderr: stderr,
+	}
+}
+
+type _writerConsole struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (self *_writerConsole) Print(level ConsoleLevel, message string) {
+	switch level {
+	case ConsoleWarn, ConsoleError:
+		fmt.Fprintln(self.stderr, message)
+	default:
+		fmt.Fprintln(self.stdout, message)
+	}
+}
+
+// SetConsole sets the destination of the console object of the runtime, or
+// restores the default (NewConsole(os.Stdout, os.Stderr)) if console is nil.
+func (self Otto) SetConsole(console Console) {
+	if console == nil {
+		console = NewConsole(os.Stdout, os.Stderr)
+	}
+	self.runtime.console.Console = console
+}
+
+// _console is the Console of a runtime, and the state of the console object
+// (timers, counters, and groups)
+type _console struct {
+	Console
+	timer map[string]time.Time
+	count map[string]int
+	group int // The depth of console.group
+}
+
+func newRuntimeConsole(console Console) *_console {
+	return &_console{
+		Console: console,
+		timer:   map[string]time.Time{},
+		count:   map[string]int{},
+	}
+}
+
+func (self *_console) print(level ConsoleLevel, message string) {
+	if self.group > 0 {
+		indent := strings.Repeat("  ", self.group)
+		message = indent + strings.Replace(message, "\n", "\n"+indent, -1)
+	}
+	self.Print(level, message)
+}
+
+// formatForConsole formats the arguments as console.log does: a string
+// is shown as-is, while anything else is inspected (see Value.Inspect)
 func formatForConsole(argumentList []Value) string {
 	output := []string{}
 	for _, argument := range argumentList {
-		output = append(output, fmt.Sprintf("%v", argument))
+		if argument.IsString() {
+			output = append(output, argument.toString())
+			continue
+		}
+		output = append(output, argument.Inspect(InspectOptions{}))
 	}
 	return strings.Join(output, " ")
 }
 
+// consoleLabel is the label (the first argument) of console.time, console.count, etc.
+func consoleLabel(call FunctionCall) string {
+	label := call.Argument(0)
+	if label.IsUndefined() {
+		return "default"
+	}
+	return toString(label)
+}
+
 func builtinConsole_log(call FunctionCall) Value {
-	fmt.Fprintln(os.Stdout, formatForConsole(call.ArgumentList))
+	call.runtime.console.print(ConsoleLog, formatForConsole(call.ArgumentList))
+	return UndefinedValue()
+}
+
+func builtinConsole_debug(call FunctionCall) Value {
+	call.runtime.console.print(ConsoleDebug, formatForConsole(call.ArgumentList))
+	return UndefinedValue()
+}
+
+func builtinConsole_info(call FunctionCall) Value {
+	call.runtime.console.print(ConsoleInfo, formatForConsole(call.ArgumentList))
 	return UndefinedValue()
 }
 
 func builtinConsole_error(call FunctionCall) Value {
-	fmt.Fprintln(os.Stdout, formatForConsole(call.ArgumentList))
+	call.runtime.console.print(ConsoleError, formatForConsole(call.ArgumentList))
+	return UndefinedValue()
+}
+
+func builtinConsole_warn(call FunctionCall) Value {
+	call.runtime.console.print(ConsoleWarn, formatForConsole(call.ArgumentList))
 	return UndefinedValue()
 }
 
-// Nothing happens.
 func builtinConsole_dir(call FunctionCall) Value {
+	call.runtime.console.print(ConsoleLog, call.Argument(0).Inspect(InspectOptions{}))
 	return UndefinedValue()
 }
 
 func builtinConsole_time(call FunctionCall) Value {
+	call.runtime.console.timer[consoleLabel(call)] = time.Now()
 	return UndefinedValue()
 }
 
 func builtinConsole_timeEnd(call FunctionCall) Value {
+	console := call.runtime.console
+	label := consoleLabel(call)
+	start, exists := console.timer[label]
+	if !exists {
+		console.print(ConsoleWarn, fmt.Sprintf("No such label: %s", label))
+		return UndefinedValue()
+	}
+	delete(console.timer, label)
+	duration := time.Since(start)
+	console.print(ConsoleLog, fmt.Sprintf("%s: %.3fms", label, float64(duration)/float64(time.Millisecond)))
 	return UndefinedValue()
 }
 
 func builtinConsole_trace(call FunctionCall) Value {
+	message := "Trace"
+	if len(call.ArgumentList) > 0 {
+		message += ": " + formatForConsole(call.ArgumentList)
+	}
+	for _, frame := range call.runtime.stackTrace() {
+		message += "\n    at " + frame.String()
+	}
+	call.runtime.console.print(ConsoleError, message)
 	return UndefinedValue()
 }
 
 func builtinConsole_assert(call FunctionCall) Value {
+	if toBoolean(call.Argument(0)) {
+		return UndefinedValue()
+	}
+	message := "Assertion failed"
+	if len(call.ArgumentList) > 1 {
+		message += ": " + formatForConsole(call.ArgumentList[1:])
+	}
+	call.runtime.console.print(ConsoleError, message)
 	return UndefinedValue()
 }
 
-func (runtime *_runtime) newConsole() *_object {
+func builtinConsole_count(call FunctionCall) Value {
+	console := call.runtime.console
+	label := consoleLabel(call)
+	console.count[label] += 1
+	console.print(ConsoleLog, fmt.Sprintf("%s: %d", label, console.count[label]))
+	return UndefinedValue()
+}
+
+func builtinConsole_group(call FunctionCall) Value {
+	console := call.runtime.console
+	if len(call.ArgumentList) > 0 {
+		console.print(ConsoleLog, formatForConsole(call.ArgumentList))
+	}
+	console.group += 1
+	return UndefinedValue()
+}
 
+func builtinConsole_groupEnd(call FunctionCall) Value {
+	console := call.runtime.console
+	if console.group > 0 {
+		console.group -= 1
+	}
+	return UndefinedValue()
+}
+
+func (runtime *_runtime) newConsole() *_object {
+	if runtime.console == nil {
+		runtime.console = newRuntimeConsole(NewConsole(os.Stdout, os.Stderr))
+	}
 	return newConsoleObject(runtime)
 }
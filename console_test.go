@@ -0,0 +1,120 @@
+package otto
+
+import (
+	. "./terst"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type _testConsole struct {
+	output []string
+}
+
+func (self *_testConsole) Print(level ConsoleLevel, message string) {
+	self.output = append(self.output, fmt.Sprintf("%s: %s", level, message))
+}
+
+func (self *_testConsole) flush() string {
+	output := strings.Join(self.output, "\n")
+	self.output = nil
+	return output
+}
+
+func TestConsole(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	console := &_testConsole{}
+	Otto.SetConsole(console)
+
+	Otto.Run(`
+        console.log("Nothing", "happens.", 1);
+        console.debug("abc");
+        console.info("def");
+        console.warn("ghi");
+        console.error("jkl");
+    `)
+	Is(console.flush(), "log: Nothing happens. 1\ndebug: abc\ninfo: def\nwarn: ghi\nerror: jkl")
+
+	Otto.Run(`
+        console.assert(true, "abc");
+        console.assert(1 === 2, "def", 3);
+        console.assert(false);
+    `)
+	Is(console.flush(), "error: Assertion failed: def 3\nerror: Assertion failed")
+
+	Otto.Run(`
+        console.count();
+        console.count("abc");
+        console.count();
+    `)
+	Is(console.flush(), "log: default: 1\nlog: abc: 1\nlog: default: 2")
+
+	Otto.Run(`
+        console.log("abc");
+        console.group("def");
+        console.log("ghi\njkl");
+        console.groupCollapsed();
+        console.warn("mno");
+        console.groupEnd();
+        console.groupEnd();
+        console.groupEnd();
+        console.log("pqr");
+    `)
+	Is(console.flush(), "log: abc\nlog: def\nlog:   ghi\n  jkl\nwarn:     mno\nlog: pqr")
+
+	Otto.Run(`
+        console.dir({ abc: 1, def: "ghi" });
+        console.dir({});
+        console.dir(3.14159);
+    `)
+	Is(console.flush(), "log: { abc: 1, def: 'ghi' }\nlog: {}\nlog: 3.14159")
+
+	Otto.Run(`
+        function abc() {
+            console.trace("def");
+        }
+        abc();
+    `)
+	Is(console.flush(), "error: Trace: def\n    at abc (<anonymous>:3:13)\n    at <anonymous>:5:9")
+
+	Otto.Run(`
+        console.time("abc");
+        console.timeEnd("abc");
+        console.timeEnd("abc");
+    `)
+	output := strings.Split(console.flush(), "\n")
+	Is(len(output), 2)
+	Is(regexp.MustCompile(`^log: abc: \d+\.\d{3}ms$`).MatchString(output[0]), true)
+	Is(output[1], "warn: No such label: abc")
+}
+
+func TestNewConsole(t *testing.T) {
+	Terst(t)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	Otto := New()
+	Otto.SetConsole(NewConsole(stdout, stderr))
+	Otto.Run(`
+        console.log("abc");
+        console.info("def");
+        console.error("ghi");
+        console.warn("jkl");
+    `)
+	Is(stdout.String(), "abc\ndef\n")
+	Is(stderr.String(), "ghi\njkl\n")
+}
+
+func TestSetConsole_nil(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetConsole(nil)
+	_, err := Otto.Run(`console.log("abc")`)
+	Is(err, nil)
+	_, isWriter := Otto.runtime.console.Console.(*_writerConsole)
+	Is(isWriter, true)
+}
@@ -0,0 +1,303 @@
+package otto
+
+import (
+	"bufio"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Coverage records which statements and branches of a script are evaluated (and
+// how many times), for reporting in the LCOV or Go cover format.
+//
+//		coverage := otto.NewCoverage()
+//		Otto.SetCoverage(coverage)
+//		Otto.RunFile("rules.js", source)
+//		...
+//		file, _ := os.Create("rules.lcov")
+//		coverage.WriteLCOV(file)
+//		file.Close()
+//
+// The code of a program (given to Run, RunFile, RunScript, etc.) or a module
+// (loaded with require) is covered, but not the code given to eval (or Function).
+// A file is included in the report once it has been run, and every statement in
+// it (including the statements of functions that were never called) counts toward
+// the total.
+//
+// A branch is each way of an if statement (including an absent else), a conditional
+// (?:), a logical && or || (whether the right side was evaluated), and each case of
+// a switch statement (with an extra branch for no match, if there is no default).
+//
+// The same Coverage can be set on many runtimes (e.g. one for each test), even at
+// the same time, to combine their coverage.
+type Coverage struct {
+	mutex    sync.Mutex
+	fileList []string
+	file     map[string]*_coverageFile
+}
+
+type _coverageFile struct {
+	statement  map[_coverageKey]int64
+	branch     map[_coverageKey][]int64
+	lineLength map[int]int
+}
+
+// _coverageKey is the position of a statement or branch (and the block, for a branch)
+type _coverageKey struct {
+	line   int
+	column int
+	block  int
+}
+
+// _coverageProgram is every statement and branch of a program, as it is converted
+type _coverageProgram struct {
+	statementList []_position
+	branchList    []_coverageBranch
+	blockCount    map[[2]int]int // The number of branches at each line and column
+	lineLength    []int
+}
+
+type _coverageBranch struct {
+	key   _coverageKey
+	count int
+}
+
+func newCoverageProgram(source string) *_coverageProgram {
+	lineLength := []int{}
+	for _, line := range strings.Split(source, "\n") {
+		lineLength = append(lineLength, len(strings.TrimRight(line, "\r")))
+	}
+	return &_coverageProgram{
+		blockCount: map[[2]int]int{},
+		lineLength: lineLength,
+	}
+}
+
+func (self *_coverageProgram) addBranch(position _position, count int) int {
+	block := self.blockCount[[2]int{position.Line, position.Column}]
+	self.blockCount[[2]int{position.Line, position.Column}] = block + 1
+	key := _coverageKey{position.Line, position.Column, block}
+	self.branchList = append(self.branchList, _coverageBranch{key, count})
+	return key.block
+}
+
+// NewCoverage returns an empty Coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{
+		file: map[string]*_coverageFile{},
+	}
+}
+
+// SetCoverage sets the coverage of the runtime (or stops recording coverage, if nil).
+//
+// While there is a coverage, a source without a filename (e.g. given to Run) is
+// given one, anonymous-XXXXXXXX.js (where XXXXXXXX is the CRC-32 of the source),
+// which appears in the report (and in the position of an error), so that the same
+// source is the same file, wherever it is run. Use RunFile (or Compile) to name it.
+func (self Otto) SetCoverage(coverage *Coverage) {
+	self.runtime.coverage = coverage
+}
+
+// anonymousFilename returns the filename of a source without one, for coverage.
+func anonymousFilename(source string) string {
+	return fmt.Sprintf("anonymous-%08x.js", crc32.ChecksumIEEE([]byte(source)))
+}
+
+// register adds the statements and branches of the program (if they are not
+// already there).
+func (self *Coverage) register(filename string, program *_coverageProgram) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	file := self.file[filename]
+	if file == nil {
+		file = &_coverageFile{
+			statement:  map[_coverageKey]int64{},
+			branch:     map[_coverageKey][]int64{},
+			lineLength: map[int]int{},
+		}
+		self.file[filename] = file
+		self.fileList = append(self.fileList, filename)
+		sort.Strings(self.fileList)
+	}
+	for _, position := range program.statementList {
+		key := _coverageKey{position.Line, position.Column, 0}
+		if _, exists := file.statement[key]; !exists {
+			file.statement[key] = 0
+			file.lineLength[position.Line] = program.lineLength[position.Line-1]
+		}
+	}
+	for _, branch := range program.branchList {
+		if _, exists := file.branch[branch.key]; !exists {
+			file.branch[branch.key] = make([]int64, branch.count)
+		}
+	}
+}
+
+// statement counts the evaluation of the statement at the position.
+func (self *Coverage) statement(position _position) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if file := self.file[position.Filename]; file != nil {
+		key := _coverageKey{position.Line, position.Column, 0}
+		if _, exists := file.statement[key]; exists {
+			file.statement[key] += 1
+		}
+	}
+}
+
+// branch counts the taking of the given way (0, 1, ...) of the branch at the position.
+func (self *Coverage) branch(position _position, block int, way int) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if file := self.file[position.Filename]; file != nil {
+		if countList := file.branch[_coverageKey{position.Line, position.Column, block}]; way < len(countList) {
+			countList[way] += 1
+		}
+	}
+}
+
+// Statements returns the number of statements evaluated (at least once), and the
+// total number of statements.
+func (self *Coverage) Statements() (covered, total int) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for _, file := range self.file {
+		for _, count := range file.statement {
+			total += 1
+			if count > 0 {
+				covered += 1
+			}
+		}
+	}
+	return
+}
+
+// Branches returns the number of branches taken (at least once), and the total
+// number of branches.
+func (self *Coverage) Branches() (covered, total int) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for _, file := range self.file {
+		for _, countList := range file.branch {
+			for _, count := range countList {
+				total += 1
+				if count > 0 {
+					covered += 1
+				}
+			}
+		}
+	}
+	return
+}
+
+// sortedCoverageKeys sorts the keys by position.
+func sortedCoverageKeys(keyList []_coverageKey) []_coverageKey {
+	sort.Slice(keyList, func(i, j int) bool {
+		a, b := keyList[i], keyList[j]
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		if a.column != b.column {
+			return a.column < b.column
+		}
+		return a.block < b.block
+	})
+	return keyList
+}
+
+// WriteLCOV writes the coverage in the LCOV (tracefile) format, as read by genhtml,
+// Codecov, etc., with the line (DA) and branch (BRDA) counts of each file.
+func (self *Coverage) WriteLCOV(writer io.Writer) error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	output := bufio.NewWriter(writer)
+	for _, filename := range self.fileList {
+		file := self.file[filename]
+		fmt.Fprintf(output, "TN:\nSF:%s\n", filename)
+
+		branchKeyList := []_coverageKey{}
+		for key := range file.branch {
+			branchKeyList = append(branchKeyList, key)
+		}
+		branchFound, branchHit := 0, 0
+		block, lastLine := 0, 0
+		for _, key := range sortedCoverageKeys(branchKeyList) {
+			if key.line != lastLine {
+				block, lastLine = 0, key.line
+			}
+			countList := file.branch[key]
+			evaluated := false
+			for _, count := range countList {
+				evaluated = evaluated || count > 0
+			}
+			for way, count := range countList {
+				branchFound += 1
+				if !evaluated {
+					fmt.Fprintf(output, "BRDA:%d,%d,%d,-\n", key.line, block, way)
+					continue
+				}
+				if count > 0 {
+					branchHit += 1
+				}
+				fmt.Fprintf(output, "BRDA:%d,%d,%d,%d\n", key.line, block, way, count)
+			}
+			block += 1
+		}
+		fmt.Fprintf(output, "BRF:%d\nBRH:%d\n", branchFound, branchHit)
+
+		// The count of a line is the greatest count of a statement on the line
+		lineCount := map[int]int64{}
+		for key, count := range file.statement {
+			if count >= lineCount[key.line] {
+				lineCount[key.line] = count
+			}
+		}
+		lineList := []int{}
+		for line := range lineCount {
+			lineList = append(lineList, line)
+		}
+		sort.Ints(lineList)
+		lineHit := 0
+		for _, line := range lineList {
+			if lineCount[line] > 0 {
+				lineHit += 1
+			}
+			fmt.Fprintf(output, "DA:%d,%d\n", line, lineCount[line])
+		}
+		fmt.Fprintf(output, "LF:%d\nLH:%d\nend_of_record\n", len(lineList), lineHit)
+	}
+	return output.Flush()
+}
+
+// WriteGoCover writes the (statement) coverage in the format of a Go coverage
+// profile (go test -coverprofile), in count mode, for a tool that reads (or
+// merges) profiles. It cannot be read by go tool cover, which looks for the
+// source of each file in a Go package.
+//
+// Each statement is a block from the statement to the next statement on the same
+// line (or the end of the line).
+func (self *Coverage) WriteGoCover(writer io.Writer) error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	output := bufio.NewWriter(writer)
+	fmt.Fprintln(output, "mode: count")
+	for _, filename := range self.fileList {
+		file := self.file[filename]
+		keyList := []_coverageKey{}
+		for key := range file.statement {
+			keyList = append(keyList, key)
+		}
+		keyList = sortedCoverageKeys(keyList)
+		for index, key := range keyList {
+			end := file.lineLength[key.line] + 1
+			if index+1 < len(keyList) && keyList[index+1].line == key.line {
+				end = keyList[index+1].column
+			}
+			fmt.Fprintf(output, "%s:%d.%d,%d.%d 1 %d\n", filename, key.line, key.column, key.line, end, file.statement[key])
+		}
+	}
+	return output.Flush()
+}
@@ -0,0 +1,117 @@
+package otto
+
+import (
+	. "./terst"
+	"bytes"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	Terst(t)
+
+	source := `
+function abc(def) {
+    if (def > 1) {
+        return "big";
+    }
+    return def === 1 ? "one" : "small";
+}
+function ghi() {
+    return "never";
+}
+abc(2); abc(0);
+var jkl = abc(1) && null || "xyzzy";
+switch (jkl) {
+case "xyzzy":
+    break;
+default:
+    ghi();
+}
+`
+	coverage := NewCoverage()
+	Otto := New()
+	Otto.SetCoverage(coverage)
+	_, err := Otto.RunFile("xyzzy.js", source)
+	Is(err, nil)
+
+	covered, total := coverage.Statements()
+	Is(covered, 8)
+	Is(total, 10)
+	covered, total = coverage.Branches()
+	Is(covered, 7)
+	Is(total, 10)
+
+	buffer := &bytes.Buffer{}
+	Is(coverage.WriteLCOV(buffer), nil)
+	Is(buffer.String(), `TN:
+SF:xyzzy.js
+BRDA:3,0,0,1
+BRDA:3,0,1,2
+BRDA:6,0,0,1
+BRDA:6,0,1,1
+BRDA:12,0,0,1
+BRDA:12,0,1,0
+BRDA:12,1,0,1
+BRDA:12,1,1,0
+BRDA:13,0,0,1
+BRDA:13,0,1,0
+BRF:10
+BRH:7
+DA:3,3
+DA:4,1
+DA:6,2
+DA:9,0
+DA:11,1
+DA:12,1
+DA:13,1
+DA:15,1
+DA:17,0
+LF:9
+LH:7
+end_of_record
+`)
+
+	buffer.Reset()
+	Is(coverage.WriteGoCover(buffer), nil)
+	Is(buffer.String(), `mode: count
+xyzzy.js:3.5,3.19 1 3
+xyzzy.js:4.9,4.22 1 1
+xyzzy.js:6.5,6.40 1 2
+xyzzy.js:9.5,9.20 1 0
+xyzzy.js:11.1,11.9 1 1
+xyzzy.js:11.9,11.16 1 1
+xyzzy.js:12.1,12.37 1 1
+xyzzy.js:13.1,13.15 1 1
+xyzzy.js:15.5,15.11 1 1
+xyzzy.js:17.5,17.11 1 0
+`)
+
+	// Without coverage, nothing is recorded (even with a filename)
+	program, parseErr := parseFile("xyzzy.js", source, false)
+	Is(parseErr, nil)
+	Is(program.coverage == nil, true)
+
+	// Without a filename, the source is named by its checksum
+	coverage = NewCoverage()
+	Otto.SetCoverage(coverage)
+	anonymous := "var mno = 1;\nif (mno > 1) {\n    mno = 2;\n}"
+	_, err = Otto.Run(anonymous)
+	Is(err, nil)
+	script, err := Compile("", anonymous)
+	Is(err, nil)
+	_, err = New().RunScript(script) // Not covered
+	Is(err, nil)
+	_, err = Otto.RunScript(script)
+	Is(err, nil)
+
+	buffer.Reset()
+	Is(coverage.WriteGoCover(buffer), nil)
+	Is(buffer.String(), `mode: count
+anonymous-a1844d62.js:1.1,1.13 1 2
+anonymous-a1844d62.js:2.1,2.15 1 2
+anonymous-a1844d62.js:3.5,3.13 1 0
+`)
+
+	_, err = Otto.Run("throw new Error('xyzzy')")
+	Is(err.(*Error).Filename, "anonymous-f05b0345.js")
+}
@@ -0,0 +1,274 @@
+package otto
+
+import (
+	"sort"
+)
+
+// DebugReason is why a Debugger paused.
+type DebugReason int
+
+const (
+	DebugBreakpoint DebugReason = iota // A breakpoint (see Debugger.SetBreakpoint)
+	DebugStatement                     // A debugger statement
+	DebugException                     // An exception (see Debugger.PauseOnException)
+	DebugStep                          // The end of a step (see DebugAction)
+)
+
+func (self DebugReason) String() string {
+	switch self {
+	case DebugBreakpoint:
+		return "breakpoint"
+	case DebugStatement:
+		return "debugger"
+	case DebugException:
+		return "exception"
+	case DebugStep:
+		return "step"
+	}
+	return "DebugReason(?)"
+}
+
+// DebugAction is what to do after a pause, as returned by the handler of a Debugger.
+type DebugAction int
+
+const (
+	DebugContinue DebugAction = iota // Run until the next breakpoint (or debugger statement, etc.)
+	DebugStepIn                      // Pause at the next statement, even within a function call
+	DebugStepOver                    // Pause at the next statement, but not within a function call
+	DebugStepOut                     // Pause at the next statement after returning from the current function
+)
+
+// Debugger pauses a runtime at breakpoints, debugger statements, and (optionally)
+// exceptions, calling its handler with the state of the runtime at that point.
+//
+//		debugger := otto.NewDebugger(func(event *otto.DebugEvent) otto.DebugAction {
+//			fmt.Printf("Paused (%s) at %s:%d\n", event.Reason, event.Filename, event.Line)
+//			value, _ := event.Eval("abc")
+//			fmt.Println("abc =", value)
+//			return otto.DebugStepOver
+//		})
+//		debugger.SetBreakpoint("example.js", 3)
+//		Otto.SetDebugger(debugger)
+//		Otto.RunFile("example.js", source)
+//
+// The handler is called on the goroutine running the script, which waits for it
+// to return. A debugger should only be attached to one runtime at a time.
+type Debugger struct {
+	handler          func(*DebugEvent) DebugAction
+	breakpoint       map[string]map[int]bool // Filename => line => true
+	pauseOnException bool
+
+	paused    bool        // Whether the handler is running (so there is no pausing within the handler)
+	step      DebugAction // The action of the last pause
+	stepDepth int         // The depth of the stack at the last pause
+	last      _position   // The position of the last statement
+	lastDepth int
+	lastLine  map[_node]bool // The statements evaluated on the line of the last statement (since entering it)
+}
+
+// NewDebugger returns a Debugger with the given handler, which is called each
+// time the runtime pauses.
+func NewDebugger(handler func(*DebugEvent) DebugAction) *Debugger {
+	return &Debugger{
+		handler:    handler,
+		breakpoint: map[string]map[int]bool{},
+	}
+}
+
+// SetBreakpoint sets a breakpoint at the (first) statement on the given line
+// (1-based) of the given file. The filename is as given to RunFile, Compile, etc.
+func (self *Debugger) SetBreakpoint(filename string, line int) {
+	if self.breakpoint[filename] == nil {
+		self.breakpoint[filename] = map[int]bool{}
+	}
+	self.breakpoint[filename][line] = true
+}
+
+// ClearBreakpoint clears the breakpoint (if any) at the given line of the given file.
+func (self *Debugger) ClearBreakpoint(filename string, line int) {
+	delete(self.breakpoint[filename], line)
+}
+
+// Breakpoints returns the lines of every breakpoint, by filename.
+func (self *Debugger) Breakpoints() map[string][]int {
+	result := map[string][]int{}
+	for filename, lineSet := range self.breakpoint {
+		for line := range lineSet {
+			result[filename] = append(result[filename], line)
+		}
+		sort.Ints(result[filename])
+	}
+	return result
+}
+
+// PauseOnException sets whether to pause when an exception is thrown (caught
+// or not).
+func (self *Debugger) PauseOnException(pause bool) {
+	self.pauseOnException = pause
+}
+
+// Step sets the action for the next statement, as if it were returned by the
+// handler. For example, DebugStepIn will pause at the first statement of the
+// script.
+func (self *Debugger) Step(action DebugAction) {
+	self.step = action
+	self.stepDepth = 0
+}
+
+// SetDebugger attaches the debugger to the runtime (or detaches any debugger,
+// if nil).
+func (self Otto) SetDebugger(debugger *Debugger) {
+	self.runtime.debugger = debugger
+}
+
+// DebugEvent is the state of a paused runtime, as given to the handler of a Debugger.
+// It is only valid until the handler returns.
+type DebugEvent struct {
+	Reason   DebugReason
+	Filename string
+	Line     int
+	Column   int
+
+	// Exception is the value thrown, if the Reason is DebugException (and undefined
+	// otherwise).
+	Exception Value
+
+	// Stack is the stack trace, beginning with the innermost frame.
+	Stack []Frame
+
+	runtime *_runtime
+}
+
+// DebugScope is a part of the scope chain of a paused runtime.
+type DebugScope struct {
+	// Kind is "local" (a function), "catch", "with", or "global".
+	Kind string
+
+	// Bindings are the variables of the scope. For the global scope, this only
+	// includes enumerable properties of the global object (so, not the builtins).
+	Bindings map[string]Value
+}
+
+// Scope returns the scope chain at the pause, beginning with the innermost scope.
+func (self *DebugEvent) Scope() []DebugScope {
+	runtime := self.runtime
+	scopeList := []DebugScope{}
+	for environment := runtime._executionContext(0).LexicalEnvironment; environment != nil; environment = environment.Outer() {
+		scope := DebugScope{
+			Bindings: map[string]Value{},
+		}
+		switch environment := environment.(type) {
+		case *_functionEnvironment:
+			scope.Kind = "local"
+			for name, property := range environment.property {
+				scope.Bindings[name] = property.value
+			}
+		case *_declarativeEnvironment:
+			scope.Kind = "catch"
+			for name, property := range environment.property {
+				scope.Bindings[name] = property.value
+			}
+		case *_objectEnvironment:
+			scope.Kind = "with"
+			if environment == runtime.GlobalEnvironment {
+				scope.Kind = "global"
+			}
+			object := environment.Object
+			object.enumerate(false, func(name string) bool {
+				scope.Bindings[name] = object.get(name)
+				return true
+			})
+		}
+		scopeList = append(scopeList, scope)
+	}
+	return scopeList
+}
+
+// Eval evaluates the source in the scope of the paused frame (as a direct call
+// to eval would), returning the resulting value and error (if any).
+//
+// The debugger does not pause during Eval.
+func (self *DebugEvent) Eval(source string) (Value, error) {
+	runtime := self.runtime
+	current := runtime._executionContext(0)
+	result := UndefinedValue()
+	err := runtime.catchPanic(func() {
+		program, err := parse(source)
+		if err != nil {
+			panic(err)
+		}
+		executionContext := newExecutionContext(current.LexicalEnvironment, current.VariableEnvironment, current.this)
+		executionContext.eval = true
+		executionContext.strict = current.strict
+		runtime.EnterExecutionContext(executionContext)
+		defer runtime.LeaveExecutionContext()
+		result = runtime.GetValue(runtime.evaluate(program))
+		if result.isEmpty() {
+			result = UndefinedValue()
+		}
+	})
+	return result, err
+}
+
+// statement is called before the evaluation of each statement.
+func (self *Debugger) statement(runtime *_runtime, node _node) {
+	if self.paused {
+		return
+	}
+
+	position := node.position()
+	depth := len(runtime.Stack)
+	pause, reason := false, DebugStep
+	switch self.step {
+	case DebugStepIn:
+		pause = true
+	case DebugStepOver:
+		pause = depth <= self.stepDepth
+	case DebugStepOut:
+		pause = depth < self.stepDepth
+	}
+	if _, yes := node.(*_debuggerNode); yes {
+		pause, reason = true, DebugStatement
+	}
+	// A new evaluation of the line: entering it (from another line, or another
+	// call), or a statement on it again (e.g. the next iteration of a loop)
+	if position.Filename != self.last.Filename || position.Line != self.last.Line || depth != self.lastDepth || self.lastLine[node] {
+		self.last, self.lastDepth = position, depth
+		self.lastLine = map[_node]bool{}
+		// Only the first statement of the line (not every statement on it)
+		if !pause && self.breakpoint[position.Filename][position.Line] {
+			pause, reason = true, DebugBreakpoint
+		}
+	}
+	self.lastLine[node] = true
+
+	if pause {
+		self.pause(runtime, reason, position, UndefinedValue())
+	}
+}
+
+// exception is called when an exception is thrown.
+func (self *Debugger) exception(runtime *_runtime, value Value) {
+	if self.paused || !self.pauseOnException {
+		return
+	}
+	self.pause(runtime, DebugException, runtime._executionContext(0).node.position(), value)
+}
+
+func (self *Debugger) pause(runtime *_runtime, reason DebugReason, position _position, exception Value) {
+	event := &DebugEvent{
+		Reason:    reason,
+		Filename:  position.Filename,
+		Line:      position.Line,
+		Column:    position.Column,
+		Exception: exception,
+		Stack:     runtime.stackTrace(),
+		runtime:   runtime,
+	}
+	self.paused = true
+	defer func() {
+		self.paused = false
+	}()
+	self.step = self.handler(event)
+	self.stepDepth = len(runtime.Stack)
+}
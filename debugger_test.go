@@ -0,0 +1,175 @@
+package otto
+
+import (
+	. "./terst"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDebugger(t *testing.T) {
+	Terst(t)
+
+	source := `
+        var abc = 1;
+        function def(ghi) {
+            var jkl = ghi + 1;
+            debugger;
+            return jkl;
+        }
+        abc = def(abc);
+        abc += 1;
+    `
+
+	pauseList := []string{}
+	evalList := []string{}
+	debugger := NewDebugger(func(event *DebugEvent) DebugAction {
+		pauseList = append(pauseList, fmt.Sprintf("%s %s:%d:%d", event.Reason, event.Filename, event.Line, event.Column))
+		if event.Reason == DebugStatement {
+			value, err := event.Eval("ghi + jkl")
+			evalList = append(evalList, fmt.Sprintf("%v %v", value, err))
+			_, err = event.Eval("jkl = 10") // Change the variable
+			evalList = append(evalList, fmt.Sprintf("%v", err))
+			_, err = event.Eval("xyzzy")
+			evalList = append(evalList, fmt.Sprintf("%v", err))
+			scope := event.Scope()
+			evalList = append(evalList, fmt.Sprintf("%s %v %s %v", scope[0].Kind, len(scope[0].Bindings), scope[1].Kind, scope[1].Bindings["abc"]))
+			evalList = append(evalList, event.Stack[0].String()+", "+event.Stack[1].String())
+		}
+		return DebugContinue
+	})
+	debugger.SetBreakpoint("xyzzy.js", 8)
+
+	Otto := New()
+	Otto.SetDebugger(debugger)
+	value, err := Otto.RunFile("xyzzy.js", source)
+	Is(err, nil)
+	Is(value, "11") // jkl was changed to 10
+	Is(strings.Join(pauseList, ", "), "breakpoint xyzzy.js:8:9, debugger xyzzy.js:5:13")
+	Is(strings.Join(evalList, "; "), "3 <nil>; <nil>; ReferenceError: xyzzy is not defined (line 1); local 3 global 1; def (xyzzy.js:5:13), xyzzy.js:8:15")
+
+	// Without a debugger, the statement does nothing
+	Otto.SetDebugger(nil)
+	value, err = Otto.Run(`debugger; 1 + 1`)
+	Is(err, nil)
+	Is(value, "2")
+}
+
+func TestDebugger_step(t *testing.T) {
+	Terst(t)
+
+	source := `
+        function abc() {
+            var def = 1;
+            return def;
+        }
+        abc();
+        abc();
+        var ghi = 3;
+    `
+
+	test := func(actionList ...DebugAction) string {
+		lineList := []string{}
+		debugger := NewDebugger(func(event *DebugEvent) DebugAction {
+			lineList = append(lineList, fmt.Sprintf("%d", event.Line))
+			if len(actionList) == 0 {
+				return DebugContinue
+			}
+			action := actionList[0]
+			actionList = actionList[1:]
+			return action
+		})
+		debugger.Step(DebugStepIn) // Pause at the beginning
+		Otto := New()
+		Otto.SetDebugger(debugger)
+		Otto.Run(source)
+		return strings.Join(lineList, " ")
+	}
+
+	Is(test(DebugStepIn, DebugStepIn, DebugStepIn, DebugStepIn, DebugStepIn), "6 3 4 7 3 4")
+	Is(test(DebugStepOver, DebugStepOver, DebugStepOver), "6 7 8")
+	Is(test(DebugStepIn, DebugStepOut, DebugStepOver), "6 3 7 8")
+	Is(test(DebugContinue), "6")
+}
+
+func TestDebugger_loop(t *testing.T) {
+	Terst(t)
+
+	lineList := []string{}
+	debugger := NewDebugger(func(event *DebugEvent) DebugAction {
+		lineList = append(lineList, fmt.Sprintf("%d", event.Line))
+		return DebugContinue
+	})
+	debugger.SetBreakpoint("", 4)
+	debugger.SetBreakpoint("", 6)
+
+	Otto := New()
+	Otto.SetDebugger(debugger)
+	_, err := Otto.Run(`
+        var abc = 0;
+        for (var def = 0; def < 3; def++) {
+            abc += def;
+        }
+        while (abc < 6) { abc++; abc++; }
+    `)
+	Is(err, nil)
+	// Once for each iteration (and only once for each line of the body)
+	Is(strings.Join(lineList, " "), "4 4 4 6 6")
+}
+
+func TestDebugger_exception(t *testing.T) {
+	Terst(t)
+
+	pauseList := []string{}
+	debugger := NewDebugger(func(event *DebugEvent) DebugAction {
+		pauseList = append(pauseList, fmt.Sprintf("%s %d %v", event.Reason, event.Line, event.Exception))
+		return DebugContinue
+	})
+	debugger.SetBreakpoint("", 6)
+	debugger.SetBreakpoint("", 7)
+	debugger.ClearBreakpoint("", 7)
+	Is(debugger.Breakpoints(), map[string][]int{"": []int{6}})
+
+	Otto := New()
+	Otto.SetDebugger(debugger)
+	source := `
+        try {
+            throw new Error("Nothing happens.");
+        } catch (err) {
+        }
+        var abc = 1; var def = 2;
+        abc.def.ghi;
+    `
+	_, err := Otto.Run(source)
+	Is(err, "TypeError (line 7)")
+	Is(strings.Join(pauseList, ", "), "breakpoint 6 undefined")
+
+	pauseList = pauseList[:0]
+	debugger.PauseOnException(true)
+	_, err = Otto.Run(source)
+	Is(strings.Join(pauseList, ", "), "exception 3 Error: Nothing happens., breakpoint 6 undefined, exception 7 TypeError")
+
+	// The exception is the value that is caught (or returned by Run)
+	exceptionList := []Value{}
+	debugger = NewDebugger(func(event *DebugEvent) DebugAction {
+		exceptionList = append(exceptionList, event.Exception)
+		return DebugContinue
+	})
+	debugger.PauseOnException(true)
+	Otto.SetDebugger(debugger)
+	Otto.Set("same", func(call FunctionCall) Value {
+		return toValue_bool(call.Argument(0)._object() == exceptionList[len(exceptionList)-1]._object())
+	})
+	value, err := Otto.Run(`
+        try {
+            null.abc;
+        } catch (err) {
+            same(err);
+        }
+    `)
+	Is(err, nil)
+	Is(value, "true")
+	_, err = Otto.Run(`undefined.abc`)
+	Is(err.(*Error).Value._object() == exceptionList[len(exceptionList)-1]._object(), true)
+	Is(len(exceptionList), 2)
+}
@@ -1,7 +1,6 @@
 package otto
 
 import (
-	"errors"
 	"fmt"
 )
 
@@ -25,7 +24,13 @@ type _error struct {
 	Name    string
 	Message string
 
-	Line int // Hackish -- line where the error/exception occurred
+	_position // Where the error/exception occurred (if known)
+
+	trace []Frame // The stack at the point where the error occurred
+
+	err error // The Go error, if the error was returned by a Go function
+
+	object *_object // The Error object of the error, once it is made (see _runtime.errorObject)
 }
 
 var messageDetail map[string]string = map[string]string{
@@ -74,14 +79,22 @@ func newError(name string, argumentList ...interface{}) _error {
 	error := _error{
 		Name:    name,
 		Message: messageFromDescription(description, argumentList...),
-		Line:    -1,
 	}
 	if node != nil {
-		error.Line = node.position()
+		error._position = node.position()
 	}
 	return error
 }
 
+// newGoError is an Error for the error returned by a Go function.
+func newGoError(err error) _error {
+	return _error{
+		Name:    "Error",
+		Message: err.Error(),
+		err:     err,
+	}
+}
+
 func newReferenceError(argumentList ...interface{}) _error {
 	return newError("ReferenceError", argumentList...)
 }
@@ -109,6 +122,137 @@ func typeErrorResult(throw bool) bool {
 	return false
 }
 
+// An Error is returned (as an error) when JavaScript throws an exception that is
+// not caught, or when the source fails to parse.
+//
+// Name and Message are taken from the error object, e.g. "TypeError" and "Nothing happens.".
+// If the thrown value is not an Error object, then Name is empty and Message is the
+// string value of what was thrown.
+//
+//		_, err := Otto.Run(`abcdef.length`)
+//		var ottoErr *otto.Error
+//		if errors.As(err, &ottoErr) {
+//			// ottoErr.Name == "ReferenceError"
+//			// ottoErr.Message == "abcdef is not defined"
+//			// ottoErr.Line == 1
+//		}
+type Error struct {
+	Name    string
+	Message string
+
+	// Value is the value that was thrown. For an error raised by the runtime itself
+	// (e.g. a ReferenceError), this is the corresponding Error object.
+	Value Value
+
+	// Filename, Line, and Column are where the error occurred, or, if an Error
+	// object was thrown, where the Error object was created (the first frame of
+	// Stack), which (as before) is not part of the description given by Error.
+	Filename string
+	Line     int // 1-based, or 0 if unknown
+	Column   int // 1-based, or 0 if unknown
+
+	// Stack is the JavaScript call stack at the point where the error was
+	// created, beginning with the innermost frame. It is nil for a syntax error,
+	// or if the thrown value is not an Error object.
+	Stack []Frame
+
+	// Err is the Go error, if the Error object was thrown for the error returned
+	// by a Go function, and is returned by Unwrap (for errors.Is and errors.As).
+	Err error
+
+	thrown bool // Whether the Value was thrown (by the script, or a native function)
+}
+
+// A Frame is a single entry of a JavaScript call stack.
+type Frame struct {
+	Function string // The name of the function, or "" for anonymous and global code
+	Filename string
+	Line     int // 1-based, or 0 if unknown
+	Column   int // 1-based, or 0 if unknown
+}
+
+// String returns the frame in the style of Error.prototype.stack, e.g.
+//
+//		abc (example.js:3:5)
+//		<anonymous>:7
+func (self Frame) String() string {
+	location := self.Filename
+	if location == "" {
+		location = "<anonymous>"
+	}
+	if self.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, self.Line)
+		if self.Column > 0 {
+			location = fmt.Sprintf("%s:%d", location, self.Column)
+		}
+	}
+	if self.Function == "" {
+		return location
+	}
+	return fmt.Sprintf("%s (%s)", self.Function, location)
+}
+
+// Unwrap returns Err.
+func (self *Error) Unwrap() error {
+	return self.Err
+}
+
+// String returns a description of the error without any position information, e.g.
+//
+//		TypeError: Nothing happens.
+func (self *Error) String() string {
+	if len(self.Name) == 0 {
+		return self.Message
+	}
+	if len(self.Message) == 0 {
+		return self.Name
+	}
+	return fmt.Sprintf("%s: %s", self.Name, self.Message)
+}
+
+// Error returns a description of the error with the position, if known, e.g.
+//
+//		ReferenceError: abcdef is not defined (line 3)
+//		SyntaxError: Unexpected token } (example.js: line 2)
+//
+// The description of a value thrown by the script (e.g. throw new TypeError())
+// is the same as String, without the position (see Line, etc.).
+func (self *Error) Error() string {
+	if self.Line == 0 || self.thrown {
+		return self.String()
+	}
+	if self.Filename == "" {
+		return fmt.Sprintf("%s (line %d)", self.String(), self.Line)
+	}
+	return fmt.Sprintf("%s (%s: line %d)", self.String(), self.Filename, self.Line)
+}
+
+func newErrorFromValue(value Value) *Error {
+	err := &Error{
+		Value:  value,
+		thrown: true,
+	}
+	if object := value._object(); object != nil && object.class == "Error" {
+		err.Stack = object.errorValue().stack
+		err.Err = object.errorValue().err
+		if len(err.Stack) > 0 {
+			// Where the Error object was made (e.g. thrown)
+			err.Filename = err.Stack[0].Filename
+			err.Line = err.Stack[0].Line
+			err.Column = err.Stack[0].Column
+		}
+		if name := object.get("name"); name.IsDefined() {
+			err.Name = toString(name)
+		}
+		if message := object.get("message"); message.IsDefined() {
+			err.Message = toString(message)
+		}
+		return err
+	}
+	err.Message = toString(value)
+	return err
+}
+
 func catchPanic(function func()) (err error) {
 	defer func() {
 		if caught := recover(); caught != nil {
@@ -117,19 +261,39 @@ func catchPanic(function func()) (err error) {
 			}
 			switch caught := caught.(type) {
 			case *_syntaxError:
-				err = errors.New(fmt.Sprintf("%s (line %d)", caught.String(), caught.Line+0))
+				err = &Error{
+					Name:     "SyntaxError",
+					Message:  caught.Message,
+					Filename: caught.Filename,
+					Line:     caught.Line,
+					Column:   caught.Column,
+				}
 				return
 			case _error:
-				if caught.Line == -1 {
-					err = errors.New(caught.String())
-				} else {
-					// We're 0-based (for now), hence the + 1
-					err = errors.New(fmt.Sprintf("%s (line %d)", caught.String(), caught.Line+1))
+				error := &Error{
+					Name:     caught.Name,
+					Message:  caught.Message,
+					Filename: caught.Filename,
+					Line:     caught.Line,
+					Column:   caught.Column,
+					Stack:    caught.trace,
+					Err:      caught.err,
 				}
+				if caught.object != nil {
+					error.Value = toValue_object(caught.object) // As seen by the debugger
+				}
+				err = error
 				return
 			case Value:
-				err = errors.New(toString(caught))
+				err = newErrorFromValue(caught)
+				return
+			case *_interrupt:
+				err = caught.err
 				return
+			case *_interruptPanic:
+				if caught.runtime.native == 0 {
+					panic(caught.value)
+				}
 			}
 			panic(caught)
 		}
@@ -138,10 +302,53 @@ func catchPanic(function func()) (err error) {
 	return nil
 }
 
+// catchPanic is like the package-level catchPanic, but will also create an Error
+// object (as the Value) for an error raised by the runtime itself.
+func (self *_runtime) catchPanic(function func()) error {
+	err := catchPanic(function)
+	if err, ok := err.(*Error); ok && err.Value.isEmpty() {
+		message := UndefinedValue()
+		if err.Message != "" {
+			message = toValue_string(err.Message)
+		}
+		error := self.newError(err.Name, message)
+		if err.Stack != nil {
+			self.setErrorStack(error, err.Stack)
+		}
+		if err.Err != nil {
+			self.setGoError(error, err.Err)
+		}
+		err.Value = toValue_object(error)
+		return err
+	}
+	return err
+}
+
+// callNative calls a native function (of the embedder), converting any panic that
+// is not a JavaScript exception (e.g. a nil pointer dereference) into an Error.
+func (self *_runtime) callNative(function func() Value) Value {
+	self.native += 1
+	defer func() {
+		self.native -= 1
+		if caught := recover(); caught != nil {
+			switch caught := caught.(type) {
+			case *_exception, _error, *_syntaxError, Value, *_interrupt, *_interruptPanic:
+				panic(caught)
+			case error:
+				panic(newGoError(caught))
+			default:
+				panic(newError("Error", "%v", caught))
+			}
+		}
+	}()
+	return function()
+}
+
 // SyntaxError
 
 type _syntaxError struct {
 	Message   string
+	Filename  string
 	Line      int
 	Column    int
 	Character int
@@ -154,12 +361,3 @@ func (self _syntaxError) String() string {
 	}
 	return fmt.Sprintf("%s: %s", name, self.Message)
 }
-
-func (self _token) newSyntaxError(description string, argumentList ...interface{}) *_syntaxError {
-	return &_syntaxError{
-		Message:   messageFromDescription(description, argumentList...),
-		Line:      self.Line,
-		Column:    self.Column,
-		Character: self.Character,
-	}
-}
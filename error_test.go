@@ -62,3 +62,35 @@ func Test_catchPanic(t *testing.T) {
 	_, err = otto.Call(`abc.def`, nil)
 	IsNot(err, nil)
 }
+
+func TestError_stack(t *testing.T) {
+	Terst(t)
+
+	test := runTest()
+	test(`
+        function abc() {
+            return new TypeError("Nothing happens.");
+        }
+        function def() {
+            return abc();
+        }
+        def().stack;
+    `, "TypeError: Nothing happens.\n    at abc (<anonymous>:3:20)\n    at def (<anonymous>:6:20)\n    at <anonymous>:8:9")
+
+	test(`
+        var xyzzy = function() {
+            null.abc;
+        };
+        try {
+            xyzzy();
+        }
+        catch (err) {
+            error = err;
+        }
+        error.stack;
+    `, "TypeError\n    at <anonymous>:3:13\n    at <anonymous>:6:13")
+
+	test(`
+        [ Error("Nothing happens.").stack, Object.keys(new Error()).length ];
+    `, "Error: Nothing happens.\n    at <anonymous>:2:11,0")
+}
@@ -23,6 +23,54 @@ func (self *_runtime) evaluateBody(body []_node) Value {
 	return bodyValue
 }
 
+func (self *_runtime) evaluateProgram(node *_programNode) Value {
+	if self.tracer != nil {
+		frame := self.traceProgram(node)
+		self.tracer.OnEnterFunction(frame)
+		defer self.tracer.OnExitFunction(frame)
+	}
+	if self.coverage != nil && node.coverage != nil {
+		self.coverage.register(node.position().Filename, node.coverage)
+	}
+	if node.strict {
+		executionContext := self._executionContext(0)
+		if !executionContext.strict {
+			executionContext.strict = true
+			defer func() {
+				executionContext.strict = false
+			}()
+		}
+	}
+	self.declare("function", node.FunctionList)
+	self.declare("variable", node.VariableList)
+	return self.evaluateBody(node.Body)
+}
+
+// interrupt checks whether evaluation should stop (or be interrupted)
+func (self *_runtime) interrupt() {
+	// Stop if the context (of RunContext, etc.) is done
+	if len(self.done) > 0 {
+		self.checkContext()
+	}
+
+	// Allow interpreter interruption
+	// If the Interrupt channel is nil, then
+	// we avoid runtime.Gosched() overhead (if any)
+	if self.Otto.Interrupt != nil {
+		runtime.Gosched()
+		select {
+		case value := <-self.Otto.Interrupt:
+			defer func() {
+				if caught := recover(); caught != nil {
+					panic(&_interruptPanic{self, caught})
+				}
+			}()
+			value()
+		default:
+		}
+	}
+}
+
 func (self *_runtime) evaluate(node _node) Value {
 	defer func() {
 		// This defer is lame (unecessary overhead)
@@ -30,8 +78,18 @@ func (self *_runtime) evaluate(node _node) Value {
 		if caught := recover(); caught != nil {
 			switch caught := caught.(type) {
 			case _error:
-				if caught.Line == -1 {
-					caught.Line = node.position()
+				if caught.Line == 0 {
+					caught._position = node.position()
+				}
+				if caught.trace == nil {
+					// The innermost node is where the error happened
+					self._executionContext(0).node = node
+					caught.trace = self.stackTrace()
+					if self.debugger != nil {
+						// The same object is caught (or returned by Run, etc.)
+						caught.object = self.errorObject(caught)
+						self.debugger.exception(self, toValue_object(caught.object))
+					}
 				}
 				panic(caught) // Panic the modified _error
 			}
@@ -39,15 +97,23 @@ func (self *_runtime) evaluate(node _node) Value {
 		}
 	}()
 
-	// Allow interpreter interruption
-	// If the Interrupt channel is nil, then
-	// we avoid runtime.Gosched() overhead (if any)
-	if self.Otto.Interrupt != nil {
-		runtime.Gosched()
-		select {
-		case value := <-self.Otto.Interrupt:
-			value()
-		default:
+	self.interrupt()
+	if self.limits.Nodes > 0 {
+		self.countNode()
+	}
+
+	self._executionContext(0).node = node
+
+	if node.isStatement() {
+		if self.tracer != nil {
+			frame, _ := self._executionContext(0).frame()
+			self.tracer.OnStatement(frame)
+		}
+		if self.coverage != nil {
+			self.coverage.statement(node.position())
+		}
+		if self.debugger != nil {
+			self.debugger.statement(self, node)
 		}
 	}
 
@@ -60,9 +126,7 @@ func (self *_runtime) evaluate(node _node) Value {
 		return self.evaluateVariableDeclaration(node)
 
 	case *_programNode:
-		self.declare("function", node.FunctionList)
-		self.declare("variable", node.VariableList)
-		return self.evaluateBody(node.Body)
+		return self.evaluateProgram(node)
 
 	case *_blockNode:
 		return self.evaluateBlock(node)
@@ -126,6 +190,10 @@ func (self *_runtime) evaluate(node _node) Value {
 	case *_emptyNode:
 		return emptyValue()
 
+	case *_debuggerNode:
+		// The debugger (if any) has already paused (see above)
+		return emptyValue()
+
 	case *_tryCatchNode:
 		return self.evaluateTryCatch(node)
 
@@ -151,7 +219,7 @@ func (self *_runtime) evaluate(node _node) Value {
 		return self.evaluateConditional(node)
 
 	case *_thisNode:
-		return toValue_object(self._executionContext(0).this)
+		return self._executionContext(0).this
 
 	case *_commaNode:
 		return self.evaluateComma(node)
@@ -9,8 +9,14 @@ func (self *_runtime) evaluateConditional(node *_conditionalNode) Value {
 	test := self.evaluate(node.Test)
 	testValue := self.GetValue(test)
 	if toBoolean(testValue) {
+		if self.coverage != nil {
+			self.coverage.branch(node.position(), node.block, 0)
+		}
 		return self.evaluate(node.Consequent)
 	}
+	if self.coverage != nil {
+		self.coverage.branch(node.position(), node.block, 1)
+	}
 	return self.evaluate(node.Alternate)
 }
 
@@ -25,6 +31,7 @@ func (self *_runtime) evaluateNew(node *_newNode) Value {
 	if !calleeValue.IsFunction() {
 		panic(newTypeError("%v is not a function", calleeValue))
 	}
+	self._executionContext(0).node = node
 	return calleeValue._object().Construct(this, argumentList)
 }
 
@@ -46,7 +53,16 @@ func (self *_runtime) evaluateObject(node *_objectNode) Value {
 	result := self.newObject()
 
 	for _, property := range node.propertyList {
-		result.defineProperty(property.Key, self.GetValue(self.evaluate(property.Value)), 0111, false)
+		value := self.GetValue(self.evaluate(property.Value))
+		switch property.Kind {
+		case "get":
+			// The setter (if any) is left as is, since it is missing (nil) here
+			result.defineOwnProperty(property.Key, _property{_propertyGetSet{value._object(), nil}, 0211}, false)
+		case "set":
+			result.defineOwnProperty(property.Key, _property{_propertyGetSet{nil, value._object()}, 0211}, false)
+		default:
+			result.defineProperty(property.Key, value, 0111, false)
+		}
 	}
 
 	return toValue_object(result)
@@ -198,7 +214,9 @@ func (self *_runtime) calculateBinaryOperation(operator string, left Value, righ
 		rightValue = toPrimitive(rightValue)
 
 		if leftValue.IsString() || rightValue.IsString() {
-			return toValue_string(strings.Join([]string{leftValue.toString(), rightValue.toString()}, ""))
+			left, right := leftValue.toString(), rightValue.toString()
+			self.allocate(len(left) + len(right))
+			return toValue_string(strings.Join([]string{left, right}, ""))
 		} else {
 			return toValue_float64(leftValue.toFloat() + rightValue.toFloat())
 		}
@@ -480,14 +498,26 @@ func (self *_runtime) evaluateBinaryOperation(node *_binaryOperationNode) Value
 	// Logical
 	case "&&":
 		if !toBoolean(leftValue) {
+			if self.coverage != nil {
+				self.coverage.branch(node.position(), node.block, 1)
+			}
 			return leftValue
 		}
+		if self.coverage != nil {
+			self.coverage.branch(node.position(), node.block, 0)
+		}
 		right := self.evaluate(node.Right)
 		return self.GetValue(right)
 	case "||":
 		if toBoolean(leftValue) {
+			if self.coverage != nil {
+				self.coverage.branch(node.position(), node.block, 1)
+			}
 			return leftValue
 		}
+		if self.coverage != nil {
+			self.coverage.branch(node.position(), node.block, 0)
+		}
 		right := self.evaluate(node.Right)
 		return self.GetValue(right)
 	}
@@ -513,6 +543,15 @@ func (self *_runtime) evaluateCall(node *_callNode, withArgumentList []interface
 		if calleeReference.IsPropertyReference() {
 			calleeObject := calleeReference.GetBase().(*_object)
 			this = toValue_object(calleeObject)
+			if _, identifier := node.Callee.(*_identifierNode); identifier && calleeObject == self.GlobalObject {
+				// A global function is called with an undefined this, which only a strict
+				// function sees (a native function gets the global object, as before)
+				if calleeValue.IsFunction() {
+					if function := functionNode(calleeValue._object()); function != nil && function.strict {
+						this = UndefinedValue()
+					}
+				}
+			}
 		} else {
 			// TODO ImplictThisValue
 		}
@@ -523,6 +562,7 @@ func (self *_runtime) evaluateCall(node *_callNode, withArgumentList []interface
 	if !calleeValue.IsFunction() {
 		panic(newTypeError("%v is not a function", calleeValue))
 	}
+	self._executionContext(0).node = node
 	return self.Call(calleeValue._object(), this, argumentList, evalHint)
 }
 
@@ -533,8 +573,7 @@ func (self *_runtime) evaluateFunction(node *_functionNode) Value {
 func (self *_runtime) evaluateDotMember(node *_dotMemberNode) Value {
 	target := self.evaluate(node.Target)
 	targetValue := self.GetValue(target)
-	// TODO Pass in base value as-is, and defer toObject till later?
-	return toValue(newPropertyReference(self.toObject(targetValue), node.Member, false, node))
+	return toValue(newPrimitivePropertyReference(self, targetValue, node.Member, self.strict(), node))
 }
 
 func (self *_runtime) evaluateBracketMember(node *_bracketMemberNode) Value {
@@ -543,8 +582,7 @@ func (self *_runtime) evaluateBracketMember(node *_bracketMemberNode) Value {
 	member := self.evaluate(node.Member)
 	memberValue := self.GetValue(member)
 
-	// TODO Pass in base value as-is, and defer toObject till later?
-	return toValue(newPropertyReference(self.toObject(targetValue), toString(memberValue), false, node))
+	return toValue(newPrimitivePropertyReference(self, targetValue, toString(memberValue), self.strict(), node))
 }
 
 func (self *_runtime) evaluateIdentifier(node *_identifierNode) Value {
@@ -552,7 +590,7 @@ func (self *_runtime) evaluateIdentifier(node *_identifierNode) Value {
 	// TODO Should be true or false (strictness) depending on context
 	// getIdentifierReference should not return nil, but we check anyway and panic
 	// so as not to propagate the nil into something else
-	reference := getIdentifierReference(self.LexicalEnvironment(), name, false, node)
+	reference := getIdentifierReference(self.LexicalEnvironment(), name, self.strict(), node)
 	if reference == nil {
 		// Should never get here!
 		panic(hereBeDragons("referenceError == nil: " + name))
@@ -44,7 +44,7 @@ func (self *_runtime) evaluateVariableDeclarationList(node *_variableDeclaration
 func (self *_runtime) evaluateVariableDeclaration(node *_variableDeclarationNode) Value {
 	if node.Operator != "" {
 		// FIXME If reference is nil
-		left := getIdentifierReference(self.LexicalEnvironment(), node.Identifier, false, node)
+		left := getIdentifierReference(self.LexicalEnvironment(), node.Identifier, self.strict(), node)
 		right := self.evaluate(node.Initializer)
 		rightValue := self.GetValue(right)
 
@@ -55,6 +55,9 @@ func (self *_runtime) evaluateVariableDeclaration(node *_variableDeclarationNode
 
 func (self *_runtime) evaluateThrow(node *_throwNode) Value {
 	value := self.GetValue(self.evaluate(node.Argument))
+	if self.debugger != nil {
+		self.debugger.exception(self, value)
+	}
 	panic(newException(value))
 }
 
@@ -71,8 +74,15 @@ func (self *_runtime) evaluateIf(node *_ifNode) Value {
 	test := self.evaluate(node.Test)
 	testValue := self.GetValue(test)
 	if toBoolean(testValue) {
+		if self.coverage != nil {
+			self.coverage.branch(node.position(), node.block, 0)
+		}
 		return self.evaluate(node.Consequent)
-	} else if node.Alternate != nil {
+	}
+	if self.coverage != nil {
+		self.coverage.branch(node.position(), node.block, 1)
+	}
+	if node.Alternate != nil {
 		return self.evaluate(node.Alternate)
 	}
 
@@ -190,6 +200,9 @@ resultBreak:
 			if toBoolean(testResultValue) == false {
 				break
 			}
+		} else {
+			// for (;;) {} would otherwise never evaluate anything
+			self.interrupt()
 		}
 		for _, node := range body {
 			value := self.evaluate(node)
@@ -241,7 +254,7 @@ func (self *_runtime) evaluateForIn(node *_forInNode) Value {
 			if into.reference() == nil {
 				identifier := toString(into)
 				// TODO Should be true or false (strictness) depending on context
-				into = toValue(getIdentifierReference(self.LexicalEnvironment(), identifier, false, node))
+				into = toValue(getIdentifierReference(self.LexicalEnvironment(), identifier, self.strict(), node))
 			}
 			self.PutValue(into.reference(), toValue_string(name))
 			for _, node := range body {
@@ -289,6 +302,14 @@ func (self *_runtime) evaluateSwitch(node *_switchNode) Value {
 		}
 	}
 
+	if self.coverage != nil {
+		way := target
+		if way == -1 {
+			way = len(node.CaseList) // No match
+		}
+		self.coverage.branch(node.position(), node.block, way)
+	}
+
 	switchValue := Value{}
 	if target != -1 {
 		labelSet := node.labelSet
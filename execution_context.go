@@ -3,11 +3,15 @@ package otto
 type _executionContext struct {
 	LexicalEnvironment  _environment
 	VariableEnvironment _environment
-	this                *_object
+	this                Value
 	eval                bool // Replace this with kind?
+	strict              bool // Whether the code being evaluated is strict mode code
+
+	function *_object // The function being called, or nil for global (and eval) code
+	node     _node    // The node currently being evaluated, for the stack trace
 }
 
-func newExecutionContext(lexical _environment, variable _environment, this *_object) *_executionContext {
+func newExecutionContext(lexical _environment, variable _environment, this Value) *_executionContext {
 	return &_executionContext{
 		LexicalEnvironment:  lexical,
 		VariableEnvironment: variable,
@@ -15,6 +19,38 @@ func newExecutionContext(lexical _environment, variable _environment, this *_obj
 	}
 }
 
+// frame returns the frame of the execution context (at the node being evaluated),
+// or false if the context is of a native (Go) function.
+func (self *_executionContext) frame() (Frame, bool) {
+	frame := Frame{}
+	if self.function != nil {
+		node := functionNode(self.function)
+		if node == nil {
+			return Frame{}, false
+		}
+		frame.Function = node.Name
+	} else if self.eval {
+		frame.Function = "eval"
+	}
+	if self.node != nil {
+		position := self.node.position()
+		frame.Filename, frame.Line, frame.Column = position.Filename, position.Line, position.Column
+	}
+	return frame, true
+}
+
+// functionNode returns the node of the (JavaScript) function, or nil if the
+// function is native (Go).
+func functionNode(function *_object) *_functionNode {
+	switch call := function.functionValue().call.(type) {
+	case *_nodeCallFunction:
+		return call.node
+	case _nodeCallFunction:
+		return call.node
+	}
+	return nil
+}
+
 func (self *_executionContext) getValue(name string) Value {
 	strict := false
 	return self.LexicalEnvironment.GetValue(name, strict)
@@ -0,0 +1,476 @@
+package otto
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// ExportTo converts the value into the Go value that target points to, which can
+// be of (almost) any type, unlike Export:
+//
+//		type Item struct {
+//			Name  string
+//			Price float64 `js:"price"`
+//		}
+//
+//		value, _ := Otto.Run(`[ { Name: "Xyzzy", price: 1.5 } ]`)
+//		var items []Item
+//		err := Otto.ExportTo(value, &items)
+//
+//		boolean     -> bool
+//		number      -> An integer type (if the number is a whole number in range) or a float type (if in range)
+//		string      -> string
+//		Array       -> A slice, or an array (of at least the length of the Array)
+//		Object      -> A struct (see SetFieldNameMapper), or a map (with string or number keys)
+//		Date        -> time.Time
+//		RegExp      -> *regexp.Regexp (unless the RegExp is not supported by RE2)
+//		null        -> The zero value (nil for a pointer, slice, map, etc.)
+//		undefined   -> The zero value
+//
+// A pointer is made (if nil) to hold the value it points to, an interface{} is set
+// to the result of Export, and a Value is set to the value itself. A struct field
+// (or map entry) is only set if the object has the property, so a field keeps its
+// value if the property is missing. A Go value (e.g. a struct given to Set) is
+// exported as is, if it is of the type.
+//
+// An object that is exported more than once as the same pointer (or map) type
+// becomes the same pointer (or map), so an object that refers to itself (e.g.
+// node.next = node) can be exported to a pointer (e.g. *Node, with a Next *Node).
+// Any other cycle (e.g. an Array that contains itself, for a []interface{}) is an
+// error, as is a sparse Array (e.g. with a length of 4294967295) for a slice.
+//
+// A value is not converted as it would be by JavaScript (e.g. "1" is not a number).
+// If a value cannot be converted, ExportTo returns an error with the path to it:
+//
+//		items[3].price: cannot convert string to float64
+//
+// ExportTo may leave the target partly set when it fails.
+func (self Otto) ExportTo(value Value, target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return fmt.Errorf("ExportTo: target is not a (non-nil) pointer: %T", target)
+	}
+	var err error
+	if caught := catchPanic(func() {
+		exporter := newExporter(self.runtime)
+		if value._valueType == valueObject {
+			// The target itself, for a cycle (through a pointer) back to the value
+			exporter.pointer[_exportKey{value._object(), targetValue.Type()}] = targetValue
+		}
+		err = exporter.export(value, targetValue.Elem(), "")
+	}); caught != nil {
+		return caught
+	}
+	return err
+}
+
+var (
+	exportTimeType   = reflect.TypeOf(time.Time{})
+	exportRegExpType = reflect.TypeOf((*regexp.Regexp)(nil))
+	exportValueType  = reflect.TypeOf(Value{})
+)
+
+// _exportError is an error of ExportTo, at the path (e.g. items[3].price) of the
+// value that could not be converted
+type _exportError struct {
+	path    string
+	message string
+}
+
+func (self *_exportError) Error() string {
+	if self.path == "" {
+		return self.message
+	}
+	return self.path + ": " + self.message
+}
+
+// exportMaxHoles is the greatest number of holes (missing elements) of a sparse
+// Array that is exported to a slice
+const exportMaxHoles = 1 << 20
+
+// _exporter is the state of ExportTo
+type _exporter struct {
+	runtime *_runtime
+	pointer map[_exportKey]reflect.Value // The pointer (or map) of each object, by type
+	active  map[*_object]bool            // The objects being exported (to a struct, slice, etc.)
+}
+
+type _exportKey struct {
+	object     *_object
+	targetType reflect.Type
+}
+
+func newExporter(runtime *_runtime) *_exporter {
+	return &_exporter{
+		runtime: runtime,
+		pointer: map[_exportKey]reflect.Value{},
+		active:  map[*_object]bool{},
+	}
+}
+
+// exportTo is ExportTo, for the arguments of a Go function, etc.
+func (self *_runtime) exportTo(value Value, target reflect.Value, path string) error {
+	return newExporter(self).export(value, target, path)
+}
+
+func exportErrorf(path string, format string, argumentList ...interface{}) error {
+	return &_exportError{path, fmt.Sprintf(format, argumentList...)}
+}
+
+// exportTypeName is the name of the type of the value, for an error: the typeof
+// of a primitive (e.g. string), or the class of an object (e.g. Array).
+func exportTypeName(value Value) string {
+	switch value._valueType {
+	case valueUndefined:
+		return "undefined"
+	case valueNull:
+		return "null"
+	case valueBoolean:
+		return "boolean"
+	case valueNumber:
+		return "number"
+	case valueString:
+		return "string"
+	case valueObject:
+		return value._object().class
+	}
+	return "value"
+}
+
+// exportPathName appends the name of the property to the path, as in JavaScript.
+func exportPathName(path string, name string) string {
+	identifier := name != ""
+	for index, chr := range name {
+		if !(unicode.IsLetter(chr) || chr == '_' || chr == '$' || index > 0 && unicode.IsDigit(chr)) {
+			identifier = false
+			break
+		}
+	}
+	if !identifier {
+		return path + "[" + strconv.Quote(name) + "]"
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// enter marks the object as being exported (until leave), or returns an error if it
+// already is, which is a cycle.
+func (self *_exporter) enter(object *_object, targetType reflect.Type, path string) error {
+	if self.active[object] {
+		return exportErrorf(path, "cannot convert a cyclic %s to %v", object.class, targetType)
+	}
+	self.active[object] = true
+	return nil
+}
+
+func (self *_exporter) leave(object *_object) {
+	delete(self.active, object)
+}
+
+// arrayIndexList returns the index of each element of the Array to export, which
+// is every index below the length, unless the Array is sparse (with fewer
+// properties than its length), when it is only the index of each (own) element.
+func (self *_exporter) arrayIndexList(object *_object) []int64 {
+	length := int64(objectLength(object))
+	if object.class != "Array" || length <= int64(len(object.property)) {
+		indexList := make([]int64, length)
+		for index := range indexList {
+			indexList[index] = int64(index)
+		}
+		return indexList
+	}
+	indexList := []int64{}
+	object.enumerate(true, func(name string) bool {
+		if index := stringToArrayIndex(name); index >= 0 && index < length {
+			indexList = append(indexList, index)
+		}
+		return true
+	})
+	sort.Slice(indexList, func(i, j int) bool {
+		return indexList[i] < indexList[j]
+	})
+	return indexList
+}
+
+// exportInterface is Value.export, except that a cycle is an error.
+func (self *_exporter) exportInterface(value Value, targetType reflect.Type, path string) (interface{}, error) {
+	if _, isValue := value.exportNative().(Value); !isValue || value._valueType != valueObject {
+		return value.export(), nil
+	}
+	object := value._object()
+	if err := self.enter(object, targetType, path); err != nil {
+		return nil, err
+	}
+	defer self.leave(object)
+	if object.class == "Array" {
+		result := make([]interface{}, 0)
+		for _, index := range self.arrayIndexList(object) {
+			name := strconv.FormatInt(index, 10)
+			if !object.hasProperty(name) {
+				continue
+			}
+			value, err := self.exportInterface(object.get(name), targetType, path+"["+name+"]")
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+	result := make(map[string]interface{})
+	var err error
+	object.enumerate(false, func(name string) bool {
+		value := object.get(name)
+		if value.IsDefined() {
+			result[name], err = self.exportInterface(value, targetType, exportPathName(path, name))
+		}
+		return err == nil
+	})
+	return result, err
+}
+
+func (self *_exporter) export(value Value, target reflect.Value, path string) error {
+	targetType := target.Type()
+
+	if targetType == exportValueType {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if value._valueType == valueUndefined || value._valueType == valueNull {
+		target.Set(reflect.Zero(targetType))
+		return nil
+	}
+
+	// A Go value (struct, map, slice, HostObject, ...) of the type, as is
+	if native := value.exportNative(); native != nil {
+		if _, isValue := native.(Value); !isValue {
+			if nativeValue := reflect.ValueOf(native); nativeValue.Type().AssignableTo(targetType) {
+				target.Set(nativeValue)
+				return nil
+			}
+		}
+	}
+
+	var object *_object
+	if value._valueType == valueObject {
+		object = value._object()
+	}
+
+	switch targetType {
+	case exportTimeType:
+		if object == nil || object.class != "Date" {
+			break
+		}
+		date := dateObjectOf(object)
+		if date.isNaN {
+			return exportErrorf(path, "cannot convert an invalid Date to %v", targetType)
+		}
+		target.Set(reflect.ValueOf(date.Time()))
+		return nil
+	case exportRegExpType:
+		if object == nil || object.class != "RegExp" {
+			break
+		}
+		regularExpression, valid := object.regExpValue().regularExpression.(*regexp.Regexp)
+		if !valid {
+			return exportErrorf(path, "cannot convert /%s/ to %v (not supported by RE2)", object.regExpValue().source, targetType)
+		}
+		target.Set(reflect.ValueOf(regularExpression))
+		return nil
+	}
+
+	switch targetType.Kind() {
+	case reflect.Interface:
+		result, err := self.exportInterface(value, targetType, path)
+		if err != nil {
+			return err
+		}
+		export := reflect.ValueOf(result)
+		if !export.IsValid() || !export.Type().AssignableTo(targetType) {
+			break
+		}
+		target.Set(export)
+		return nil
+	case reflect.Ptr:
+		if targetType == exportRegExpType { // Not a RegExp
+			break
+		}
+		if object != nil {
+			key := _exportKey{object, targetType}
+			if pointer, exists := self.pointer[key]; exists {
+				target.Set(pointer)
+				return nil
+			}
+			if target.IsNil() {
+				target.Set(reflect.New(targetType.Elem()))
+			}
+			self.pointer[key] = target
+			return self.export(value, target.Elem(), path)
+		}
+		if target.IsNil() {
+			target.Set(reflect.New(targetType.Elem()))
+		}
+		return self.export(value, target.Elem(), path)
+	case reflect.Bool:
+		if value._valueType != valueBoolean {
+			break
+		}
+		target.SetBool(value.toBoolean())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value._valueType != valueNumber {
+			break
+		}
+		number := toFloat(value)
+		// -2^63 <= number < 2^63 (which is out of range)
+		if number != math.Trunc(number) || number < -9223372036854775808 || number >= 9223372036854775808 || target.OverflowInt(int64(number)) {
+			return exportErrorf(path, "cannot convert %v to %v", value, targetType)
+		}
+		target.SetInt(int64(number))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value._valueType != valueNumber {
+			break
+		}
+		number := toFloat(value)
+		if number != math.Trunc(number) || number < 0 || number >= 18446744073709551616 || target.OverflowUint(uint64(number)) {
+			return exportErrorf(path, "cannot convert %v to %v", value, targetType)
+		}
+		target.SetUint(uint64(number))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if value._valueType != valueNumber {
+			break
+		}
+		number := toFloat(value)
+		if target.OverflowFloat(number) { // A float32 is out of range (but Infinity is not)
+			return exportErrorf(path, "cannot convert %v to %v", value, targetType)
+		}
+		target.SetFloat(number)
+		return nil
+	case reflect.String:
+		if value._valueType != valueString {
+			break
+		}
+		target.SetString(value.toString())
+		return nil
+	case reflect.Slice:
+		if !isArray(object) {
+			break
+		}
+		if err := self.enter(object, targetType, path); err != nil {
+			return err
+		}
+		defer self.leave(object)
+		length := int64(objectLength(object))
+		indexList := self.arrayIndexList(object)
+		if length-int64(len(indexList)) > exportMaxHoles {
+			return exportErrorf(path, "cannot convert a sparse %s (of length %d) to %v", object.class, length, targetType)
+		}
+		self.runtime.allocate(int(length) * int(targetType.Elem().Size()))
+		slice := reflect.MakeSlice(targetType, int(length), int(length))
+		for _, index := range indexList {
+			name := strconv.FormatInt(index, 10)
+			err := self.export(object.get(name), slice.Index(int(index)), path+"["+name+"]")
+			if err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+	case reflect.Array:
+		if !isArray(object) {
+			break
+		}
+		length := int64(objectLength(object))
+		if length > int64(target.Len()) {
+			return exportErrorf(path, "cannot convert %s (of length %d) to %v", object.class, length, targetType)
+		}
+		if err := self.enter(object, targetType, path); err != nil {
+			return err
+		}
+		defer self.leave(object)
+		target.Set(reflect.Zero(targetType))
+		for index := 0; index < int(length); index++ {
+			err := self.export(object.get(strconv.Itoa(index)), target.Index(index), path+"["+strconv.Itoa(index)+"]")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if object == nil || isArray(object) || object.class == "Function" {
+			break
+		}
+		key := _exportKey{object, targetType}
+		if result, exists := self.pointer[key]; exists {
+			target.Set(result)
+			return nil
+		}
+		keyType := targetType.Key()
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(targetType))
+		}
+		self.pointer[key] = target
+		if err := self.enter(object, targetType, path); err != nil {
+			return err
+		}
+		defer self.leave(object)
+		var err error
+		object.enumerate(false, func(name string) bool {
+			var key reflect.Value
+			if keyType.Kind() == reflect.String {
+				key = reflect.ValueOf(name).Convert(keyType)
+			} else {
+				key, err = stringToReflectValue(name, keyType.Kind())
+				if err != nil {
+					err = exportErrorf(path, "cannot convert key %q to %v", name, keyType)
+					return false
+				}
+				key = key.Convert(keyType)
+			}
+			element := reflect.New(targetType.Elem()).Elem()
+			if err = self.export(object.get(name), element, exportPathName(path, name)); err != nil {
+				return false
+			}
+			target.SetMapIndex(key, element)
+			return true
+		})
+		return err
+	case reflect.Struct:
+		if object == nil || isArray(object) || object.class == "Function" {
+			break
+		}
+		if err := self.enter(object, targetType, path); err != nil {
+			return err
+		}
+		defer self.leave(object)
+		structType := self.runtime.goStructType(targetType)
+		for _, name := range structType.nameList {
+			index, exists := structType.field[name]
+			if !exists || !object.hasProperty(name) {
+				continue
+			}
+			// Make the embedded struct (pointer), if it is nil
+			field, valid := goStructField(target, index, true)
+			if !valid {
+				return exportErrorf(exportPathName(path, name), "cannot make the embedded struct of %s", name)
+			}
+			if err := self.export(object.get(name), field, exportPathName(path, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return exportErrorf(path, "cannot convert %s to %v", exportTypeName(value), targetType)
+}
@@ -0,0 +1,139 @@
+package otto
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	. "./terst"
+)
+
+type testExportItem struct {
+	Name  string
+	Price float64 `js:"price"`
+	Tags  map[string]int
+	Next  *testExportItem
+}
+
+func TestExportTo(t *testing.T) {
+	Terst(t)
+
+	Otto, test := runTestWithOtto()
+
+	value := test(`
+        [
+            { Name: "Abc", price: 1.5, Tags: { def: 1 } },
+            { Name: "Ghi", price: 2, Next: { Name: "Jkl" } },
+            null
+        ];
+    `)
+	var itemList []*testExportItem
+	Is(Otto.ExportTo(value, &itemList), nil)
+	Is(len(itemList), 3)
+	Is(itemList[0].Name, "Abc")
+	Is(itemList[0].Price, 1.5)
+	Is(itemList[0].Tags["def"], 1)
+	Is(itemList[1].Next.Name, "Jkl")
+	Is(itemList[1].Next.Next == nil, true)
+	Is(itemList[2] == nil, true)
+
+	{
+		var array [3]int
+		Is(Otto.ExportTo(test(`[ 1, 2 ]`), &array), nil)
+		Is(array, [3]int{1, 2, 0})
+
+		var count map[int]uint8
+		Is(Otto.ExportTo(test(`({ 1: 2, 3: 4 })`), &count), nil)
+		Is(count, map[int]uint8{1: 2, 3: 4})
+
+		var any interface{}
+		Is(Otto.ExportTo(test(`({ abc: [ 1, "def" ] })`), &any), nil)
+		Is(fmt.Sprint(any), "map[abc:[1 def]]")
+
+		var date time.Time
+		Is(Otto.ExportTo(test(`new Date(Date.UTC(2001, 1, 3, 4, 5, 6))`), &date), nil)
+		Is(date.UTC().Format(time.RFC3339), "2001-02-03T04:05:06Z")
+
+		var matcher *regexp.Regexp
+		Is(Otto.ExportTo(test(`/ab+c/`), &matcher), nil)
+		Is(matcher.MatchString("xabbbc"), true)
+	}
+
+	{
+		item := &testExportItem{Name: "Xyzzy"}
+		failSet("item", item)
+		var result *testExportItem
+		Is(Otto.ExportTo(test(`item`), &result), nil)
+		Is(result == item, true)
+	}
+
+	{
+		var result []testExportItem
+		err := Otto.ExportTo(test(`[ {}, {}, {}, { price: "1" } ]`), &result)
+		Is(err, "[3].price: cannot convert string to float64")
+
+		var items map[string][]testExportItem
+		err = Otto.ExportTo(test(`({ items: [ { Next: { Tags: { "a b": true } } } ] })`), &items)
+		Is(err, `items[0].Next.Tags["a b"]: cannot convert boolean to int`)
+
+		var number int8
+		Is(Otto.ExportTo(test(`1.5`), &number), "cannot convert 1.5 to int8")
+		Is(Otto.ExportTo(test(`128`), &number), "cannot convert 128 to int8")
+
+		var float float32
+		Is(Otto.ExportTo(test(`1e300`), &float), "cannot convert 1e+300 to float32")
+		Is(Otto.ExportTo(test(`[ -1e300 ]`), &[]float32{}), "[0]: cannot convert -1e+300 to float32")
+		Is(Otto.ExportTo(test(`Infinity`), &float), nil)
+		Is(math.IsInf(float64(float), 1), true)
+		Is(Otto.ExportTo(test(`[ 1, 2, 3 ]`), &[2]int{}), "cannot convert Array (of length 3) to [2]int")
+		Is(Otto.ExportTo(test(`"abc"`), new(*regexp.Regexp)), "cannot convert string to *regexp.Regexp")
+		Is(Otto.ExportTo(test(`new Date(NaN)`), &time.Time{}), "cannot convert an invalid Date to time.Time")
+		Is(Otto.ExportTo(test(`/(a)\1/`), new(*regexp.Regexp)), `cannot convert /(a)\1/ to *regexp.Regexp (not supported by RE2)`)
+		Is(Otto.ExportTo(test(`"abc"`), number), "ExportTo: target is not a (non-nil) pointer: int8")
+
+		Is(Otto.ExportTo(test(`({ get price() { throw new Error("Xyzzy") } })`), &testExportItem{}), "Error: Xyzzy")
+	}
+
+	{
+		// A cycle through a pointer is the same pointer
+		test(`
+            var node = { Value: 1 };
+            node.Next = node;
+        `)
+		var node *testExportNode
+		Is(Otto.ExportTo(test(`node`), &node), nil)
+		Is(node.Next == node, true)
+		Is(node.Value, 1)
+
+		var value testExportNode
+		Is(Otto.ExportTo(test(`node`), &value), nil)
+		Is(value.Next.Next == value.Next, true)
+
+		var nodes map[string]interface{}
+		Is(Otto.ExportTo(test(`({ a: node, b: node })`), &map[string]*testExportNode{}), nil)
+		Is(Otto.ExportTo(test(`node`), &nodes), "Next: cannot convert a cyclic Object to interface {}")
+
+		var any interface{}
+		Is(Otto.ExportTo(test(`var abc = [ 1 ]; abc.push(abc); abc`), &any), "[1]: cannot convert a cyclic Array to interface {}")
+		Is(Otto.ExportTo(test(`abc`), &[]interface{}{}), "[1]: cannot convert a cyclic Array to interface {}")
+		Is(Otto.ExportTo(test(`var def = []; def.push(def); def`), &[][]interface{}{}), "[0]: cannot convert a cyclic Array to []interface {}")
+	}
+
+	{
+		var result []int
+		Is(Otto.ExportTo(test(`var abc = []; abc.length = 4294967295; abc`), &result), "cannot convert a sparse Array (of length 4294967295) to []int")
+		Is(Otto.ExportTo(test(`abc = []; abc[10] = 1; abc.length = 12; abc`), &result), nil)
+		Is(fmt.Sprint(result), "[0 0 0 0 0 0 0 0 0 0 1 0]")
+
+		var any interface{}
+		Is(Otto.ExportTo(test(`abc = [ 1 ]; abc[100000] = 2; abc.length = 4294967295; abc`), &any), nil)
+		Is(fmt.Sprint(any), "[1 2]")
+	}
+}
+
+type testExportNode struct {
+	Value int
+	Next  *testExportNode
+}
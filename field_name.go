@@ -0,0 +1,119 @@
+package otto
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldNameMapper maps the fields and methods of a Go struct to the names of the
+// properties that a script sees, for getting, setting, and enumerating them:
+//
+//		type User struct {
+//			FirstName string
+//			Password  string `js:"-"`
+//			LastLogin int64  `json:"last_login"`
+//		}
+//
+//		Otto.SetFieldNameMapper(otto.TagFieldNameMapper("json", true))
+//		Otto.Set("user", &User{FirstName: "Xyzzy"})
+//		Otto.Run(`[ user.firstName, user.password, user.last_login ]`) // Xyzzy,,0
+//
+// By default, a field (or method) has the same name as in Go, unless the field
+// has a js tag (see TagFieldNameMapper).
+//
+// Only exported fields and methods can be mapped, and the fields of an embedded
+// struct are mapped as if they were fields of the outer struct (as in Go), so the
+// embedded struct itself is not mapped, unless a TagFieldNameMapper finds it named
+// by a tag. If two members map to the same name, the first (the field, or the
+// earlier field) wins. A field of a nil embedded struct pointer is not enumerated
+// (and is undefined), until it is set, which makes the embedded struct.
+type FieldNameMapper interface {
+	// FieldName returns the name of the property for the field of the struct type,
+	// or "" to hide the field.
+	FieldName(t reflect.Type, field reflect.StructField) string
+
+	// MethodName returns the name of the property for the method of the type (a
+	// struct, or a pointer to a struct), or "" to hide the method.
+	MethodName(t reflect.Type, method reflect.Method) string
+}
+
+// TagFieldNameMapper returns a FieldNameMapper that names a field by its js tag,
+// or (if tagName is not "js") by its tagName tag, as in encoding/json:
+//
+//		Name    string `js:"name"`
+//		Address string `json:"address,omitempty"`
+//		Secret  string `js:"-"` // Hidden
+//
+// A field without a name in either tag (and every method) keeps its Go name, or,
+// if lowerCamelCase is true, has a lowerCamelCase version of it (FirstName becomes
+// firstName, and URLPath becomes urlPath).
+func TagFieldNameMapper(tagName string, lowerCamelCase bool) FieldNameMapper {
+	return _tagFieldNameMapper{
+		tagName:        tagName,
+		lowerCamelCase: lowerCamelCase,
+	}
+}
+
+type _tagFieldNameMapper struct {
+	tagName        string
+	lowerCamelCase bool
+}
+
+var defaultFieldNameMapper = TagFieldNameMapper("js", false)
+
+func (self _tagFieldNameMapper) FieldName(t reflect.Type, field reflect.StructField) string {
+	if name, tagged := self.tag(field); tagged {
+		return name
+	}
+	return self.name(field.Name)
+}
+
+// tag returns the name of the field in its js (or tagName) tag, which is "" if the
+// tag hides the field, or false if neither tag names (or hides) the field.
+func (self _tagFieldNameMapper) tag(field reflect.StructField) (string, bool) {
+	for _, tagName := range []string{"js", self.tagName} {
+		tag := field.Tag.Get(tagName)
+		if tag == "-" {
+			return "", true
+		}
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (self _tagFieldNameMapper) MethodName(t reflect.Type, method reflect.Method) string {
+	return self.name(method.Name)
+}
+
+func (self _tagFieldNameMapper) name(name string) string {
+	if self.lowerCamelCase {
+		return lowerCamelCase(name)
+	}
+	return name
+}
+
+// lowerCamelCase lowers the leading upper case letters of the name (but not the
+// first letter of the next word, if any).
+func lowerCamelCase(name string) string {
+	runeList := []rune(name)
+	for index, chr := range runeList {
+		if !unicode.IsUpper(chr) {
+			break
+		}
+		if index > 0 && index+1 < len(runeList) && unicode.IsLower(runeList[index+1]) {
+			break
+		}
+		runeList[index] = unicode.ToLower(chr)
+	}
+	return string(runeList)
+}
+
+// SetFieldNameMapper sets the FieldNameMapper of the runtime (or restores the
+// default, if nil).
+func (self Otto) SetFieldNameMapper(mapper FieldNameMapper) {
+	self.runtime.fieldNameMapper = mapper
+	self.runtime.goStructTypes = nil
+}
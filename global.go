@@ -190,16 +190,20 @@ func (runtime *_runtime) newError(name string, message Value) *_object {
 		return runtime.newURIError(message)
 	}
 
-	self = runtime.newErrorObject(message)
-	self.prototype = runtime.Global.ErrorPrototype
+	self = runtime.newErrorObject(runtime.Global.ErrorPrototype, message)
 	if name != "" {
 		self.defineProperty("name", toValue_string(name), 0111, false)
+		runtime.setErrorStack(self, self.errorValue().stack) // Again, with the name
 	}
 	return self
 }
 
 func (runtime *_runtime) newNativeFunction(_nativeFunction _nativeFunction) *_object {
-	self := runtime.newNativeFunctionObject(_nativeFunction, 0)
+	self := runtime.newNativeFunctionObject(func(call FunctionCall) Value {
+		return call.runtime.callNative(func() Value {
+			return _nativeFunction(call)
+		})
+	}, 0)
 	self.prototype = runtime.Global.FunctionPrototype
 	prototype := runtime.newObject()
 	self.defineProperty("prototype", toValue_object(prototype), 0100, false)
@@ -6077,7 +6077,7 @@ func newConsoleObject(runtime *_runtime) *_object {
 				"length",
 			},
 			value: _functionObject{
-				call: _nativeCallFunction(builtinConsole_log),
+				call: _nativeCallFunction(builtinConsole_debug),
 			},
 		}
 		info_function := &_object{
@@ -6099,7 +6099,7 @@ func newConsoleObject(runtime *_runtime) *_object {
 				"length",
 			},
 			value: _functionObject{
-				call: _nativeCallFunction(builtinConsole_log),
+				call: _nativeCallFunction(builtinConsole_info),
 			},
 		}
 		error_function := &_object{
@@ -6143,7 +6143,7 @@ func newConsoleObject(runtime *_runtime) *_object {
 				"length",
 			},
 			value: _functionObject{
-				call: _nativeCallFunction(builtinConsole_error),
+				call: _nativeCallFunction(builtinConsole_warn),
 			},
 		}
 		dir_function := &_object{
@@ -6256,6 +6256,94 @@ func newConsoleObject(runtime *_runtime) *_object {
 				call: _nativeCallFunction(builtinConsole_assert),
 			},
 		}
+		count_function := &_object{
+			runtime:     runtime,
+			class:       "Function",
+			objectClass: _classObject,
+			prototype:   runtime.Global.FunctionPrototype,
+			extensible:  true,
+			property: map[string]_property{
+				"length": _property{
+					mode: 0,
+					value: Value{
+						_valueType: valueNumber,
+						value:      0,
+					},
+				},
+			},
+			propertyOrder: []string{
+				"length",
+			},
+			value: _functionObject{
+				call: _nativeCallFunction(builtinConsole_count),
+			},
+		}
+		group_function := &_object{
+			runtime:     runtime,
+			class:       "Function",
+			objectClass: _classObject,
+			prototype:   runtime.Global.FunctionPrototype,
+			extensible:  true,
+			property: map[string]_property{
+				"length": _property{
+					mode: 0,
+					value: Value{
+						_valueType: valueNumber,
+						value:      0,
+					},
+				},
+			},
+			propertyOrder: []string{
+				"length",
+			},
+			value: _functionObject{
+				call: _nativeCallFunction(builtinConsole_group),
+			},
+		}
+		groupCollapsed_function := &_object{
+			runtime:     runtime,
+			class:       "Function",
+			objectClass: _classObject,
+			prototype:   runtime.Global.FunctionPrototype,
+			extensible:  true,
+			property: map[string]_property{
+				"length": _property{
+					mode: 0,
+					value: Value{
+						_valueType: valueNumber,
+						value:      0,
+					},
+				},
+			},
+			propertyOrder: []string{
+				"length",
+			},
+			value: _functionObject{
+				call: _nativeCallFunction(builtinConsole_group),
+			},
+		}
+		groupEnd_function := &_object{
+			runtime:     runtime,
+			class:       "Function",
+			objectClass: _classObject,
+			prototype:   runtime.Global.FunctionPrototype,
+			extensible:  true,
+			property: map[string]_property{
+				"length": _property{
+					mode: 0,
+					value: Value{
+						_valueType: valueNumber,
+						value:      0,
+					},
+				},
+			},
+			propertyOrder: []string{
+				"length",
+			},
+			value: _functionObject{
+				call: _nativeCallFunction(builtinConsole_groupEnd),
+			},
+		}
 		return &_object{
 			runtime:     runtime,
 			class:       "Object",
@@ -6333,6 +6421,34 @@ func newConsoleObject(runtime *_runtime) *_object {
 						value:      assert_function,
 					},
 				},
+				"count": _property{
+					mode: 0101,
+					value: Value{
+						_valueType: valueObject,
+						value:      count_function,
+					},
+				},
+				"group": _property{
+					mode: 0101,
+					value: Value{
+						_valueType: valueObject,
+						value:      group_function,
+					},
+				},
+				"groupCollapsed": _property{
+					mode: 0101,
+					value: Value{
+						_valueType: valueObject,
+						value:      groupCollapsed_function,
+					},
+				},
+				"groupEnd": _property{
+					mode: 0101,
+					value: Value{
+						_valueType: valueObject,
+						value:      groupEnd_function,
+					},
+				},
 			},
 			propertyOrder: []string{
 				"log",
@@ -6345,6 +6461,10 @@ func newConsoleObject(runtime *_runtime) *_object {
 				"timeEnd",
 				"trace",
 				"assert",
+				"count",
+				"group",
+				"groupCollapsed",
+				"groupEnd",
 			},
 		}
 	}
@@ -0,0 +1,312 @@
+package otto
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InspectOptions are the options of Value.Inspect.
+type InspectOptions struct {
+	// Depth is how many levels of nested objects will be shown, where a
+	// Depth of 0 means the default (2), and a negative Depth means no limit.
+	Depth int
+
+	// Color will style the output with ANSI color codes (for a terminal).
+	Color bool
+}
+
+// The width beyond which an object (or array) is spread over multiple lines
+const inspectBreakLength = 72
+
+// The most elements of an array that are shown (with "... N more items" for the rest)
+const inspectMaxArrayLength = 100
+
+var (
+	inspectKey_Regexp  = regexp.MustCompile(`^[a-zA-Z_\$][a-zA-Z0-9_\$]*$`)
+	inspectAnsi_Regexp = regexp.MustCompile("\x1b\\[\\d+m")
+)
+
+// Inspect returns a human-readable representation of the value, in the style of
+// util.inspect from Node.js:
+//
+//		{ abc: 1, def: [ 'ghi', true ], jkl: [Function: jkl], mno: { pqr: [Object] } }
+//
+// Nested objects, arrays, functions, dates, regular expressions, errors, and
+// Go-backed values (struct, map, slice) are all shown. An object that refers to
+// itself is shown as [Circular]. Only the first 100 elements of an array are
+// shown, and a getter (or setter) is shown as [Getter] (or [Setter]), and not
+// called.
+//
+// This is what console.log uses to show a value that is not a string.
+func (value Value) Inspect(options InspectOptions) string {
+	if options.Depth == 0 {
+		options.Depth = 2
+	}
+	inspector := &_inspector{
+		options: options,
+	}
+	result := ""
+	err := catchPanic(func() {
+		result = inspector.inspect(value, 0, "")
+	})
+	if err != nil {
+		return value.String()
+	}
+	return result
+}
+
+type _inspector struct {
+	options InspectOptions
+	seen    []*_object // The objects currently being inspected, for detecting a cycle
+}
+
+func (self *_inspector) style(text string, color int) string {
+	if !self.options.Color {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[39m", color, text)
+}
+
+const (
+	inspectBold    = 1
+	inspectRed     = 31
+	inspectGreen   = 32
+	inspectYellow  = 33
+	inspectMagenta = 35
+	inspectCyan    = 36
+	inspectGrey    = 90
+)
+
+func (self *_inspector) inspect(value Value, depth int, indent string) string {
+	switch value._valueType {
+	case valueEmpty, valueUndefined:
+		return self.style("undefined", inspectGrey)
+	case valueNull:
+		return self.style("null", inspectBold)
+	case valueBoolean, valueNumber:
+		return self.style(value.toString(), inspectYellow)
+	case valueString:
+		return self.style(inspectQuote(value.toString()), inspectGreen)
+	case valueObject:
+		return self.inspectObject(value._object(), depth, indent)
+	}
+	return value.toString()
+}
+
+// inspectProperty inspects the value of the (own) property of the object, which is
+// [Getter], [Setter], or [Getter/Setter] for an accessor (which is not called).
+func (self *_inspector) inspectProperty(object *_object, name string, depth int, indent string) string {
+	property := object.getOwnProperty(name)
+	if property == nil {
+		return self.inspect(UndefinedValue(), depth+1, indent)
+	}
+	if getSet, isAccessor := property.value.(_propertyGetSet); isAccessor {
+		getter := getSet[0] != nil && getSet[0] != &_nilGetSetObject
+		setter := getSet[1] != nil && getSet[1] != &_nilGetSetObject
+		switch {
+		case getter && setter:
+			return self.style("[Getter/Setter]", inspectCyan)
+		case setter:
+			return self.style("[Setter]", inspectCyan)
+		}
+		return self.style("[Getter]", inspectCyan)
+	}
+	return self.inspect(property.get(object), depth+1, indent)
+}
+
+func inspectQuote(value string) string {
+	quoted := strconv.Quote(value)
+	quoted = strings.Replace(quoted[1:len(quoted)-1], `\"`, `"`, -1)
+	return "'" + strings.Replace(quoted, "'", `\'`, -1) + "'"
+}
+
+func inspectKey(name string) string {
+	if inspectKey_Regexp.MatchString(name) {
+		return name
+	}
+	return inspectQuote(name)
+}
+
+func (self *_inspector) inspectObject(object *_object, depth int, indent string) string {
+	for _, seen := range self.seen {
+		if seen == object {
+			return self.style("[Circular]", inspectCyan)
+		}
+	}
+
+	prefix := "" // e.g. [Function: abc] or [Number: 1]
+	switch object.class {
+	case "Function":
+		name := ""
+		switch call := object.functionValue().call.(type) {
+		case *_nodeCallFunction:
+			name = call.node.Name
+		case _nodeCallFunction:
+			name = call.node.Name
+		}
+		if name == "" {
+			prefix = self.style("[Function]", inspectCyan)
+		} else {
+			prefix = self.style("[Function: "+name+"]", inspectCyan)
+		}
+	case "RegExp":
+		prefix = self.style(toString(toValue_object(object)), inspectRed)
+	case "Date":
+		date := dateObjectOf(object)
+		if date.isNaN {
+			prefix = self.style("Invalid Date", inspectMagenta)
+		} else {
+			prefix = self.style(date.Time().UTC().Format("2006-01-02T15:04:05.000Z"), inspectMagenta)
+		}
+	case "Error":
+		prefix = "[" + errorToString(object) + "]"
+	case "Boolean", "Number", "String":
+		prefix = fmt.Sprintf("[%s: %s]", object.class, self.inspect(object.primitiveValue(), depth, indent))
+	}
+
+	array := isArray(object)
+	nameList := []string{}
+	switch value := object.value.(type) {
+	case *_goStructObject:
+		prefix = reflect.Indirect(value.value).Type().String()
+		structType := object.runtime.goStructType(value.value.Type())
+		for _, name := range structType.nameList {
+			if _, exists := structType.field[name]; exists {
+				nameList = append(nameList, name)
+			}
+		}
+	case *_goMapObject:
+		prefix = value.value.Type().String()
+		object.enumerate(false, func(name string) bool {
+			nameList = append(nameList, name)
+			return true
+		})
+		sort.Strings(nameList)
+	default:
+		object.enumerate(false, func(name string) bool {
+			if array && stringToArrayIndex(name) >= 0 {
+				return true // The elements are done separately (below)
+			}
+			if object.class == "String" && stringToArrayIndex(name) >= 0 {
+				return true // The characters of a String object
+			}
+			if object.class == "Error" && name == "message" {
+				return true // Already part of the prefix
+			}
+			nameList = append(nameList, name)
+			return true
+		})
+	}
+
+	if !array && len(nameList) == 0 {
+		if prefix != "" {
+			return prefix
+		}
+		return "{}"
+	}
+
+	if self.options.Depth >= 0 && depth > self.options.Depth {
+		if array {
+			return self.style("[Array]", inspectCyan)
+		}
+		if prefix != "" && object.class != "Object" {
+			return prefix
+		}
+		return self.style("[Object]", inspectCyan)
+	}
+
+	self.seen = append(self.seen, object)
+	defer func() {
+		self.seen = self.seen[:len(self.seen)-1]
+	}()
+
+	nestedIndent := indent + "  "
+	entryList := []string{}
+	if array {
+		length := int64(objectLength(object))
+		// The (own) elements of a sparse array, in order, rather than every index
+		var indexList []int64
+		if object.class == "Array" && length > int64(len(object.property)) {
+			indexList = []int64{}
+			object.enumerate(true, func(name string) bool {
+				if index := stringToArrayIndex(name); index >= 0 && index < length {
+					indexList = append(indexList, index)
+				}
+				return true
+			})
+			sort.Slice(indexList, func(i, j int) bool {
+				return indexList[i] < indexList[j]
+			})
+		}
+		count := 0 // The elements (and runs of empty items) shown
+		for index := int64(0); index < length; {
+			if count == inspectMaxArrayLength {
+				more := length - index
+				if more == 1 {
+					entryList = append(entryList, "... 1 more item")
+				} else {
+					entryList = append(entryList, fmt.Sprintf("... %d more items", more))
+				}
+				break
+			}
+			next := index // The next element
+			if indexList != nil {
+				for len(indexList) > 0 && indexList[0] < index {
+					indexList = indexList[1:]
+				}
+				next = length
+				if len(indexList) > 0 {
+					next = indexList[0]
+				}
+			} else {
+				for next < length && object.getOwnProperty(arrayIndexToString(next)) == nil {
+					next++
+				}
+			}
+			if empty := next - index; empty == 1 {
+				entryList = append(entryList, self.style("<1 empty item>", inspectGrey))
+			} else if empty > 1 {
+				entryList = append(entryList, self.style(fmt.Sprintf("<%d empty items>", empty), inspectGrey))
+			}
+			if next > index {
+				count++
+				index = next
+				continue
+			}
+			entryList = append(entryList, self.inspectProperty(object, arrayIndexToString(index), depth, nestedIndent))
+			count++
+			index++
+		}
+	}
+	for _, name := range nameList {
+		entryList = append(entryList, inspectKey(name)+": "+self.inspectProperty(object, name, depth, nestedIndent))
+	}
+
+	open, close := "{", "}"
+	if array {
+		open, close = "[", "]"
+	}
+	if prefix != "" {
+		open = prefix + " " + open
+	}
+	if len(entryList) == 0 {
+		return open + close
+	}
+
+	length := len(indent) + len(open) + len(close)
+	multiline := false
+	for _, entry := range entryList {
+		length += len(inspectAnsi_Regexp.ReplaceAllString(entry, "")) + 2
+		if strings.Contains(entry, "\n") {
+			multiline = true
+		}
+	}
+	if !multiline && length <= inspectBreakLength {
+		return open + " " + strings.Join(entryList, ", ") + " " + close
+	}
+	return open + "\n" + nestedIndent + strings.Join(entryList, ",\n"+nestedIndent) + "\n" + indent + close
+}
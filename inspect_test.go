@@ -0,0 +1,79 @@
+package otto
+
+import (
+	. "./terst"
+	"strings"
+	"testing"
+)
+
+func TestValue_Inspect(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	inspect := func(source string, options ...InspectOptions) string {
+		value, _ := Otto.Run(source)
+		if len(options) == 0 {
+			options = append(options, InspectOptions{})
+		}
+		return value.Inspect(options[0])
+	}
+
+	Is(inspect(`undefined`), "undefined")
+	Is(inspect(`null`), "null")
+	Is(inspect(`3.14159`), "3.14159")
+	Is(inspect(`"Nothing 'happens'."`), `'Nothing \'happens\'.'`)
+	Is(inspect(`"abc\ndef"`), `'abc\ndef'`)
+	Is(inspect(`[ 1, "abc", true, null, undefined ]`), "[ 1, 'abc', true, null, undefined ]")
+	Is(inspect(`[]`), "[]")
+	Is(inspect(`[ 1, , , 4, , 6 ]`), "[ 1, <2 empty items>, 4, <1 empty item>, 6 ]")
+	Is(inspect(`({})`), "{}")
+	Is(inspect(`({ abc: 1, "d-f": { ghi: [ 2, 3 ] }, _jkl: "mno" })`), "{ abc: 1, 'd-f': { ghi: [ 2, 3 ] }, _jkl: 'mno' }")
+	Is(inspect(`({ abc: { def: { ghi: { jkl: 1 } } }, mno: [ [ [ [ 1 ] ] ] ] })`), "{ abc: { def: { ghi: [Object] } }, mno: [ [ [Array] ] ] }")
+	Is(inspect(`({ abc: { def: { ghi: { jkl: 1 } } } })`, InspectOptions{Depth: -1}), "{ abc: { def: { ghi: { jkl: 1 } } } }")
+	Is(inspect(`({ abc: { def: { ghi: 1 } } })`, InspectOptions{Depth: 1}), "{ abc: { def: [Object] } }")
+	Is(inspect(`xyzzy = { abc: 1 }; xyzzy.def = xyzzy; xyzzy`), "{ abc: 1, def: [Circular] }")
+	Is(inspect(`abc = [ 1 ]; [ abc, abc ]`), "[ [ 1 ], [ 1 ] ]")
+	Is(inspect(`(function abc(){})`), "[Function: abc]")
+	Is(inspect(`[ function(){}, Math.max ]`), "[ [Function], [Function] ]")
+	Is(inspect(`abc = function def(){}; abc.ghi = 1; abc`), "[Function: def] { ghi: 1 }")
+	Is(inspect(`/abc/gi`), "/abc/gi")
+	Is(inspect(`new Date(0)`), "1970-01-01T00:00:00.000Z")
+	Is(inspect(`new Date(NaN)`), "Invalid Date")
+	Is(inspect(`new TypeError("Nothing happens.")`), "[TypeError: Nothing happens.]")
+	Is(inspect(`[ new Number(1), new String("abc"), new Boolean(false) ]`), "[ [Number: 1], [String: 'abc'], [Boolean: false] ]")
+	Is(inspect(`abc = [ 1, 2 ]; abc.def = 3; abc`), "[ 1, 2, def: 3 ]")
+	Is(inspect(`abc = []; abc[2] = 1; abc[4294967290] = 2; abc`), "[ <2 empty items>, 1, <4294967287 empty items>, 2 ]")
+	Is(inspect(`abc = []; abc.length = 4294967295; abc`), "[ <4294967295 empty items> ]")
+	Is(inspect(`abc = []; for (i = 0; i < 103; i++) { abc.push(1) }; abc`), "[\n"+strings.Repeat("  1,\n", 100)+"  ... 3 more items\n]")
+	Is(inspect(`abc.length = 101; abc.splice(0, 99); abc[0] = 2; abc.length = 200; abc`), "[ 2, 1, <198 empty items> ]")
+	Is(inspect(`({ get abc() { throw new Error("Nothing happens.") }, set def(value) {}, get ghi() {}, set ghi(value) {}, jkl: 1 })`),
+		"{ abc: [Getter], def: [Setter], ghi: [Getter/Setter], jkl: 1 }")
+
+	Is(inspect(`({ abcdefghijklmnopqrstuvwxyz: 1, ABCDEFGHIJKLMNOPQRSTUVWXYZ: 2, xyzzy: "Nothing happens." })`),
+		"{\n  abcdefghijklmnopqrstuvwxyz: 1,\n  ABCDEFGHIJKLMNOPQRSTUVWXYZ: 2,\n  xyzzy: 'Nothing happens.'\n}")
+	Is(inspect(`({ abc: { abcdefghijklmnopqrstuvwxyz: 1, ABCDEFGHIJKLMNOPQRSTUVWXYZ: 2, xyzzy: "Nothing happens." } })`),
+		"{\n  abc: {\n    abcdefghijklmnopqrstuvwxyz: 1,\n    ABCDEFGHIJKLMNOPQRSTUVWXYZ: 2,\n    xyzzy: 'Nothing happens.'\n  }\n}")
+
+	Is(inspect(`[ 1, "abc", null, undefined ]`, InspectOptions{Color: true}), "[ \x1b[33m1\x1b[39m, \x1b[32m'abc'\x1b[39m, \x1b[1mnull\x1b[39m, \x1b[90mundefined\x1b[39m ]")
+}
+
+func TestValue_Inspect_Go(t *testing.T) {
+	Terst(t)
+
+	type _abc struct {
+		Xyzzy string
+		Def   []int
+		ghi   bool
+	}
+
+	Otto := New()
+
+	value, _ := Otto.ToValue(&_abc{Xyzzy: "Nothing happens.", Def: []int{1, 2}})
+	Is(value.Inspect(InspectOptions{}), "otto._abc { Xyzzy: 'Nothing happens.', Def: [ 1, 2 ] }")
+
+	value, _ = Otto.ToValue(map[string]int{"def": 2, "abc": 1})
+	Is(value.Inspect(InspectOptions{}), "map[string]int { abc: 1, def: 2 }")
+
+	value, _ = Otto.ToValue([]string{"abc", "def"})
+	Is(value.Inspect(InspectOptions{}), "[ 'abc', 'def' ]")
+}
@@ -0,0 +1,112 @@
+package otto
+
+import (
+	"errors"
+)
+
+// Limits are limits on the resources used by a runtime, for running untrusted
+// (third-party) code. A limit of 0 means there is no limit, which is the default
+// (except for CallDepth, see DefaultCallDepth).
+//
+// Nodes and Memory are budgets for the lifetime of the limits: the usage counted
+// against them adds up over every Run, Call, etc. (it is not reset for each one),
+// until SetLimits is called again, which resets it.
+//
+//		Otto := otto.New()
+//		Otto.SetLimits(otto.Limits{
+//			Nodes:     1000000,
+//			CallDepth: 512,
+//			Memory:    64 * 1024 * 1024,
+//		})
+//		_, err := Otto.Run(untrusted)
+//		if err == otto.ErrNodeLimit || err == otto.ErrMemoryLimit {
+//			...
+//		}
+type Limits struct {
+	// Nodes is the maximum number of nodes (roughly, operations) that will be evaluated,
+	// in total, since SetLimits. Execution is aborted with ErrNodeLimit when this is
+	// exceeded (and every Run, etc. after it is aborted, until SetLimits is called).
+	Nodes int64
+
+	// CallDepth is the maximum depth of nested function calls. A RangeError
+	// (which can be caught) is thrown when this is exceeded.
+	//
+	// A CallDepth of 0 is DefaultCallDepth. A negative CallDepth means there is no
+	// limit, so runaway recursion will overflow the (Go) stack, which crashes the
+	// whole process.
+	CallDepth int
+
+	// Memory is the approximate maximum number of bytes allocated for objects,
+	// properties, and strings, in total, since SetLimits. Execution is aborted with
+	// ErrMemoryLimit when this is exceeded (as for Nodes).
+	//
+	// This is a limit on what is allocated, not on what is in use: memory that is
+	// reclaimed by the garbage collector still counts toward the limit.
+	Memory int64
+}
+
+// DefaultCallDepth is the CallDepth of a runtime whose Limits do not set one, which
+// is well within what the (Go) stack can hold.
+const DefaultCallDepth = 10000
+
+var (
+	// ErrNodeLimit is returned when execution is aborted because Limits.Nodes was exceeded.
+	ErrNodeLimit = errors.New("otto: node limit exceeded")
+
+	// ErrMemoryLimit is returned when execution is aborted because Limits.Memory was exceeded.
+	ErrMemoryLimit = errors.New("otto: memory limit exceeded")
+)
+
+// The (approximate) size, in bytes, of an object, of a property (not including the
+// name), and of a value (in a list of values, not including a string)
+const (
+	objectSize   = 96
+	propertySize = 48
+	valueSize    = 24
+)
+
+// _usage is the usage (of the resources limited by Limits) since the limits were set
+type _usage struct {
+	nodes  int64
+	memory int64
+}
+
+// SetLimits sets the resource limits of the runtime, and resets the usage counted
+// against them (so the limits apply to what happens from this point on).
+func (self Otto) SetLimits(limits Limits) {
+	self.runtime.limits = limits
+	self.runtime.usage = _usage{}
+}
+
+// Limits returns the resource limits of the runtime.
+func (self Otto) Limits() Limits {
+	return self.runtime.limits
+}
+
+func (self *_runtime) countNode() {
+	self.usage.nodes += 1
+	if self.usage.nodes > self.limits.Nodes {
+		panic(&_interrupt{ErrNodeLimit})
+	}
+}
+
+func (self *_runtime) allocate(size int) {
+	if self.limits.Memory == 0 {
+		return
+	}
+	self.usage.memory += int64(size)
+	if self.usage.memory > self.limits.Memory {
+		panic(&_interrupt{ErrMemoryLimit})
+	}
+}
+
+func (self *_runtime) checkCallDepth() {
+	depth := self.limits.CallDepth
+	if depth == 0 {
+		depth = DefaultCallDepth
+	}
+	// The global execution context does not count toward the depth
+	if len(self.Stack) > depth {
+		panic(newRangeError("Maximum call stack size exceeded"))
+	}
+}
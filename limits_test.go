@@ -0,0 +1,162 @@
+package otto
+
+import (
+	. "./terst"
+	"testing"
+)
+
+func TestLimits_CallDepth(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetLimits(Limits{CallDepth: 100})
+	Is(Otto.Limits().CallDepth, 100)
+
+	_, err := Otto.Run(`
+        function abc() {
+            abc();
+        }
+        abc();
+    `)
+	Is(err, "RangeError: Maximum call stack size exceeded (line 3)")
+	Is(len(Otto.runtime.Stack), 1)
+
+	value, err := Otto.Run(`
+        var depth = 0;
+        function def() {
+            depth += 1;
+            def();
+        }
+        try {
+            def();
+        }
+        catch (err) {
+            result = [ err instanceof RangeError, depth ];
+        }
+        result;
+    `)
+	Is(err, nil)
+	Is(value, "true,100")
+
+	// The default
+	Otto = New()
+	value, err = Otto.Run(`
+        var depth = 0;
+        function ghi() {
+            depth += 1;
+            ghi();
+        }
+        try {
+            ghi();
+        }
+        catch (err) {
+            result = [ err instanceof RangeError, depth ];
+        }
+        result;
+    `)
+	Is(err, nil)
+	Is(value, "true,10000")
+
+	Otto.SetLimits(Limits{CallDepth: -1})
+	value, err = Otto.Run(`
+        function jkl(depth) {
+            return depth == 0 ? 0 : 1 + jkl(depth - 1);
+        }
+        jkl(20000);
+    `)
+	Is(err, nil)
+	Is(value, "20000")
+}
+
+func TestLimits_Nodes(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetLimits(Limits{Nodes: 10000})
+
+	_, err := Otto.Run(`
+        try {
+            while (true) {}
+        }
+        catch (err) {
+        }
+    `)
+	Is(err, ErrNodeLimit)
+	Is(len(Otto.runtime.Stack), 1)
+
+	// The usage adds up over each Run, until SetLimits resets it
+	Otto.SetLimits(Limits{Nodes: 1000})
+	for count := 0; count < 1000; count++ {
+		_, err = Otto.Run(`var abc = 1 + 2;`)
+		if err != nil {
+			break
+		}
+	}
+	Is(err, ErrNodeLimit)
+	Is(Otto.runtime.usage.nodes > 1000, true)
+	_, err = Otto.Run(`1`)
+	Is(err, ErrNodeLimit)
+
+	Otto.SetLimits(Limits{Nodes: 10000})
+	value, err := Otto.Run(`
+        var abc = 0;
+        for (var i = 0; i < 10; i++) {
+            abc += i;
+        }
+        abc;
+    `)
+	Is(err, nil)
+	Is(value, "45")
+}
+
+func TestLimits_Memory(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetLimits(Limits{Memory: 1024 * 1024})
+
+	_, err := Otto.Run(`
+        var abc = "x";
+        while (true) {
+            abc += abc;
+        }
+    `)
+	Is(err, ErrMemoryLimit)
+
+	Otto.SetLimits(Limits{Memory: 1024 * 1024})
+	_, err = Otto.Run(`
+        var abc = [];
+        while (true) {
+            abc.push({ def: 1 });
+        }
+    `)
+	Is(err, ErrMemoryLimit)
+
+	Otto.SetLimits(Limits{Memory: 1024 * 1024})
+	value, err := Otto.Run(`
+        var abc = [];
+        for (var i = 0; i < 100; i++) {
+            abc.push({ def: i });
+        }
+        abc.length;
+    `)
+	Is(err, nil)
+	Is(value, "100")
+
+	// Builtins that build a string (or an array) from their input
+	for _, source := range []string{
+		`new Array(20000001).join("x")`,
+		`var abc = new Array(100001).join("-"); new Array(100).join(abc)`,
+		`var abc = "x"; while (true) { abc = JSON.stringify([ abc, abc ]); }`,
+		`JSON.stringify(new Array(1000000))`,
+		`var abc = "xxxxxxxx"; while (true) { abc = abc.replace(/x/g, "xx"); }`,
+		`var abc = "x"; while (true) { abc = abc.replace("x", abc + abc); }`,
+		`var abc = "x"; while (true) { abc = abc.replace(/x/, function(){ return abc + abc; }); }`,
+		`var abc = new Array(10001).join("x"); abc.replace(/x/g, abc)`,
+		`var abc = new Array(100001).join("x"); abc.split("")`,
+	} {
+		Otto.SetLimits(Limits{Memory: 1024 * 1024})
+		_, err = Otto.Run(source)
+		Is(err, ErrMemoryLimit)
+	}
+}
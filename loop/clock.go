@@ -0,0 +1,116 @@
+package loop
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of a Loop, for scheduling timers.
+type Clock interface {
+	Now() time.Time
+	After(duration time.Duration) <-chan time.Time
+}
+
+// RealClock is the Clock of the system (time.Now and time.After).
+var RealClock Clock = _realClock{}
+
+type _realClock struct{}
+
+func (_realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (_realClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+// FakeClock is a Clock that only moves when it is advanced, for deterministic
+// tests of timers:
+//
+//		clock := loop.NewFakeClock(time.Unix(0, 0))
+//		Loop := loop.NewWithClock(otto.New(), clock)
+//		Loop.Run(func(vm *otto.Otto) error {
+//			_, err := vm.Run(`setTimeout(function(){ console.log("Nothing happens.") }, 1000)`)
+//			return err
+//		})
+//
+// Since nothing will advance the clock, the above will wait forever. Instead, use
+// Tick after advancing the clock:
+//
+//		clock.Advance(999 * time.Millisecond)
+//		Loop.Tick() // Nothing (yet)
+//		clock.Advance(1 * time.Millisecond)
+//		Loop.Tick() // Nothing happens.
+//
+// Or, use AutoAdvance, so that a Loop waiting on the clock moves it straight to
+// the next timer.
+type FakeClock struct {
+	mutex       sync.Mutex
+	now         time.Time
+	autoAdvance bool
+	waiterList  []_fakeWaiter
+}
+
+type _fakeWaiter struct {
+	deadline time.Time
+	channel  chan time.Time
+}
+
+// NewFakeClock returns a FakeClock beginning at the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now,
+	}
+}
+
+// AutoAdvance sets whether the clock moves itself, whenever something waits on
+// it (via After), to the end of the wait. With AutoAdvance, Run will go through
+// every timer without actually waiting.
+func (self *FakeClock) AutoAdvance(autoAdvance bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.autoAdvance = autoAdvance
+}
+
+func (self *FakeClock) Now() time.Time {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.now
+}
+
+func (self *FakeClock) After(duration time.Duration) <-chan time.Time {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	channel := make(chan time.Time, 1)
+	deadline := self.now.Add(duration)
+	if self.autoAdvance && duration > 0 {
+		self.advance(duration)
+	}
+	if !deadline.After(self.now) {
+		channel <- self.now
+		return channel
+	}
+	self.waiterList = append(self.waiterList, _fakeWaiter{deadline, channel})
+	return channel
+}
+
+// Advance moves the clock forward by the given duration, and wakes anything
+// waiting (via After) until then.
+func (self *FakeClock) Advance(duration time.Duration) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.advance(duration)
+}
+
+func (self *FakeClock) advance(duration time.Duration) {
+	self.now = self.now.Add(duration)
+	waiterList := self.waiterList[:0]
+	for _, waiter := range self.waiterList {
+		if waiter.deadline.After(self.now) {
+			waiterList = append(waiterList, waiter)
+			continue
+		}
+		waiter.channel <- self.now
+	}
+	self.waiterList = waiterList
+}
@@ -0,0 +1,337 @@
+/*
+Package loop is an event loop for otto, providing setTimeout, setInterval, and setImmediate.
+
+	import (
+		"github.com/robertkrimen/otto"
+		"github.com/robertkrimen/otto/loop"
+	)
+
+	Loop := loop.New(otto.New())
+	err := Loop.Run(func(vm *otto.Otto) error {
+		_, err := vm.Run(`
+			setTimeout(function(){
+				console.log("Nothing happens.");
+			}, 1000);
+		`)
+		return err
+	})
+
+Run returns when there is nothing more to do (no timer or Pending remains), or
+when a callback throws an (uncaught) exception, which is returned as the error.
+
+Every callback, and every function given to Schedule, is called on the goroutine
+of Run, one at a time. While the loop is running, the runtime should not be used
+from anywhere else; use Schedule (or a Pending) instead:
+
+	go func() {
+		result := slowComputation()
+		Loop.Schedule(func(vm *otto.Otto) error {
+			_, err := vm.Call("handleResult", nil, result)
+			return err
+		})
+	}()
+*/
+package loop
+
+import (
+	"container/heap"
+	"github.com/robertkrimen/otto"
+	"math"
+	"sync"
+	"time"
+)
+
+// Loop is an event loop, which owns its runtime (an *otto.Otto).
+type Loop struct {
+	vm    *otto.Otto
+	clock Clock
+
+	id            int64
+	timer         map[int64]*_timer // Every timer (and immediate) that has not been cleared
+	timerQueue    _timerQueue       // Every timer, by deadline
+	immediateList []*_timer
+
+	mutex   sync.Mutex
+	jobList []func(*otto.Otto) error
+	pending int // The number of Pending that are not yet resolved (or cancelled)
+	wake    chan struct{}
+}
+
+type _timer struct {
+	id           int64
+	deadline     time.Time
+	interval     time.Duration // If setInterval
+	repeat       bool
+	function     otto.Value
+	argumentList []interface{}
+}
+
+// New returns a Loop (using the system clock) for the given runtime, with
+// setTimeout, setInterval, setImmediate, clearTimeout, clearInterval, and
+// clearImmediate set in the runtime.
+func New(vm *otto.Otto) *Loop {
+	return NewWithClock(vm, RealClock)
+}
+
+// NewWithClock is like New, but with the given clock (e.g. a FakeClock) in place
+// of the system clock.
+func NewWithClock(vm *otto.Otto, clock Clock) *Loop {
+	self := &Loop{
+		vm:    vm,
+		clock: clock,
+		timer: map[int64]*_timer{},
+		wake:  make(chan struct{}, 1),
+	}
+	vm.Set("setTimeout", self.setTimer(false))
+	vm.Set("setInterval", self.setTimer(true))
+	vm.Set("setImmediate", self.setImmediate)
+	vm.Set("clearTimeout", self.clearTimer)
+	vm.Set("clearInterval", self.clearTimer)
+	vm.Set("clearImmediate", self.clearTimer)
+	return self
+}
+
+// VM returns the runtime of the loop.
+func (self *Loop) VM() *otto.Otto {
+	return self.vm
+}
+
+// Schedule adds the function to the loop, to be called (on the goroutine of Run)
+// as soon as possible, waking the loop if it is waiting on a timer. An error
+// returned by the function stops the loop, as an exception thrown by a timer
+// would.
+//
+// Schedule is safe to call from any goroutine. If the loop is not running, then
+// the function is called the next time it is (by Run or Tick).
+func (self *Loop) Schedule(function func(*otto.Otto) error) {
+	self.mutex.Lock()
+	self.jobList = append(self.jobList, function)
+	self.mutex.Unlock()
+	select {
+	case self.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run calls the given function (if not nil) with the runtime, then runs the loop
+// until there is nothing more to do: every timer has either fired or been
+// cleared, nothing is scheduled, and nothing is pending.
+//
+// The first error (from the function, a scheduled function, or an exception thrown
+// by a callback) stops the loop, and is returned. Any remaining timers are left in
+// place, and will fire if Run is called again.
+func (self *Loop) Run(function func(*otto.Otto) error) error {
+	if function != nil {
+		if err := function(self.vm); err != nil {
+			return err
+		}
+	}
+	for {
+		if err := self.Tick(); err != nil {
+			return err
+		}
+		scheduled, pending := self.status()
+		if scheduled {
+			continue
+		}
+		next := self.nextTimer()
+		if next == nil {
+			if !pending {
+				return nil
+			}
+			<-self.wake
+			continue
+		}
+		select {
+		case <-self.clock.After(next.deadline.Sub(self.clock.Now())):
+		case <-self.wake:
+		}
+	}
+}
+
+// Tick runs the loop once, without waiting: every scheduled function, then every
+// immediate, then every timer that is due (according to the clock).
+//
+// A timer (or immediate) added during the tick will not be run until the next
+// tick, so Tick always returns.
+func (self *Loop) Tick() error {
+	self.mutex.Lock()
+	jobList := self.jobList
+	self.jobList = nil
+	self.mutex.Unlock()
+	for index, job := range jobList {
+		if err := job(self.vm); err != nil {
+			self.mutex.Lock()
+			self.jobList = append(jobList[index+1:], self.jobList...)
+			self.mutex.Unlock()
+			return err
+		}
+	}
+
+	immediateList := self.immediateList
+	self.immediateList = nil
+	for index, immediate := range immediateList {
+		if self.timer[immediate.id] != immediate {
+			continue // Cleared
+		}
+		delete(self.timer, immediate.id)
+		if err := self.call(immediate); err != nil {
+			self.immediateList = append(immediateList[index+1:], self.immediateList...)
+			return err
+		}
+	}
+
+	now := self.clock.Now()
+	last := self.id // Any timer after this was added during the tick
+	laterList := []*_timer{}
+	defer func() {
+		for _, timer := range laterList {
+			heap.Push(&self.timerQueue, timer)
+		}
+	}()
+	for len(self.timerQueue) > 0 && !self.timerQueue[0].deadline.After(now) {
+		timer := heap.Pop(&self.timerQueue).(*_timer)
+		if self.timer[timer.id] != timer {
+			continue // Cleared
+		}
+		if timer.id > last {
+			laterList = append(laterList, timer)
+			continue
+		}
+		if !timer.repeat {
+			delete(self.timer, timer.id)
+		}
+		if err := self.call(timer); err != nil {
+			delete(self.timer, timer.id)
+			return err
+		}
+		if timer.repeat && self.timer[timer.id] == timer {
+			timer.deadline = self.clock.Now().Add(timer.interval)
+			laterList = append(laterList, timer)
+		}
+	}
+	return nil
+}
+
+// status reports whether anything is scheduled (a function or an immediate), and
+// whether anything is pending, together (so a Pending that is resolved in between
+// cannot be missed).
+func (self *Loop) status() (scheduled bool, pending bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return len(self.jobList) > 0 || len(self.immediateList) > 0, self.pending > 0
+}
+
+// nextTimer returns the timer with the earliest deadline (or nil, if there is none).
+func (self *Loop) nextTimer() *_timer {
+	for len(self.timerQueue) > 0 {
+		timer := self.timerQueue[0]
+		if self.timer[timer.id] == timer {
+			return timer
+		}
+		heap.Pop(&self.timerQueue) // Cleared
+	}
+	return nil
+}
+
+func (self *Loop) call(timer *_timer) error {
+	_, err := timer.function.Call(otto.UndefinedValue(), timer.argumentList...)
+	return err
+}
+
+func (self *Loop) newTimer(call otto.FunctionCall, name string, skip int) *_timer {
+	function := call.Argument(0)
+	if !function.IsFunction() {
+		throwTypeError(call.Otto, "The callback of "+name+" must be a function")
+	}
+	argumentList := []interface{}{}
+	if len(call.ArgumentList) > skip {
+		for _, argument := range call.ArgumentList[skip:] {
+			argumentList = append(argumentList, argument)
+		}
+	}
+	self.id += 1
+	timer := &_timer{
+		id:           self.id,
+		function:     function,
+		argumentList: argumentList,
+	}
+	self.timer[timer.id] = timer
+	return timer
+}
+
+// setTimer returns setTimeout (or setInterval, if repeat is true)
+func (self *Loop) setTimer(repeat bool) func(otto.FunctionCall) otto.Value {
+	name := "setTimeout"
+	if repeat {
+		name = "setInterval"
+	}
+	return func(call otto.FunctionCall) otto.Value {
+		timer := self.newTimer(call, name, 2)
+		delay, _ := call.Argument(1).ToFloat()
+		if math.IsNaN(delay) || delay < 0 {
+			delay = 0
+		}
+		timer.interval = time.Duration(delay * float64(time.Millisecond))
+		if repeat {
+			timer.repeat = true
+			if timer.interval < time.Millisecond {
+				timer.interval = time.Millisecond
+			}
+		}
+		timer.deadline = self.clock.Now().Add(timer.interval)
+		heap.Push(&self.timerQueue, timer)
+		value, _ := call.Otto.ToValue(timer.id)
+		return value
+	}
+}
+
+func (self *Loop) setImmediate(call otto.FunctionCall) otto.Value {
+	timer := self.newTimer(call, "setImmediate", 1)
+	self.immediateList = append(self.immediateList, timer)
+	value, _ := call.Otto.ToValue(timer.id)
+	return value
+}
+
+// clearTimer is clearTimeout, clearInterval, and clearImmediate
+func (self *Loop) clearTimer(call otto.FunctionCall) otto.Value {
+	id, err := call.Argument(0).ToInteger()
+	if err == nil {
+		delete(self.timer, id)
+	}
+	return otto.UndefinedValue()
+}
+
+func throwTypeError(vm *otto.Otto, message string) {
+	value, _ := vm.Call("new TypeError", nil, message)
+	panic(value)
+}
+
+// _timerQueue is a heap of timers, ordered by deadline (and then by id)
+type _timerQueue []*_timer
+
+func (self _timerQueue) Len() int {
+	return len(self)
+}
+
+func (self _timerQueue) Less(i, j int) bool {
+	if self[i].deadline.Equal(self[j].deadline) {
+		return self[i].id < self[j].id
+	}
+	return self[i].deadline.Before(self[j].deadline)
+}
+
+func (self _timerQueue) Swap(i, j int) {
+	self[i], self[j] = self[j], self[i]
+}
+
+func (self *_timerQueue) Push(value interface{}) {
+	*self = append(*self, value.(*_timer))
+}
+
+func (self *_timerQueue) Pop() interface{} {
+	queue := *self
+	timer := queue[len(queue)-1]
+	*self = queue[:len(queue)-1]
+	return timer
+}
@@ -0,0 +1,153 @@
+package loop
+
+import (
+	. "../terst"
+	"github.com/robertkrimen/otto"
+	"testing"
+	"time"
+)
+
+func newFakeLoop() (*Loop, *FakeClock) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	Loop := NewWithClock(otto.New(), clock)
+	Loop.VM().Run(`
+        var result = [];
+        function record() {
+            result.push(Array.prototype.slice.call(arguments).join(" "));
+        }
+    `)
+	return Loop, clock
+}
+
+func result(Loop *Loop) string {
+	value, _ := Loop.VM().Run(`result.join(", ")`)
+	return value.String()
+}
+
+func TestLoop_Tick(t *testing.T) {
+	Terst(t)
+
+	Loop, clock := newFakeLoop()
+	_, err := Loop.VM().Run(`
+        setTimeout(record, 100, "abc", 1);
+        setTimeout(record, 50, "def");
+        var ghi = setTimeout(record, 50, "ghi");
+        setImmediate(record, "jkl");
+        setTimeout(record, 0, "mno");
+        clearTimeout(ghi);
+    `)
+	Is(err, nil)
+
+	Is(Loop.Tick(), nil)
+	Is(result(Loop), "jkl, mno")
+
+	clock.Advance(49 * time.Millisecond)
+	Is(Loop.Tick(), nil)
+	Is(result(Loop), "jkl, mno")
+
+	clock.Advance(1 * time.Millisecond)
+	Is(Loop.Tick(), nil)
+	Is(result(Loop), "jkl, mno, def")
+
+	clock.Advance(1 * time.Second)
+	Is(Loop.Tick(), nil)
+	Is(result(Loop), "jkl, mno, def, abc 1")
+}
+
+func TestLoop_interval(t *testing.T) {
+	Terst(t)
+
+	Loop, clock := newFakeLoop()
+	_, err := Loop.VM().Run(`
+        var count = 0;
+        var abc = setInterval(function(){
+            count += 1;
+            record(count);
+            if (count === 3) {
+                clearInterval(abc);
+            }
+        }, 10);
+        // A timer added by a callback does not run in the same tick
+        setTimeout(function(){
+            setTimeout(record, 0, "def");
+        }, 0);
+    `)
+	Is(err, nil)
+
+	Is(Loop.Tick(), nil)
+	Is(result(Loop), "")
+	Is(Loop.Tick(), nil)
+	Is(result(Loop), "def")
+
+	for index := 0; index < 5; index++ {
+		clock.Advance(10 * time.Millisecond)
+		Is(Loop.Tick(), nil)
+	}
+	Is(result(Loop), "def, 1, 2, 3")
+}
+
+func TestLoop_Run(t *testing.T) {
+	Terst(t)
+
+	Loop, clock := newFakeLoop()
+	clock.AutoAdvance(true)
+	err := Loop.Run(func(vm *otto.Otto) error {
+		_, err := vm.Run(`
+            setTimeout(function(){
+                record("abc");
+                setTimeout(record, 1000, "def");
+                setImmediate(record, "ghi");
+            }, 5000);
+            setTimeout(record, 10, "jkl");
+        `)
+		return err
+	})
+	Is(err, nil)
+	Is(result(Loop), "jkl, abc, ghi, def")
+	Is(clock.Now(), time.Unix(6, 0))
+
+	// An exception stops the loop
+	err = Loop.Run(func(vm *otto.Otto) error {
+		_, err := vm.Run(`
+            setTimeout(function(){
+                throw new Error("Nothing happens.");
+            }, 10);
+            setTimeout(record, 20, "mno");
+        `)
+		return err
+	})
+	Is(err, "Error: Nothing happens.")
+	Is(Loop.Run(nil), nil)
+	Is(result(Loop), "jkl, abc, ghi, def, mno")
+
+	_, err = Loop.VM().Run(`setTimeout("record()", 10)`)
+	Is(err, "TypeError: The callback of setTimeout must be a function")
+}
+
+func TestLoop_Schedule(t *testing.T) {
+	Terst(t)
+
+	Loop := New(otto.New())
+	Loop.VM().Run(`
+        var result = [];
+        setTimeout(function(){ result.push("abc") }, 60 * 1000); // Cleared by the schedule
+    `)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		Loop.Schedule(func(vm *otto.Otto) error {
+			_, err := vm.Run(`
+                result.push("def");
+                clearTimeout(1);
+            `)
+			return err
+		})
+	}()
+
+	start := time.Now()
+	err := Loop.Run(nil)
+	Is(err, nil)
+	Is(time.Since(start) < 30*time.Second, true)
+	value, _ := Loop.VM().Run(`result.join(", ")`)
+	Is(value, "def")
+}
@@ -0,0 +1,94 @@
+package loop
+
+import (
+	"github.com/robertkrimen/otto"
+)
+
+// Pending is a handle to work being done outside of the loop (on another
+// goroutine), which will later continue on the loop. A native function can use it
+// to do something slow (like I/O) without blocking the runtime:
+//
+//		vm.Set("readFile", func(call otto.FunctionCall) otto.Value {
+//			filename, callback := call.Argument(0).String(), call.Argument(1)
+//			pending := Loop.Pending()
+//			go func() {
+//				data, err := ioutil.ReadFile(filename)
+//				if err != nil {
+//					pending.Call(callback, err.Error())
+//					return
+//				}
+//				pending.Call(callback, otto.NullValue(), string(data))
+//			}()
+//			return otto.UndefinedValue()
+//		})
+//
+// Run will not return while a Pending is outstanding (neither resolved nor
+// cancelled), even if there are no timers.
+//
+// The methods of Pending are safe to call from any goroutine, but only the first
+// call (of Resolve, Call, or Cancel) has any effect.
+type Pending struct {
+	loop *Loop
+	done bool
+}
+
+// Pending returns a new (outstanding) Pending of the loop.
+func (self *Loop) Pending() *Pending {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.pending += 1
+	return &Pending{
+		loop: self,
+	}
+}
+
+// Resolve schedules the function (the continuation) to be called on the loop,
+// as Schedule does.
+func (self *Pending) Resolve(function func(*otto.Otto) error) {
+	self.finish(function)
+}
+
+// Call schedules a call of the function (e.g. a callback given to the native
+// function) with the arguments. The arguments are converted (with ToValue) on
+// the loop, not the calling goroutine.
+//
+// An exception thrown by the function stops the loop, as one thrown by a timer
+// would.
+func (self *Pending) Call(function otto.Value, argumentList ...interface{}) {
+	self.finish(func(vm *otto.Otto) error {
+		valueList := make([]interface{}, len(argumentList))
+		for index, argument := range argumentList {
+			value, err := vm.ToValue(argument)
+			if err != nil {
+				return err
+			}
+			valueList[index] = value
+		}
+		_, err := function.Call(otto.UndefinedValue(), valueList...)
+		return err
+	})
+}
+
+// Cancel abandons the pending work, without calling anything on the loop.
+func (self *Pending) Cancel() {
+	self.finish(nil)
+}
+
+func (self *Pending) finish(function func(*otto.Otto) error) {
+	loop := self.loop
+	loop.mutex.Lock()
+	if self.done {
+		loop.mutex.Unlock()
+		return
+	}
+	self.done = true
+	loop.pending -= 1
+	if function != nil {
+		loop.jobList = append(loop.jobList, function)
+	}
+	loop.mutex.Unlock()
+	select {
+	case loop.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,114 @@
+package loop
+
+import (
+	. "../terst"
+	"errors"
+	"github.com/robertkrimen/otto"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPending(t *testing.T) {
+	Terst(t)
+
+	Loop := New(otto.New())
+	Loop.VM().Set("fetch", func(call otto.FunctionCall) otto.Value {
+		name, callback := call.Argument(0).String(), call.Argument(1)
+		pending := Loop.Pending()
+		go func() {
+			time.Sleep(10 * time.Millisecond) // Something slow
+			if name == "" {
+				pending.Call(callback, "Nothing happens.")
+				return
+			}
+			pending.Call(callback, otto.NullValue(), map[string]interface{}{"name": name})
+		}()
+		return otto.UndefinedValue()
+	})
+
+	err := Loop.Run(func(vm *otto.Otto) error {
+		_, err := vm.Run(`
+            var result = [];
+            fetch("abc", function(err, value) {
+                result.push(value.name);
+                fetch("", function(err) {
+                    result.push(err);
+                });
+            });
+            fetch("def", function(err, value) {
+                result.push(err + " " + value.name);
+            });
+            result.push("ghi");
+        `)
+		return err
+	})
+	Is(err, nil)
+	// The order of abc and def depends on which goroutine finishes first
+	value, _ := Loop.VM().Run(`result[0] + "; " + result.slice(1).sort().join(", ")`)
+	Is(value, "ghi; Nothing happens., abc, null def")
+}
+
+func TestPending_ResolveCancel(t *testing.T) {
+	Terst(t)
+
+	Loop := New(otto.New())
+	Loop.VM().Run(`var result = [];`)
+
+	pending := Loop.Pending()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pending.Resolve(func(vm *otto.Otto) error {
+			_, err := vm.Run(`result.push("abc")`)
+			return err
+		})
+		pending.Resolve(func(vm *otto.Otto) error {
+			_, err := vm.Run(`result.push("def")`) // Too late
+			return err
+		})
+	}()
+	Is(Loop.Run(nil), nil)
+	value, _ := Loop.VM().Run(`result.join(", ")`)
+	Is(value, "abc")
+
+	pending = Loop.Pending()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pending.Cancel()
+	}()
+	Is(Loop.Run(nil), nil)
+	value, _ = Loop.VM().Run(`result.join(", ")`)
+	Is(value, "abc")
+
+	pending = Loop.Pending()
+	go pending.Resolve(func(vm *otto.Otto) error {
+		return errors.New("Nothing happens.")
+	})
+	Is(Loop.Run(nil), "Nothing happens.")
+}
+
+func TestPending_race(t *testing.T) {
+	Terst(t)
+
+	// Resolve from other goroutines while Run is deciding whether to return, which
+	// must not return until every continuation has been called
+	Loop := New(otto.New())
+	count := 0
+	for round := 1; round <= 20000; round++ {
+		pending := Loop.Pending()
+		go func(spin int) {
+			for index := 0; index < spin; index++ {
+				runtime.Gosched()
+			}
+			pending.Resolve(func(vm *otto.Otto) error {
+				count += 1
+				return nil
+			})
+		}(round % 4)
+		Is(Loop.Run(nil), nil)
+		if count != round {
+			Is(count, round)
+			break
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package otto
+
+import (
+	"errors"
+	"github.com/robertkrimen/otto/registry"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrModuleNotFound is returned by a ModuleLoader that does not have a module at
+// the given path.
+var ErrModuleNotFound = errors.New("otto: module not found")
+
+// ModuleLoader is the source of the modules of require.
+//
+// Load is given a clean, slash-separated path without a leading slash (e.g.
+// "lib/abc.js"), and returns the source of the module at that path, or
+// ErrModuleNotFound. Any other error is thrown (as an Error) by require.
+//
+// Resolving the name given to require is done by the runtime, not the loader:
+// a name beginning with "./" or "../" is relative to the module that is calling
+// require, while any other name is relative to the root of the loader. For each
+// name, the paths tried are, in order:
+//
+//		name
+//		name.js
+//		name.json
+//		name/index.js
+//
+// A module ending in .json is parsed as JSON, and becomes the exports of the module.
+type ModuleLoader interface {
+	Load(path string) (string, error)
+}
+
+// NewMapLoader returns a ModuleLoader of the modules in the map, keyed by path.
+//
+//		Otto.SetModuleLoader(otto.NewMapLoader(map[string]string{
+//			"abc.js":      `exports.def = require("./lib/ghi").jkl;`,
+//			"lib/ghi.js":  `exports.jkl = "Nothing happens.";`,
+//		}))
+func NewMapLoader(source map[string]string) ModuleLoader {
+	return _mapLoader(source)
+}
+
+type _mapLoader map[string]string
+
+func (self _mapLoader) Load(path string) (string, error) {
+	source, exists := self[path]
+	if !exists {
+		return "", ErrModuleNotFound
+	}
+	return source, nil
+}
+
+// NewFSLoader returns a ModuleLoader of the files in the file system, which
+// could be (for example) embedded in the program:
+//
+//		//go:embed lib
+//		var lib embed.FS
+//
+//		Otto.SetModuleLoader(otto.NewFSLoader(lib))
+func NewFSLoader(fsys fs.FS) ModuleLoader {
+	return &_fsLoader{fsys}
+}
+
+// NewFileLoader returns a ModuleLoader of the files in the directory root (and
+// below). A module cannot be loaded from outside of root.
+func NewFileLoader(root string) ModuleLoader {
+	return &_fsLoader{os.DirFS(root)}
+}
+
+type _fsLoader struct {
+	fsys fs.FS
+}
+
+func (self *_fsLoader) Load(path string) (string, error) {
+	info, err := fs.Stat(self.fsys, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+			return "", ErrModuleNotFound
+		}
+		return "", err
+	}
+	if info.IsDir() {
+		return "", ErrModuleNotFound
+	}
+	source, err := fs.ReadFile(self.fsys, path)
+	if err != nil {
+		return "", err
+	}
+	return string(source), nil
+}
+
+// SetModuleLoader sets the loader of the modules of require, and empties the cache
+// of modules already loaded.
+//
+//		Otto.SetModuleLoader(otto.NewFileLoader("./js"))
+//		Otto.Run(`
+//			var abc = require("abc"); // ./js/abc.js
+//		`)
+//
+// Each module is run in its own function scope, with exports, require, module,
+// __filename, and __dirname, as in Node.js. A module is only run once, and any
+// further require of the same module results in the same exports. For a cycle
+// (a requires b, which requires a), the exports of the (unfinished) module is
+// returned.
+//
+// A native (Go) module, registered with registry.RegisterNative, can be
+// required by name whether or not there is a loader.
+//
+// The global require is set by SetModuleLoader (unless there already is one), or
+// by New, if there is a native module.
+func (self Otto) SetModuleLoader(loader ModuleLoader) {
+	modules := self.runtime.moduleSystem()
+	modules.loader = loader
+	modules.cache = map[string]*_object{}
+	self.runtime.enableRequire()
+}
+
+// _moduleSystem is the loader and cache of the modules of require
+type _moduleSystem struct {
+	loader ModuleLoader        // nil if there is no loader
+	cache  map[string]*_object // The module object of each path
+	native map[string]*_object // The exports of each native module
+}
+
+func newModuleSystem(loader ModuleLoader) *_moduleSystem {
+	return &_moduleSystem{
+		loader: loader,
+		cache:  map[string]*_object{},
+		native: map[string]*_object{},
+	}
+}
+
+func (self *_runtime) moduleSystem() *_moduleSystem {
+	if self.modules == nil {
+		self.modules = newModuleSystem(nil)
+	}
+	return self.modules
+}
+
+// The parameters of the function of a module
+var moduleParameterList = []string{"exports", "require", "module", "__filename", "__dirname"}
+
+// enableRequire sets the global require, unless there already is one.
+func (self *_runtime) enableRequire() {
+	if !self.GlobalObject.hasOwnProperty("require") {
+		self.GlobalObject.put("require", toValue_object(self.newRequire("")), false)
+	}
+}
+
+// newRequire returns the require function of the module at the path parent (or
+// of the global scope, if parent is "").
+func (runtime *_runtime) newRequire(parent string) *_object {
+	return runtime.newNativeFunction(func(call FunctionCall) Value {
+		name := toString(call.Argument(0))
+		if name == "" {
+			panic(newTypeError("The name of a module must be a non-empty string"))
+		}
+		return call.runtime.require(name, parent)
+	})
+}
+
+func (self *_runtime) require(name, parent string) Value {
+	modules := self.moduleSystem()
+
+	base := name
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		base = path.Join(path.Dir(parent), name)
+	} else if loader := registry.Native(name); loader != nil {
+		return self.loadNativeModule(name, loader)
+	}
+	base = strings.TrimPrefix(path.Clean(base), "/")
+
+	if modules.loader == nil {
+		panic(newError("Error", "Cannot find module '%s'", name))
+	}
+	for _, filename := range []string{base, base + ".js", base + ".json", base + "/index.js"} {
+		if module, exists := modules.cache[filename]; exists {
+			return module.get("exports")
+		}
+		source, err := modules.loader.Load(filename)
+		if err == ErrModuleNotFound {
+			continue
+		}
+		if err != nil {
+			panic(newError("Error", "Cannot load module '%s': %s", name, err.Error()))
+		}
+		return self.loadModule(filename, source)
+	}
+	panic(newError("Error", "Cannot find module '%s'", name))
+}
+
+func (self *_runtime) loadModule(filename, source string) Value {
+	module := self.newObject()
+	exports := self.newObject()
+	module.put("id", toValue_string(filename), false)
+	module.put("exports", toValue_object(exports), false)
+	module.put("loaded", FalseValue(), false)
+
+	self.modules.cache[filename] = module
+	loaded := false
+	defer func() {
+		if !loaded {
+			delete(self.modules.cache, filename) // So that the module can be tried again
+		}
+	}()
+
+	if path.Ext(filename) == ".json" {
+		value := builtinJSON_parse(FunctionCall{
+			runtime:      self,
+			ArgumentList: []Value{toValue_string(source)},
+		})
+		module.put("exports", value, false)
+	} else {
+		function := self.compileModule(filename, source)
+		function.call(toValue_object(exports),
+			exports,
+			self.newRequire(filename),
+			module,
+			filename,
+			path.Dir(filename),
+		)
+	}
+
+	loaded = true
+	module.put("loaded", TrueValue(), false)
+	return module.get("exports")
+}
+
+func (self *_runtime) loadNativeModule(name string, loader interface{}) Value {
+	if exports, exists := self.modules.native[name]; exists {
+		return toValue_object(exports)
+	}
+	load, valid := loader.(func(*Otto) *Object)
+	if !valid {
+		panic(newError("Error", "Cannot load module '%s': %T is not a func(*otto.Otto) *otto.Object", name, loader))
+	}
+	exports := load(self.Otto)
+	if exports == nil {
+		panic(newError("Error", "Cannot load module '%s': no exports", name))
+	}
+	self.modules.native[name] = exports.object
+	return exports.value
+}
+
+// compileModule returns the module as a function (of the moduleParameterList),
+// made in the global scope (not the scope of the caller of require).
+func (self *_runtime) compileModule(filename, source string) Value {
+	node, coverage := parseModule(filename, moduleParameterList, source, self.coverage != nil)
+	if coverage != nil {
+		self.coverage.register(filename, coverage)
+	}
+	return toValue_object(self.newNodeFunction(node, self.GlobalEnvironment))
+}
@@ -0,0 +1,229 @@
+package otto
+
+import (
+	. "./terst"
+	"errors"
+	"github.com/robertkrimen/otto/registry"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRequire(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetModuleLoader(NewMapLoader(map[string]string{
+		"abc.js": `
+            var def = require("./lib/def");
+            exports.xyzzy = "Nothing happens.";
+            exports.def = def;
+            exports.filename = __filename;
+        `,
+		"lib/def.js": `
+            var jkl = require("../jkl.json");
+            module.exports = function() {
+                return jkl.mno + " " + __dirname;
+            };
+        `,
+		"jkl.json": `{ "mno": 11 }`,
+		"ghi/index.js": `
+            var count = (this.count || 0) + 1;
+            exports.count = count;
+            exports.self = this === exports;
+        `,
+	}))
+
+	value, err := Otto.Run(`
+        var abc = require("abc");
+        [ abc.xyzzy, abc.def(), abc.filename ].join(", ");
+    `)
+	Is(err, nil)
+	Is(value, "Nothing happens., 11 lib, abc.js")
+
+	// Each module has its own scope
+	value, err = Otto.Run(`typeof def + " " + typeof jkl + " " + typeof exports + " " + typeof module`)
+	Is(err, nil)
+	Is(value, "undefined undefined undefined undefined")
+
+	// A module is only run once
+	value, err = Otto.Run(`
+        var ghi = require("./ghi");
+        [ ghi.count, ghi.self, ghi === require("ghi/index"), require("abc") === abc ].join(", ");
+    `)
+	Is(err, nil)
+	Is(value, "1, true, true, true")
+
+	_, err = Otto.Run(`require("nothing")`)
+	Is(err, "Error: Cannot find module 'nothing' (line 1)")
+
+	_, err = Otto.Run(`require("../abc")`)
+	Is(err, "Error: Cannot find module '../abc' (line 1)")
+
+	// Without a loader (or a native module), there is no require
+	_, err = New().Run(`require("abc")`)
+	Is(err, "ReferenceError: require is not defined (line 1)")
+}
+
+func TestRequire_cycle(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetModuleLoader(NewMapLoader(map[string]string{
+		"abc.js": `
+            exports.loaded = false;
+            var def = require("./def");
+            exports.def = def.loaded + " " + def.abc;
+            exports.loaded = true;
+        `,
+		"def.js": `
+            exports.loaded = false;
+            var abc = require("./abc");
+            exports.abc = abc.loaded;
+            exports.loaded = true;
+        `,
+	}))
+
+	value, err := Otto.Run(`require("abc").def`)
+	Is(err, nil)
+	Is(value, "true false")
+}
+
+func TestRequire_error(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.SetModuleLoader(NewMapLoader(map[string]string{
+		"abc.js": `
+            count = (typeof count === "undefined" ? 0 : count) + 1;
+            if (count === 1) {
+                throw new Error("Nothing happens.");
+            }
+            exports.count = count;
+        `,
+		"def.js": "var def = {;",
+		"ghi.js": "exports.ghi = xyzzy;",
+	}))
+
+	_, err := Otto.Run(`require("abc")`)
+	Is(err, "Error: Nothing happens.")
+	{
+		err := err.(*Error)
+		Is(len(err.Stack) > 0, true)
+		Is(err.Stack[0].Filename, "abc.js")
+		Is(err.Stack[0].Line, 4)
+	}
+
+	// A module that failed is not cached, so it can be tried again
+	value, err := Otto.Run(`require("abc").count`)
+	Is(err, nil)
+	Is(value, "2")
+
+	value, err = Otto.Run(`
+        try {
+            require("def");
+        }
+        catch (err) {
+            err.name;
+        }
+    `)
+	Is(err, nil)
+	Is(value, "SyntaxError")
+
+	// The columns of the first line are those of the module
+	_, err = Otto.Run(`require("def")`)
+	Is(err, "SyntaxError: Unexpected token ; (def.js: line 1)")
+	_, runErr := Otto.RunFile("def.js", "var def = {;")
+	Is(err.(*Error).Column, runErr.(*Error).Column)
+
+	_, err = Otto.Run(`require("ghi")`)
+	Is(err, "ReferenceError: xyzzy is not defined (ghi.js: line 1)")
+	Is(err.(*Error).Column, 15)
+}
+
+func TestRequire_loader(t *testing.T) {
+	Terst(t)
+
+	directory, err := ioutil.TempDir("", "otto-module")
+	Is(err, nil)
+	defer os.RemoveAll(directory)
+	Is(os.MkdirAll(filepath.Join(directory, "lib", "def"), 0755), nil)
+	Is(ioutil.WriteFile(filepath.Join(directory, "abc.js"), []byte(`module.exports = require("./lib/def").ghi;`), 0644), nil)
+	Is(ioutil.WriteFile(filepath.Join(directory, "lib", "def", "index.js"), []byte(`exports.ghi = "Nothing happens.";`), 0644), nil)
+
+	Otto := New()
+	Otto.SetModuleLoader(NewFileLoader(directory))
+	value, err := Otto.Run(`require("abc")`)
+	Is(err, nil)
+	Is(value, "Nothing happens.")
+
+	_, err = Otto.Run(`require("lib")`)
+	Is(err, "Error: Cannot find module 'lib' (line 1)")
+
+	Otto = New()
+	Otto.SetModuleLoader(NewFSLoader(fstest.MapFS{
+		"abc.js": &fstest.MapFile{Data: []byte(`exports.def = 1 + 1;`)},
+	}))
+	value, err = Otto.Run(`require("./abc").def`)
+	Is(err, nil)
+	Is(value, "2")
+
+	Otto.SetModuleLoader(_errorLoader{})
+	_, err = Otto.Run(`require("abc")`)
+	Is(err, "Error: Cannot load module 'abc': Nothing happens. (line 1)")
+}
+
+type _errorLoader struct{}
+
+func (_errorLoader) Load(path string) (string, error) {
+	return "", errors.New("Nothing happens.")
+}
+
+func TestRequire_native(t *testing.T) {
+	Terst(t)
+
+	count := 0
+	entry := registry.RegisterNative("otto-test-xyzzy", func(Otto *Otto) *Object {
+		count += 1
+		object, _ := Otto.Object(`({ abc: "Nothing happens." })`)
+		object.Set("def", func(call FunctionCall) Value {
+			value, _ := Otto.ToValue(call.Argument(0).String() + "!")
+			return value
+		})
+		return object
+	})
+	defer entry.Disable()
+	defer registry.RegisterNative("otto-test-invalid", func() {}).Disable()
+
+	Otto := New()
+	value, err := Otto.Run(`
+        var xyzzy = require("otto-test-xyzzy");
+        xyzzy.def(xyzzy.abc) + " " + (xyzzy === require("otto-test-xyzzy"));
+    `)
+	Is(err, nil)
+	Is(value, "Nothing happens.! true")
+	Is(count, 1) // Loaded lazily, and only once
+
+	// A native module takes precedence over the loader, but not a relative path
+	Otto.SetModuleLoader(NewMapLoader(map[string]string{
+		"otto-test-xyzzy.js": `exports.abc = "Something happens.";`,
+	}))
+	value, err = Otto.Run(`require("otto-test-xyzzy").abc + " " + require("./otto-test-xyzzy").abc`)
+	Is(err, nil)
+	Is(value, "Nothing happens. Something happens.")
+	Is(count, 1)
+
+	// Each runtime has its own exports
+	_, err = New().Run(`require("otto-test-xyzzy")`)
+	Is(err, nil)
+	Is(count, 2)
+
+	_, err = Otto.Run(`require("otto-test-invalid")`)
+	Is(err, "Error: Cannot load module 'otto-test-invalid': func() is not a func(*otto.Otto) *otto.Object (line 1)")
+
+	entry.Disable()
+	_, err = New().Run(`require("otto-test-xyzzy")`)
+	Is(err, "Error: Cannot find module 'otto-test-xyzzy' (line 1)")
+}
@@ -10,8 +10,10 @@ import (
 type _node interface {
 	Type() _nodeType
 	String() string
-	setPosition(int)
-	position() int
+	setPosition(_position)
+	position() _position
+	setStatement()
+	isStatement() bool
 }
 
 type _nodeType int
@@ -20,17 +22,34 @@ func (self _nodeType) Type() _nodeType {
 	return self
 }
 
+// _position is a location in the source, where Line and Column are 1-based
+// (a Line of 0 means the position is unknown)
+type _position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
 type _node_ struct {
 	_nodeType
-	Line int // Line in the source
+	_position      // Where the node begins in the source
+	statement bool // Whether the node is a statement (where the debugger can stop)
+}
+
+func (self *_node_) setPosition(position _position) {
+	self._position = position
 }
 
-func (self *_node_) setPosition(Line int) {
-	self.Line = Line
+func (self *_node_) position() _position {
+	return self._position
 }
 
-func (self *_node_) position() int {
-	return self.Line
+func (self *_node_) setStatement() {
+	self.statement = true
+}
+
+func (self *_node_) isStatement() bool {
+	return self.statement
 }
 
 const (
@@ -78,6 +97,8 @@ const (
 	nodeValue
 	nodeThis
 	nodeComma
+
+	nodeDebugger
 )
 
 // _labelSet
@@ -53,6 +53,7 @@ type _binaryOperationNode struct {
 	Operator string
 	Left     _node
 	Right    _node
+	block    int // For coverage (see _converter.branch)
 }
 
 func newBinaryOperationNode(operator string, left _node, right _node) *_binaryOperationNode {
@@ -131,6 +132,7 @@ type _conditionalNode struct {
 	Test       _node
 	Consequent _node
 	Alternate  _node
+	block      int // For coverage (see _converter.branch)
 }
 
 func newConditionalNode(test _node, consequent _node, alternate _node) *_conditionalNode {
@@ -149,11 +151,13 @@ type _functionNode struct {
 	_nodeType
 	_node_
 	_declaration         bool
+	Name                 string // The name of the function, or "" if anonymous
 	ParameterList        []string
 	Body                 []_node
 	VariableList         []_declaration
 	FunctionList         []_declaration
 	ArgumentsIsParameter bool // A hint that "arguments" exists as a parameter
+	strict               bool
 }
 
 func newFunctionNode() *_functionNode {
@@ -271,6 +275,7 @@ type _objectPropertyNode struct {
 	_nodeType
 	_node_
 	Key   string
+	Kind  string // "value", or "get" or "set" (for an accessor)
 	Value _node
 }
 
@@ -278,6 +283,7 @@ func newObjectPropertyNode(key string, value _node) *_objectPropertyNode {
 	return &_objectPropertyNode{
 		_nodeType: nodeObjectProperty,
 		Key:       key,
+		Kind:      "value",
 		Value:     value,
 	}
 }
@@ -59,6 +59,21 @@ func (self _continueNode) String() string {
 	return fmt.Sprintf("<continue:%s>", self.Target)
 }
 
+type _debuggerNode struct {
+	_nodeType
+	_node_
+}
+
+func newDebuggerNode() *_debuggerNode {
+	return &_debuggerNode{
+		_nodeType: nodeDebugger,
+	}
+}
+
+func (self _debuggerNode) String() string {
+	return "debugger"
+}
+
 type _emptyNode struct {
 	_nodeType
 	_node_
@@ -204,6 +219,7 @@ type _ifNode struct {
 	Test       _node
 	Consequent _node
 	Alternate  _node
+	block      int // For coverage (see _converter.branch)
 }
 
 func newIfNode(test _node, consequent _node) *_ifNode {
@@ -235,6 +251,8 @@ type _programNode struct {
 	Body         []_node
 	VariableList []_declaration
 	FunctionList []_declaration
+	strict       bool
+	coverage     *_coverageProgram // nil if the program has no filename
 }
 
 func newProgramNode() *_programNode {
@@ -247,15 +265,6 @@ func (self _programNode) String() string {
 	return fmtNodeString("{ @ %s }", self.Body)
 }
 
-func (self _programNode) toFunction(parameterList []string) *_functionNode {
-	node := newFunctionNode()
-	node.ParameterList = parameterList
-	node.Body = self.Body
-	node.VariableList = self.VariableList
-	node.FunctionList = self.FunctionList
-	return node
-}
-
 type _returnNode struct {
 	_nodeType
 	_node_
@@ -282,6 +291,7 @@ type _switchNode struct {
 	Default      int
 	CaseList     [](*_caseNode)
 	labelSet     _labelSet
+	block        int // For coverage (see _converter.branch)
 }
 
 func newSwitchNode(discriminant _node) *_switchNode {
@@ -22,6 +22,9 @@ func newObject(runtime *_runtime, class string) *_object {
 		property:    make(map[string]_property),
 		extensible:  true,
 	}
+	if runtime != nil {
+		runtime.allocate(objectSize)
+	}
 	return self
 }
 
@@ -136,6 +139,9 @@ func (self *_object) _write(name string, value interface{}, mode _propertyMode)
 	self.property[name] = _property{value, mode}
 	if !exists {
 		self.propertyOrder = append(self.propertyOrder, name)
+		if self.runtime != nil {
+			self.runtime.allocate(propertySize + len(name))
+		}
 	}
 }
 
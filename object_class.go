@@ -33,6 +33,7 @@ var (
 	_classGoMap,
 	_classGoArray,
 	_classGoSlice,
+	_classHost,
 	_ *_objectClass
 )
 
@@ -151,6 +152,20 @@ func init() {
 		goSliceEnumerate,
 		objectClone,
 	}
+
+	_classHost = &_objectClass{
+		hostGetOwnProperty,
+		objectGetProperty,
+		objectGet,
+		objectCanPut,
+		hostPut,
+		hostHasProperty,
+		hostHasOwnProperty,
+		hostDefineOwnProperty,
+		hostDelete,
+		hostEnumerate,
+		objectClone,
+	}
 }
 
 // Allons-y
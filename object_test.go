@@ -607,6 +607,48 @@ func TestObjectGetterSetter(t *testing.T) {
 
 }
 
+func TestObjectLiteralGetterSetter(t *testing.T) {
+	Terst(t)
+
+	test := runTest()
+
+	test(`
+        var abc = {
+            def: 1,
+            get ghi() {
+                return this.def + 1;
+            },
+            set ghi(value) {
+                this.def = value * 2;
+            },
+            get: "get",
+            set: function(){ return "set" }
+        };
+        abc.ghi = 3;
+        var descriptor = Object.getOwnPropertyDescriptor(abc, "ghi");
+        [ abc.def, abc.ghi, abc.get, abc.set(), typeof descriptor.get, typeof descriptor.set, descriptor.enumerable, descriptor.configurable, Object.keys(abc) ];
+    `, "6,7,get,set,function,function,true,true,def,ghi,get,set")
+
+	test(`
+        var abc = {
+            get "def ghi"() { return "xyzzy" },
+            get 1() { return 1 }
+        };
+        abc.jkl = 0;
+        [ abc["def ghi"], abc[1], "set" in abc, Object.getOwnPropertyDescriptor(abc, "1").set ];
+    `, "xyzzy,1,false,")
+
+	// Only a getter, so the property cannot be set
+	test(`
+        var abc = { get def() { return "def" } };
+        abc.def = "ghi";
+        abc.def;
+    `, "def")
+
+	test(`raise: ({ get abc(def) {} })`, "SyntaxError: Getter must not have any formal parameters.")
+	test(`raise: ({ set abc() {} })`, "SyntaxError: Setter must have exactly one formal parameter.")
+}
+
 func TestProperty(t *testing.T) {
 	Terst(t)
 
@@ -63,6 +63,17 @@ Embedding a Go function in JavaScript:
 		result = twoPlus(2.0) // 4
 	`)
 
+A Go function of any other type can be set, too. Its arguments are converted to
+the types of its parameters (see ExportTo), and a non-nil error (as the last
+result) is thrown as an Error:
+
+	Otto.Set("parsePrice", func(text string, options ...string) (float64, error) {
+		return strconv.ParseFloat(text, 64)
+	})
+
+	Otto.Run(`parsePrice("1.5")`) // 1.5
+	Otto.Run(`parsePrice("Xyzzy")`) // Error: strconv.ParseFloat: parsing "Xyzzy": invalid syntax
+
 You can run (Go) JavaScript from the commandline with: http://github.com/robertkrimen/otto/tree/master/otto
 
 	$ go get -v github.com/robertkrimen/otto/otto
@@ -71,6 +82,17 @@ Run JavaScript by entering some source on stdin or by giving otto a filename:
 
 	$ otto example.js
 
+With no filename (and a terminal), otto will start an interactive REPL:
+
+	$ otto
+	> 1 + 1
+	2
+
+To step through a script (with breakpoints, backtraces, and evaluation in the
+current frame; type help at the prompt for the commands):
+
+	$ otto debug example.js
+
 Optionally include the JavaScript utility-belt library, underscore, with this import:
 
 	import (
@@ -85,10 +107,8 @@ For more information: http://github.com/robertkrimen/otto/tree/master/underscore
 Caveat Emptor
 
     * For now, otto is a hybrid ECMA3/ECMA5 interpreter. Parts of the specification are still works in progress.
-    * For example, "use strict" will parse, but does nothing.
+    * "use strict" is supported, but the syntax restrictions of strict mode (e.g. no with statement) do not apply to code given to eval by strict code.
     * Error reporting needs to be improved.
-    * Does not support the (?!) or (?=) regular expression syntax (because Go does not)
-    * JavaScript considers a vertical tab (\000B <VT>) to be part of the whitespace class (\s), while RE2 does not.
     * Really, error reporting could use some improvement.
 
 Regular Expression Syntax
@@ -98,15 +118,34 @@ Go translates JavaScript-style regular expressions into something that is "regex
 Unfortunately, JavaScript has positive lookahead, negative lookahead, and backreferencing,
 all of which are not supported by Go's RE2-like engine: https://code.google.com/p/re2/wiki/Syntax
 
-A brief discussion of these limitations: "Regexp (?!re)" https://groups.google.com/forum/?fromgroups=#%21topic/golang-nuts/7qgSDWPIh_E
+So, a regular expression that RE2 cannot do, such as /^(?=.*\d)\w+$/ or /(["'])(.*?)\1/, is instead
+run by otto's own backtracking engine (with the ECMAScript semantics of lookahead, backreferences, etc.).
+Backtracking can take exponential time with a pathological regular expression (e.g. /(a+)+(?=b)/), so a
+search that takes too many steps throws a RangeError instead of running forever.
 
 More information about RE2: https://code.google.com/p/re2/
 
-JavaScript considers a vertical tab (\000B <VT>) to be part of the whitespace class (\s), while RE2 does not.
-
 Halting Problem
 
-If you want to stop long running executions (like third-party code), you can use the interrupt channel to do this:
+If you want to stop long running executions (like third-party code), the simplest way is to run
+the code with a context.Context that has a deadline (or that you cancel):
+
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    _, err := Otto.RunContext(ctx, unsafe)
+    switch err {
+    case context.DeadlineExceeded:
+        // The code took too long
+    case otto.ErrInterrupted:
+        // The context was cancelled
+    }
+
+Either way, the runtime is left in a usable state afterwards. Note that the runtime can only stop
+between JavaScript operations, so a long-running (Go) native function is not interrupted.
+
+To bound the work (rather than the time) a script can do, and to limit its call depth and memory, see Limits.
+
+Alternatively, you can use the interrupt channel to do this:
 
     package main
 
@@ -156,7 +195,13 @@ If you want to stop long running executions (like third-party code), you can use
 Where is setTimeout/setInterval?
 
 These timing functions are not actually part of the ECMA-262 specification. Typically, they belong to the `windows` object (in the browser).
-It would not be difficult to provide something like these via Go, but you probably want to wrap otto in an event loop in that case.
+They are provided by the loop package, which wraps otto in an event loop: http://github.com/robertkrimen/otto/tree/master/loop
+
+	Loop := loop.New(otto.New())
+	err := Loop.Run(func(vm *otto.Otto) error {
+		_, err := vm.Run(`setTimeout(function(){ console.log("Nothing happens.") }, 1000)`)
+		return err
+	})
 
 Here is some discussion of the problem:
 
@@ -170,6 +215,7 @@ Here is some discussion of the problem:
 package otto
 
 import (
+	"context"
 	"fmt"
 	"github.com/robertkrimen/otto/registry"
 	"strings"
@@ -190,6 +236,9 @@ func New() *Otto {
 	}
 	self.runtime.Otto = self
 	self.Set("console", self.runtime.newConsole())
+	if registry.HasNative() {
+		self.runtime.enableRequire()
+	}
 
 	registry.Apply(func(entry registry.Entry) {
 		self.Run(entry.Source())
@@ -220,7 +269,43 @@ func Run(source string) (*Otto, Value, error) {
 // If the runtime is unable to parse source, then this function will return undefined and the parse error (nothing
 // will be evaluated in this case).
 func (self Otto) Run(source string) (Value, error) {
-	return self.runtime.runSafe(source)
+	return self.runtime.runSafe("", source)
+}
+
+// RunFile is like Run, except the source is identified by the given filename,
+// which will appear in any error (and stack trace) resulting from the source.
+//
+//		_, err := Otto.RunFile("example.js", `abcdef.length`)
+//		// err = ReferenceError: abcdef is not defined (example.js: line 1)
+func (self Otto) RunFile(filename, source string) (Value, error) {
+	return self.runtime.runSafe(filename, source)
+}
+
+// RunScript will run the given script (see Compile), returning the resulting
+// value and error (if any).
+//
+// The same script can be run by this and any other runtime, any number of times.
+func (self Otto) RunScript(script *Script) (Value, error) {
+	return self.runtime.runScriptSafe(script)
+}
+
+// RunContext is like Run, except that execution is aborted when the given
+// context is cancelled or its deadline passes.
+//
+// If the deadline passes, then the error is context.DeadlineExceeded, otherwise
+// (if the context is cancelled) the error is ErrInterrupted. An interruption
+// cannot be caught (by try/catch) in JavaScript.
+//
+// A RunContext (or CallContext) nested in another (e.g. called by a native function)
+// is also aborted when the context of the outer one is done.
+func (self Otto) RunContext(ctx context.Context, source string) (Value, error) {
+	value := UndefinedValue()
+	err := self.runtime.withContext(ctx, func() error {
+		var err error
+		value, err = self.Run(source)
+		return err
+	})
+	return value, err
 }
 
 // Get the value of the top-level binding of the given name.
@@ -229,7 +314,7 @@ func (self Otto) Run(source string) (Value, error) {
 // will be undefined.
 func (self Otto) Get(name string) (Value, error) {
 	value := UndefinedValue()
-	err := catchPanic(func() {
+	err := self.runtime.catchPanic(func() {
 		value = self.getValue(name)
 	})
 	return value, err
@@ -254,7 +339,7 @@ func (self Otto) Set(name string, value interface{}) error {
 		if err != nil {
 			return err
 		}
-		err = catchPanic(func() {
+		err = self.runtime.catchPanic(func() {
 			self.setValue(name, value)
 		})
 		return err
@@ -301,7 +386,7 @@ func (self Otto) Call(source string, this interface{}, argumentList ...interface
 	if !new_ && this == nil {
 		value := UndefinedValue()
 		fallback := false
-		err := catchPanic(func() {
+		err := self.runtime.catchPanic(func() {
 			programNode := mustParse(source + "()")
 			if callNode, valid := programNode.Body[0].(*_callNode); valid {
 				value = self.runtime.evaluateCall(callNode, argumentList)
@@ -341,6 +426,18 @@ func (self Otto) Call(source string, this interface{}, argumentList ...interface
 	return value, nil
 }
 
+// CallContext is like Call, except that execution is aborted when the given
+// context is cancelled or its deadline passes (see RunContext).
+func (self Otto) CallContext(ctx context.Context, source string, this interface{}, argumentList ...interface{}) (Value, error) {
+	value := UndefinedValue()
+	err := self.runtime.withContext(ctx, func() error {
+		var err error
+		value, err = self.Call(source, this, argumentList...)
+		return err
+	})
+	return value, err
+}
+
 // Object will run the given source and return the result as an object.
 //
 // For example, accessing an existing object:
@@ -359,7 +456,7 @@ func (self Otto) Call(source string, this interface{}, argumentList ...interface
 // If there is an error (like the source does not result in an object), then
 // nil and an error is returned.
 func (self Otto) Object(source string) (*Object, error) {
-	value, err := self.runtime.runSafe(source)
+	value, err := self.runtime.runSafe("", source)
 	if err != nil {
 		return nil, err
 	}
@@ -374,6 +471,35 @@ func (self Otto) ToValue(value interface{}) (Value, error) {
 	return self.runtime.ToValue(value)
 }
 
+// MakeCustomError returns a new Error object with the name and message, to be
+// thrown from a native function (see FunctionCall.Throw):
+//
+//		Otto.Set("withdraw", func(call otto.FunctionCall) otto.Value {
+//			...
+//			call.Throw(call.Otto.MakeCustomError("InsufficientFunds", "Nothing happens."))
+//		})
+//
+// The object is an instance of Error (or, for a name like "TypeError", of that
+// error), so a script can catch it, and the *Error of an uncaught one has the name.
+func (self Otto) MakeCustomError(name, message string) Value {
+	return toValue_object(self.runtime.newError(name, toValue_string(message)))
+}
+
+// MakeTypeError returns a new TypeError object with the message (see MakeCustomError).
+func (self Otto) MakeTypeError(message string) Value {
+	return self.MakeCustomError("TypeError", message)
+}
+
+// MakeRangeError returns a new RangeError object with the message (see MakeCustomError).
+func (self Otto) MakeRangeError(message string) Value {
+	return self.MakeCustomError("RangeError", message)
+}
+
+// MakeSyntaxError returns a new SyntaxError object with the message (see MakeCustomError).
+func (self Otto) MakeSyntaxError(message string) Value {
+	return self.MakeCustomError("SyntaxError", message)
+}
+
 // Copy will create a copy/clone of the runtime.
 //
 // Copy is useful for saving some processing time when creating many similar
@@ -434,7 +560,7 @@ func (self Object) Value() Value {
 // Get the value of the property with the given name.
 func (self Object) Get(name string) (Value, error) {
 	value := UndefinedValue()
-	err := catchPanic(func() {
+	err := self.object.runtime.catchPanic(func() {
 		value = self.object.get(name)
 	})
 	return value, err
@@ -450,7 +576,7 @@ func (self Object) Set(name string, value interface{}) error {
 		if err != nil {
 			return err
 		}
-		err = catchPanic(func() {
+		err = self.object.runtime.catchPanic(func() {
 			self.object.put(name, value, true)
 		})
 		return err
@@ -469,6 +595,26 @@ func (self Object) Keys() []string {
 	return keys
 }
 
+// KeysByParent gets the names of all the properties of the object (including
+// those that are not enumerable), followed by the names of all the properties
+// of its prototype, and so on up the prototype chain:
+//
+//		[ [ own ], [ prototype ], [ prototype of prototype ], ... ]
+//
+// This is useful for something like completion in a REPL.
+func (self Object) KeysByParent() [][]string {
+	var keysByParent [][]string
+	for object := self.object; object != nil; object = object.prototype {
+		var keys []string
+		object.enumerate(true, func(name string) bool {
+			keys = append(keys, name)
+			return true
+		})
+		keysByParent = append(keysByParent, keys)
+	}
+	return keysByParent
+}
+
 // Class will return the class string of the object.
 //
 // The return value will (generally) be one of:
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/robertkrimen/otto"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const debugHelp = `c, continue          Continue until the next breakpoint
+s, step              Step to the next statement (into a function call)
+n, next              Step over a function call
+o, out               Step out of the current function
+b, break [file:]line Set a breakpoint
+d, delete [file:]line
+                     Clear a breakpoint
+breakpoints          List every breakpoint
+bt, backtrace        Print the stack
+l, list              Print the source around the current line
+scope                Print the variables in scope
+p, print expression  Evaluate the expression in the current frame, and print the result
+h, help              Print this help
+q, quit              Stop the script and exit
+`
+
+// _debugSession is the command line interface of the debugger (otto debug script.js)
+type _debugSession struct {
+	source   map[string][]string // Filename => lines
+	input    *bufio.Reader
+	output   io.Writer
+	debugger *otto.Debugger
+	quit     bool // At the end of input, so run without pausing
+}
+
+// debug runs the script with the debugger, pausing at the first statement.
+func debug(filename string, script string) error {
+	self := &_debugSession{
+		source: map[string][]string{
+			filename: strings.Split(script, "\n"),
+		},
+		input:  bufio.NewReader(os.Stdin),
+		output: os.Stdout,
+	}
+	self.debugger = otto.NewDebugger(self.pause)
+	self.debugger.PauseOnException(true)
+	self.debugger.Step(otto.DebugStepIn)
+
+	Otto := otto.New()
+	Otto.SetDebugger(self.debugger)
+	_, err := Otto.RunFile(filename, script)
+	if self.quit {
+		return nil
+	}
+	return err
+}
+
+func (self *_debugSession) pause(event *otto.DebugEvent) otto.DebugAction {
+	if self.quit {
+		return otto.DebugContinue
+	}
+	if event.Reason == otto.DebugException {
+		fmt.Fprintf(self.output, "paused (exception) at %s: %s\n", self.location(event.Filename, event.Line), event.Exception)
+	} else {
+		fmt.Fprintf(self.output, "paused (%s) at %s\n", event.Reason, self.location(event.Filename, event.Line))
+	}
+	self.list(event, 0)
+
+	for {
+		fmt.Fprint(self.output, "debug> ")
+		line, err := self.input.ReadString('\n')
+		if err != nil && line == "" {
+			// End of input, so run to the end
+			fmt.Fprintln(self.output)
+			self.quit = true
+			return otto.DebugContinue
+		}
+		command, argument := splitCommand(strings.TrimSpace(line))
+		switch command {
+		case "":
+		case "c", "continue":
+			return otto.DebugContinue
+		case "s", "step":
+			return otto.DebugStepIn
+		case "n", "next":
+			return otto.DebugStepOver
+		case "o", "out":
+			return otto.DebugStepOut
+		case "b", "break", "d", "delete":
+			filename, line, err := self.parseBreakpoint(event, argument)
+			if err != nil {
+				fmt.Fprintln(self.output, err)
+				continue
+			}
+			if command == "b" || command == "break" {
+				self.debugger.SetBreakpoint(filename, line)
+				fmt.Fprintf(self.output, "Breakpoint set at %s\n", self.location(filename, line))
+			} else {
+				self.debugger.ClearBreakpoint(filename, line)
+				fmt.Fprintf(self.output, "Breakpoint cleared at %s\n", self.location(filename, line))
+			}
+		case "breakpoints":
+			for filename, lineList := range self.debugger.Breakpoints() {
+				for _, line := range lineList {
+					fmt.Fprintln(self.output, self.location(filename, line))
+				}
+			}
+		case "bt", "backtrace":
+			for _, frame := range event.Stack {
+				fmt.Fprintf(self.output, "    at %s\n", frame)
+			}
+		case "l", "list":
+			self.list(event, 5)
+		case "scope":
+			for _, scope := range event.Scope() {
+				nameList := []string{}
+				for name := range scope.Bindings {
+					nameList = append(nameList, name)
+				}
+				sort.Strings(nameList)
+				fmt.Fprintf(self.output, "%s:\n", scope.Kind)
+				for _, name := range nameList {
+					fmt.Fprintf(self.output, "    %s = %s\n", name, summary(scope.Bindings[name]))
+				}
+			}
+		case "p", "print":
+			value, err := event.Eval(argument)
+			if err != nil {
+				fmt.Fprintln(self.output, err)
+				continue
+			}
+			fmt.Fprintln(self.output, value.Inspect(otto.InspectOptions{}))
+		case "h", "help":
+			fmt.Fprint(self.output, debugHelp)
+		case "q", "quit":
+			os.Exit(0)
+		default:
+			fmt.Fprintf(self.output, "Unknown command: %s (try help)\n", command)
+		}
+	}
+}
+
+// summary is a short (one line) description of the value, for listing a scope.
+func summary(value otto.Value) string {
+	if value.IsObject() {
+		return "[" + value.Class() + "]"
+	}
+	return value.Inspect(otto.InspectOptions{})
+}
+
+func splitCommand(line string) (string, string) {
+	index := strings.IndexAny(line, " \t")
+	if index == -1 {
+		return line, ""
+	}
+	return line[:index], strings.TrimSpace(line[index+1:])
+}
+
+// parseBreakpoint parses "line" (in the current file) or "file:line".
+func (self *_debugSession) parseBreakpoint(event *otto.DebugEvent, argument string) (string, int, error) {
+	filename := event.Filename
+	if index := strings.LastIndex(argument, ":"); index != -1 {
+		filename, argument = argument[:index], argument[index+1:]
+	}
+	line, err := strconv.Atoi(argument)
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("Invalid breakpoint: %q (expected [file:]line)", argument)
+	}
+	return filename, line, nil
+}
+
+// list prints the current line, and the given number of lines around it.
+func (self *_debugSession) list(event *otto.DebugEvent, around int) {
+	lineList := self.source[event.Filename]
+	if event.Line < 1 || event.Line > len(lineList) {
+		return
+	}
+	start, end := event.Line-around, event.Line+around
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lineList) {
+		end = len(lineList)
+	}
+	for line := start; line <= end; line++ {
+		marker := " "
+		if line == event.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(self.output, "%s %4d  %s\n", marker, line, lineList[line-1])
+	}
+}
+
+func (self *_debugSession) location(filename string, line int) string {
+	if filename == "" {
+		filename = "<stdin>"
+	}
+	return fmt.Sprintf("%s:%d", filename, line)
+}
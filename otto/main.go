@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"github.com/robertkrimen/otto"
+	"github.com/robertkrimen/otto/repl"
 	"github.com/robertkrimen/otto/underscore"
 	"io/ioutil"
 	"os"
@@ -11,12 +12,52 @@ import (
 
 var underscoreFlag *bool = flag.Bool("underscore", true, "Load underscore into the runtime environment")
 
+// isTerminal reports whether the file is a terminal (character device),
+// as opposed to a pipe or a regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 func main() {
 	flag.Parse()
+	if !*underscoreFlag {
+		underscore.Disable()
+	}
 	var script []byte
 	var err error
 	filename := flag.Arg(0)
+	if filename == "debug" {
+		filename = flag.Arg(1)
+		if filename == "" {
+			fmt.Println("Usage: otto debug script.js")
+			os.Exit(64)
+		}
+		script, err = ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Printf("Can't open file \"%v\": %v\n", filename, err)
+			os.Exit(64)
+		}
+		err = debug(filename, string(script))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(64)
+		}
+		return
+	}
+	if filename == "" && isTerminal(os.Stdin) {
+		err = repl.Run(otto.New())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(64)
+		}
+		return
+	}
 	if filename == "" || filename == "-" {
+		filename = ""
 		script, err = ioutil.ReadAll(os.Stdin)
 		if err != nil {
 			fmt.Printf("Can't read stdin: %v\n", err)
@@ -29,11 +70,8 @@ func main() {
 			os.Exit(64)
 		}
 	}
-	if !*underscoreFlag {
-		underscore.Disable()
-	}
 	Otto := otto.New()
-	_, err = Otto.Run(string(script))
+	_, err = Otto.RunFile(filename, string(script))
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(64)
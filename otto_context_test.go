@@ -0,0 +1,92 @@
+package otto
+
+import (
+	. "./terst"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOttoRunContext(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := Otto.RunContext(ctx, `
+        function abc() {
+            try {
+                while (true) {}
+            }
+            catch (err) {
+            }
+        }
+        abc();
+    `)
+	Is(err, context.DeadlineExceeded)
+	Is(len(Otto.runtime.Stack), 1)
+
+	// The runtime is still usable
+	value, err := Otto.Run(`1 + 1`)
+	Is(err, nil)
+	Is(value, "2")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err = Otto.RunContext(ctx, `for (;;) {}`)
+	Is(err, ErrInterrupted)
+
+	// Already cancelled
+	_, err = Otto.RunContext(ctx, `abc = 1`)
+	Is(err, ErrInterrupted)
+	value, _ = Otto.Get("abc")
+	Is(value.IsFunction(), true)
+
+	value, err = Otto.RunContext(context.Background(), `abc = 2`)
+	Is(err, nil)
+	Is(value, "2")
+
+	// A nested call is bound to the outer context, too
+	var nestedErr error
+	Otto.Set("nested", func(call FunctionCall) Value {
+		_, nestedErr = Otto.RunContext(context.Background(), `for (;;) {}`)
+		return UndefinedValue()
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = Otto.RunContext(ctx, `nested(); for (;;) {}`)
+	Is(err, context.DeadlineExceeded)
+	Is(nestedErr, context.DeadlineExceeded)
+	Is(len(Otto.runtime.context), 0)
+
+	value, err = Otto.RunContext(context.Background(), `nested; abc = 3`)
+	Is(err, nil)
+	Is(value, "3")
+}
+
+func TestOttoCallContext(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	Otto.Run(`
+        function forever() {
+            while (true) {}
+        }
+        function add(abc, def) {
+            return abc + def;
+        }
+    `)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := Otto.CallContext(ctx, "forever", nil)
+	Is(err, context.DeadlineExceeded)
+
+	value, err := Otto.CallContext(context.Background(), "add", nil, 1, 2)
+	Is(err, nil)
+	Is(value, "3")
+}
@@ -2,6 +2,7 @@ package otto
 
 import (
 	. "./terst"
+	"errors"
 	"testing"
 )
 
@@ -47,3 +48,267 @@ func TestOttoError(t *testing.T) {
 	Is(err, "ReferenceError: xyzzy is not defined (line 4)")
 
 }
+
+func TestOttoError_Error(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+
+	_, err := Otto.Run(`
+		(function(){
+			return abcdef.length
+		})()
+	`)
+	{
+		var err_ *Error
+		Is(errors.As(err, &err_), true)
+		Is(err_.Name, "ReferenceError")
+		Is(err_.Message, "abcdef is not defined")
+		Is(err_.Line, 3)
+		Is(err_.Value.IsObject(), true)
+		Is(err_.Value, "ReferenceError: abcdef is not defined")
+	}
+
+	_, err = Otto.Run(`throw new TypeError("Nothing happens.")`)
+	{
+		err := err.(*Error)
+		Is(err.Name, "TypeError")
+		Is(err.Message, "Nothing happens.")
+		Is(err.Value.IsObject(), true)
+	}
+
+	_, err = Otto.Run(`throw { abc: 3.14159 }`)
+	{
+		err := err.(*Error)
+		Is(err.Name, "")
+		Is(err.Message, "[object Object]")
+		value, _ := err.Value.Object().Get("abc")
+		Is(value, "3.14159")
+	}
+
+	_, err = Otto.Run("abc = 1;\ndef = }")
+	{
+		err := err.(*Error)
+		Is(err.Name, "SyntaxError")
+		Is(err.Message, "Unexpected token }")
+		Is(err.Line, 2)
+		Is(err.Column, 8)
+		Is(err.Value.IsObject(), true)
+	}
+
+	_, err = Otto.Call(`[].xyzzy`, nil)
+	{
+		err := err.(*Error)
+		Is(err.Name, "TypeError")
+	}
+}
+
+func TestOttoError_Stack(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+
+	_, err := Otto.Run(`
+		function abc() {
+			xyzzy();
+		}
+		[ 1 ].forEach(function(){
+			abc();
+		});
+	`)
+	{
+		err := err.(*Error)
+		Is(err, "ReferenceError: xyzzy is not defined (line 3)")
+		Is(len(err.Stack), 3)
+		Is(err.Stack[0], Frame{Function: "abc", Line: 3, Column: 4})
+		Is(err.Stack[1], Frame{Line: 6, Column: 4})
+		Is(err.Stack[2], Frame{Line: 5, Column: 3})
+		Is(err.Stack[0].String(), "abc (<anonymous>:3:4)")
+		stack, _ := err.Value.Object().Get("stack")
+		Is(stack, "ReferenceError: xyzzy is not defined\n    at abc (<anonymous>:3:4)\n    at <anonymous>:6:4\n    at <anonymous>:5:3")
+	}
+
+	_, err = Otto.Run(`
+		function abc() {
+			throw new Error("Nothing happens.");
+		}
+		abc();
+	`)
+	{
+		err := err.(*Error)
+		Is(len(err.Stack), 2)
+		Is(err.Stack[0], Frame{Function: "abc", Line: 3, Column: 10})
+		Is(err.Stack[1], Frame{Line: 5, Column: 3})
+	}
+}
+
+func TestOttoError_RunFile(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+
+	_, err := Otto.RunFile("xyzzy.js", `
+		function abc() {
+			return def.ghi;
+		}
+		abc();
+	`)
+	Is(err, "ReferenceError: def is not defined (xyzzy.js: line 3)")
+	{
+		err := err.(*Error)
+		Is(err.Filename, "xyzzy.js")
+		Is(err.Line, 3)
+		Is(err.Column, 11)
+		Is(err.Stack[0], Frame{Function: "abc", Filename: "xyzzy.js", Line: 3, Column: 11})
+		Is(err.Stack[1].String(), "xyzzy.js:5:3")
+	}
+
+	_, err = Otto.RunFile("xyzzy.js", "abc = 1;\ndef = }")
+	Is(err, "SyntaxError: Unexpected token } (xyzzy.js: line 2)")
+	{
+		err := err.(*Error)
+		Is(err.Filename, "xyzzy.js")
+		Is(err.Column, 8)
+	}
+
+	_, err = Otto.RunFile("xyzzy.js", "/a**/")
+	{
+		err := err.(*Error)
+		Is(err.Name, "SyntaxError")
+		Is(err.Filename, "xyzzy.js")
+		Is(err.Line, 1)
+		Is(err.Column, 1)
+	}
+
+	_, err = Otto.Run(`
+		xyzzy;
+	`)
+	{
+		err := err.(*Error)
+		Is(err.Filename, "")
+		Is(err.Line, 2)
+		Is(err.Column, 3)
+	}
+
+	_, err = Otto.RunFile("xyzzy.js", `
+		function abc() {
+			throw new Error("boom");
+		}
+		abc();
+	`)
+	Is(err, "Error: boom") // The position is only in the fields
+	{
+		err := err.(*Error)
+		Is(err.Filename, "xyzzy.js")
+		Is(err.Line, 3)
+		Is(err.Column, 10)
+		Is(err.Stack[0], Frame{Function: "abc", Filename: "xyzzy.js", Line: 3, Column: 10})
+	}
+}
+
+func TestOttoError_native(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+
+	Otto.Set("reject", func(call FunctionCall) Value {
+		switch call.Argument(0).String() {
+		case "type":
+			call.Throw(call.Otto.MakeTypeError("Nothing happens."))
+		case "range":
+			call.Throw(call.Otto.MakeRangeError("Nothing happens."))
+		case "custom":
+			call.Throw(call.Otto.MakeCustomError("XyzzyError", "Nothing happens."))
+		case "value":
+			call.Throw(call.Argument(1))
+		}
+		return UndefinedValue()
+	})
+
+	value, err := Otto.Run(`
+        var result = [];
+        [ "type", "range", "custom", "value" ].forEach(function(kind){
+            try {
+                reject(kind, 3.14159);
+            } catch (error) {
+                result.push(error instanceof Error, String(error));
+            }
+        });
+        result.push(new TypeError() instanceof Error);
+        result;
+    `)
+	Is(err, nil)
+	Is(value, "true,TypeError: Nothing happens.,true,RangeError: Nothing happens.,true,XyzzyError: Nothing happens.,false,3.14159,true")
+
+	_, err = Otto.Run(`reject("type")`)
+	Is(err, "TypeError: Nothing happens.")
+	Is(err.(*Error).Line, 1)
+	Is(err.(*Error).Column, 1)
+
+	_, err = Otto.Run(`reject("custom")`)
+	Is(err.(*Error).Name, "XyzzyError")
+
+	errXyzzy := errors.New("Xyzzy")
+	Otto.Set("fail", func(call FunctionCall) Value {
+		switch call.Argument(0).String() {
+		case "error":
+			panic(errXyzzy)
+		case "nil":
+			var object *Object
+			object.Class()
+		}
+		panic("Nothing happens.")
+	})
+
+	value, err = Otto.Run(`
+        var result = [];
+        [ "error", "nil", "string" ].forEach(function(kind){
+            try {
+                fail(kind);
+            } catch (error) {
+                result.push(error.name, error.message);
+            }
+        });
+        result;
+    `)
+	Is(err, nil)
+	Is(value, "Error,Xyzzy,Error,runtime error: invalid memory address or nil pointer dereference,Error,Nothing happens.")
+
+	_, err = Otto.Run(`fail("error")`)
+	Is(err, "Error: Xyzzy (line 1)")
+	Is(errors.Is(err, errXyzzy), true)
+}
+
+func TestOttoError_nativeInterrupt(t *testing.T) {
+	Terst(t)
+
+	halt := errors.New("Halt")
+	Otto := New()
+	Otto.Interrupt = make(chan func(), 1)
+	interrupt := func() {
+		Otto.Interrupt <- func() {
+			panic(halt)
+		}
+	}
+	Otto.Set("callback", func(call FunctionCall) Value {
+		interrupt() // Within the callback
+		value, err := call.Argument(0).Call(UndefinedValue())
+		Is(err, nil) // Not reached
+		return value
+	})
+
+	run := func(source string) (caught interface{}) {
+		defer func() {
+			caught = recover()
+		}()
+		Otto.Run(source)
+		return nil
+	}
+
+	// A panic of an Interrupt function is not caught, even within a native function
+	interrupt()
+	Is(run(`for (;;) {}`), halt)
+	Is(run(`callback(function(){ for (;;) {} })`), halt)
+	Is(run(`try { callback(function(){ for (;;) {} }) } catch (error) {}`), halt)
+	Is(run(`1 + 1`), nil)
+}
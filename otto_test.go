@@ -77,7 +77,7 @@ func runTestWithOtto() (*Otto, func(string, ...interface{}) Value) {
 				source = source[6:]
 				source = strings.TrimLeft(source, " ")
 			}
-			value = Otto.runtime.run(source)
+			value = Otto.runtime.run("", source)
 		}
 		value = Otto.runtime.GetValue(value)
 		if len(expect) > 0 {
@@ -367,6 +367,13 @@ func TestAPI(t *testing.T) {
 	Is(err, nil)
 	object = abc.Object() // Object abc
 	Is(object.Keys(), []string{"0", "1", "2", "3", "4", "def"})
+	{
+		keysByParent := object.KeysByParent()
+		Is(len(keysByParent), 3) // abc, Array.prototype, Object.prototype
+		Is(keysByParent[0], []string{"length", "0", "1", "2", "3", "4", "def"})
+		Is(len(keysByParent[1]) > 0, true)
+		Is(len(keysByParent[2]) > 0, true)
+	}
 }
 
 func TestUnicode(t *testing.T) {
@@ -1,313 +1,538 @@
 package otto
 
 import (
-	"fmt"
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/parser"
 	"strings"
 )
 
-const ottoDebug = false
-
-const endOfFile = -1
-const startOfFile = -2
+// The parsing proper is done by the parser package, which yields an AST (package ast).
+// Here, the AST is converted into the (internal) node tree that is evaluated by the runtime.
 
 func mustParse(source string) *_programNode {
-	parser := newParser()
-	parser.lexer.Source = source
-	return parser.Parse()
+	program, err := parse(source)
+	if err != nil {
+		panic(err)
+	}
+	return program
+}
+
+func parse(source string) (*_programNode, interface{}) {
+	return parseFile("", source, false)
 }
 
-func parse(source string) (result *_programNode, err interface{}) {
+// parseFile parses the source, recording the filename in every node, and (if
+// coverage is true, and there is a filename) the statements and branches of the
+// program, for coverage
+func parseFile(filename, source string, coverage bool) (result *_programNode, err interface{}) {
 	defer func() {
 		if caught := recover(); caught != nil {
 			switch caught := caught.(type) {
 			case *_syntaxError, _error:
-				err = caught
+				result, err = nil, caught
 				return
 			}
 			panic(caught)
 		}
 	}()
-	parser := newParser()
-	parser.lexer.Source = source
-	return parser.Parse(), nil
+	program, parseErr := parser.ParseFile(filename, source)
+	if parseErr != nil {
+		return nil, convertParserError(parseErr)
+	}
+	converter := &_converter{filename: filename}
+	if coverage && filename != "" {
+		converter.coverage = newCoverageProgram(source)
+	}
+	node := converter.convertProgram(program)
+	node.coverage = converter.coverage
+	return node, nil
 }
 
-func init() {
-
-	// 2-character
-	// <= >= == != ++ -- << >> && ||
-	// += -= *= %= &= |= ^= /=
-	for _, value := range "<>=!+-*%&|^/" {
-		punctuatorTable[string(value)+"="] = true
+// parseFunction parses the source of a function body, as with new Function(...)
+func parseFunction(parameterList []string, body string) *_functionNode {
+	function, err := parser.ParseFunction(parameterList, body)
+	if err != nil {
+		panic(convertParserError(err))
 	}
-
-	for _, value := range "+-<>&|" {
-		punctuatorTable[string(value)+string(value)] = true
+	converter := &_converter{}
+	return converter.convertFunction(function, false)
+}
+
+// parseModule parses the source of a module as the body of a function (with the
+// parameters), recording the filename in every node, and returns the function and
+// (if coverage is true) its statements and branches (for coverage)
+func parseModule(filename string, parameterList []string, source string, coverage bool) (*_functionNode, *_coverageProgram) {
+	function, err := parser.ParseFunction(parameterList, source)
+	if err != nil {
+		if parserError, ok := err.(*parser.Error); ok {
+			parserError.Filename = filename
+		}
+		panic(convertParserError(err))
 	}
-
-	// 1-character
-	for _, value := range "[]<>+-*%&|^!~?:=/;{},()" {
-		punctuatorTable[string(value)] = true
+	converter := &_converter{
+		filename: filename,
 	}
+	if coverage {
+		converter.coverage = newCoverageProgram(source)
+	}
+	return converter.convertFunction(function, false), converter.coverage
 }
 
-type _parser struct {
-	lexer   _lexer
-	Stack   [](*_sourceScope)
-	history []_token
-}
-
-func newParser() *_parser {
-	self := &_parser{
-		history: make([]_token, 0, 4),
+func convertParserError(err error) interface{} {
+	parserError, ok := err.(*parser.Error)
+	if !ok {
+		return &_syntaxError{Message: err.Error()}
+	}
+	if parserError.Name == "ReferenceError" {
+		error := newReferenceError(parserError.Message)
+		error._position = _position{
+			Filename: parserError.Filename,
+			Line:     parserError.Line,
+			Column:   parserError.Column,
+		}
+		return error
+	}
+	return &_syntaxError{
+		Message:   parserError.Message,
+		Filename:  parserError.Filename,
+		Line:      parserError.Line,
+		Column:    parserError.Column,
+		Character: parserError.Character,
 	}
-	self.lexer.readIn = make([]rune, 0)
-	return self
 }
 
-func (self *_parser) Consume() string {
-	return self.Next().Text
-}
+var assignmentTable map[string]string = map[string]string{}
 
-type _sourceScope struct {
-	VariableList []_declaration
-	FunctionList []_declaration
-	labelSet     _labelSet
-	AllowIn      bool
-	InFunction   bool
-	InSwitch     bool
-	InIteration  bool
+func init() {
+	for _, value := range strings.Fields("= *= /= %= += -= <<= >>= >>>= &= ^= |=") {
+		operator := value[:len(value)-1]
+		assignmentTable[value] = operator
+	}
 }
 
-func (self *_sourceScope) AddVariable(name string) {
-	self.VariableList = append(self.VariableList, _declaration{name, nil})
+// _converter converts an AST (from a single source) into a node tree
+type _converter struct {
+	filename string
+	coverage *_coverageProgram // The statements and branches of the program, for coverage (if there is a filename)
 }
 
-func (self *_sourceScope) AddFunction(name string, definition _node) {
-	self.FunctionList = append(self.FunctionList, _declaration{name, definition})
+func (self *_converter) markNode(node _node, position ast.Node) _node {
+	node.setPosition(_position{
+		Filename: self.filename,
+		Line:     position.Pos().Line,
+		Column:   position.Pos().Column,
+	})
+	return node
 }
 
-func newSourceScope() *_sourceScope {
-	self := &_sourceScope{
-		labelSet: _labelSet{},
-		AllowIn:  true,
+// branch records the (marked) node as a branch with the given number of ways, for
+// coverage, returning its block (which tells apart branches at the same position).
+func (self *_converter) branch(node _node, count int) int {
+	if self.coverage == nil {
+		return 0
 	}
-	return self
-}
-
-func (self *_sourceScope) HasLabel(name string) bool {
-	_, exists := self.labelSet[name]
-	return exists
+	return self.coverage.addBranch(node.position(), count)
 }
 
-func (self *_parser) EnterScope() {
-	scope := newSourceScope()
-	self.Stack = append(self.Stack, scope)
-}
-
-func (self *_parser) LeaveScope() {
-	self.Stack = self.Stack[:len(self.Stack)-1]
+func (self *_converter) convertProgram(program *ast.Program) *_programNode {
+	node := newProgramNode()
+	self.markNode(node, program)
+	node.Body = self.convertStatementList(program.Body)
+	node.FunctionList, node.VariableList = self.convertDeclarationList(program.DeclarationList)
+	node.strict = program.Strict
+	return node
 }
 
-func (self *_parser) Scope() *_sourceScope {
-	return self.Stack[len(self.Stack)-1]
+func (self *_converter) convertDeclarationList(declarationList []ast.Declaration) (functionList []_declaration, variableList []_declaration) {
+	for _, declaration := range declarationList {
+		switch declaration := declaration.(type) {
+		case *ast.FunctionDeclaration:
+			function := declaration.Function
+			functionList = append(functionList, _declaration{function.Name.Name, self.convertFunction(function, true)})
+		case *ast.VariableDeclaration:
+			for _, variable := range declaration.List {
+				variableList = append(variableList, _declaration{variable.Name, nil})
+			}
+		}
+	}
+	return
 }
 
-func (self *_parser) Accept(kind string) bool {
-	if kind == "\n" {
-		// This is a PeekLineSkip, except we
-		// retain the adjusted lexer if we really
-		// skipped a line
-		lexerCopy := self.lexer.Copy()
-		didSkip := lexerCopy.ScanLineSkip()
-		if didSkip {
-			self.lexer = *lexerCopy
+func (self *_converter) convertFunction(function *ast.FunctionLiteral, declaration bool) *_functionNode {
+	node := newFunctionNode()
+	self.markNode(node, function)
+	if function.Name != nil {
+		node.Name = function.Name.Name
+	}
+	for _, parameter := range function.ParameterList {
+		node.AddParameter(parameter.Name)
+		if parameter.Name == "arguments" {
+			node.ArgumentsIsParameter = true
 		}
-		return didSkip
 	}
-	if self.Match(kind) {
-		self.Next()
-		return true
+	if function.Body != nil {
+		node.Body = self.convertStatementList(function.Body.List)
 	}
-	return false
-}
-
-func (self *_parser) Match(kind string) bool {
-	if kind == "\n" {
-		return self.PeekLineSkip()
+	node.FunctionList, node.VariableList = self.convertDeclarationList(function.DeclarationList)
+	node.strict = function.Strict
+	if !declaration && function.Name != nil {
+		// A named function expression can refer to itself (by name) from within its body
+		node.FunctionList = append([]_declaration{{function.Name.Name, node}}, node.FunctionList...)
 	}
-	return self.Peek().Kind == kind
+	return node
 }
 
-func (self *_parser) Expect(kind string) {
-	token := self.Next()
-	if token.Kind != kind {
-		panic(self.Unexpected(token))
-		//panic(fmt.Sprintf("Expect %s but got %s (%s)", kind, token.Kind, token.Text))
+func (self *_converter) convertStatementList(list []ast.Statement) []_node {
+	result := []_node{}
+	for _, statement := range list {
+		result = append(result, self.convertStatement(statement))
 	}
+	return result
 }
 
-var assignmentTable map[string]string = map[string]string{}
-
-func init() {
-	for _, value := range strings.Fields("= *= /= %= += -= <<= >>= >>>= &= ^= |=") {
-		operator := value[:len(value)-1]
-		assignmentTable[value] = operator
+// convertIterationBody flattens a block into the body of a loop
+func (self *_converter) convertIterationBody(body ast.Statement) []_node {
+	switch node := self.convertStatement(body).(type) {
+	case *_blockNode:
+		return node.Body
+	default:
+		return []_node{node}
 	}
 }
 
-func (self *_parser) matchAssignment() bool {
-	if _, exists := assignmentTable[self.Peek().Kind]; exists {
-		return true
+// convertStatement converts the statement, marking the node as a statement
+// (unless there is nothing there to evaluate, like a block)
+func (self *_converter) convertStatement(statement ast.Statement) _node {
+	node := self.convertStatementNode(statement)
+	switch node.(type) {
+	case *_blockNode, *_emptyNode:
+	default:
+		node.setStatement()
+		if self.coverage != nil {
+			self.coverage.statementList = append(self.coverage.statementList, node.position())
+		}
 	}
-	return false
-}
-
-func (self *_parser) ConsumeNull() *_valueNode {
-	node := newNullNode(self.Next().Text)
-	self.markNode(node)
 	return node
 }
 
-func (self *_parser) throwUnexpectedError(token _token) {
-	if futureKeywordTable[token.Kind] {
-		panic(token.newSyntaxError("Unexpected reserved word"))
-	}
-	panic(token.newSyntaxError("Unexpected token %s", token.Kind))
-}
+func (self *_converter) convertStatementNode(statement ast.Statement) _node {
+	switch statement := statement.(type) {
 
-func (self *_parser) ConsumeIdentifier() *_identifierNode {
-	token := self.Next()
-	if token.Kind != "identifier" {
-		self.throwUnexpectedError(token) // panic
-	}
-	node := newIdentifierNode(token.Text)
-	self.markNode(node)
-	return node
-}
+	case *ast.BlockStatement:
+		node := newBlockNode()
+		node.Body = self.convertStatementList(statement.List)
+		return self.markNode(node, statement)
 
-func (self *_parser) ConsumeString() *_valueNode {
-	node := newStringNode(self.Next().Text)
-	self.markNode(node)
-	return node
-}
+	case *ast.BreakStatement:
+		target := ""
+		if statement.Label != nil {
+			target = statement.Label.Name
+		}
+		return self.markNode(newBreakNode(target), statement)
 
-func (self *_parser) ConsumeBoolean() *_valueNode {
-	node := newBooleanNode(self.Next().Text)
-	self.markNode(node)
-	return node
-}
+	case *ast.ContinueStatement:
+		target := ""
+		if statement.Label != nil {
+			target = statement.Label.Name
+		}
+		return self.markNode(newContinueNode(target), statement)
 
-func (self *_parser) ConsumeNumber() *_valueNode {
-	node := newNumberNode(self.Next().Text)
-	self.markNode(node)
-	return node
-}
+	case *ast.DebuggerStatement:
+		return self.markNode(newDebuggerNode(), statement)
 
-func (self *_parser) ConsumeSemicolon() {
+	case *ast.DoWhileStatement:
+		node := newDoWhileNode(self.convertExpression(statement.Test), self.convertIterationBody(statement.Body))
+		node.labelSet[""] = true
+		return self.markNode(node, statement)
 
-	if self.Accept(";") {
-		return
-	}
+	case *ast.EmptyStatement:
+		return self.markNode(newEmptyNode(), statement)
 
-	if self.Accept("\n") {
-		return
-	}
+	case *ast.ExpressionStatement:
+		return self.convertExpression(statement.Expression)
 
-	if self.Accept(";") {
-		return
-	}
+	case *ast.ForInStatement:
+		var into _node
+		if variable, ok := statement.Into.(*ast.VariableExpression); ok {
+			into = self.convertVariable(variable)
+		} else {
+			into = self.convertExpression(statement.Into)
+		}
+		node := newForInNode(into, self.convertExpression(statement.Source), self.convertIterationBody(statement.Body))
+		node.labelSet[""] = true
+		return self.markNode(node, statement)
+
+	case *ast.ForStatement:
+		var initial, test, update _node
+		switch initializer := statement.Initializer.(type) {
+		case nil:
+		case *ast.VariableStatement:
+			initial = self.convertStatementNode(initializer) // Part of the for statement
+		case ast.Expression:
+			initial = self.convertExpression(initializer)
+		}
+		if statement.Test != nil {
+			test = self.convertExpression(statement.Test)
+		}
+		if statement.Update != nil {
+			update = self.convertExpression(statement.Update)
+		}
+		node := newForNode(initial, test, update, self.convertIterationBody(statement.Body))
+		node.labelSet[""] = true
+		return self.markNode(node, statement)
+
+	case *ast.FunctionStatement:
+		// The function itself is hoisted (see convertDeclarationList)
+		return self.markNode(newEmptyNode(), statement)
+
+	case *ast.IfStatement:
+		node := newIfNode(self.convertExpression(statement.Test), self.convertStatement(statement.Consequent))
+		if statement.Alternate != nil {
+			node.Alternate = self.convertStatement(statement.Alternate)
+		}
+		self.markNode(node, statement)
+		node.block = self.branch(node, 2)
+		return node
+
+	case *ast.LabelledStatement:
+		node := self.convertStatement(statement.Statement)
+		var labelSet _labelSet
+		switch node := node.(type) {
+		case *_blockNode:
+			labelSet = node.labelSet
+		case *_doWhileNode:
+			labelSet = node.labelSet
+		case *_whileNode:
+			labelSet = node.labelSet
+		case *_switchNode:
+			labelSet = node.labelSet
+		case *_forNode:
+			labelSet = node.labelSet
+		case *_forInNode:
+			labelSet = node.labelSet
+		}
+		if labelSet != nil {
+			labelSet[statement.Label.Name] = true
+		}
+		return node
 
-	if !self.Match("EOF") && !self.Match("}") {
-		panic(self.Unexpected(self.Peek()))
-	}
+	case *ast.ReturnStatement:
+		node := newReturnNode()
+		if statement.Argument != nil {
+			node.Argument = self.convertExpression(statement.Argument)
+		}
+		return self.markNode(node, statement)
+
+	case *ast.SwitchStatement:
+		node := newSwitchNode(self.convertExpression(statement.Discriminant))
+		node.Default = statement.Default
+		for _, clause := range statement.Body {
+			var caseNode *_caseNode
+			if clause.Test == nil {
+				caseNode = newDefaultCaseNode()
+			} else {
+				caseNode = newCaseNode(self.convertExpression(clause.Test))
+			}
+			caseNode.Body = self.convertStatementList(clause.Consequent)
+			self.markNode(caseNode, clause)
+			node.AddCase(caseNode)
+		}
+		node.labelSet[""] = true
+		self.markNode(node, statement)
+		branchCount := len(node.CaseList)
+		if node.Default == -1 {
+			branchCount += 1 // No match
+		}
+		node.block = self.branch(node, branchCount)
+		return node
+
+	case *ast.ThrowStatement:
+		return self.markNode(newThrowNode(self.convertExpression(statement.Argument)), statement)
+
+	case *ast.TryStatement:
+		node := newTryCatchNode(self.convertStatement(statement.Body))
+		if statement.Catch != nil {
+			body := self.convertStatement(statement.Catch.Body).(*_blockNode)
+			node.Catch = newCatchNode(statement.Catch.Parameter.Name, body)
+			self.markNode(node.Catch, statement.Catch)
+		}
+		if statement.Finally != nil {
+			node.Finally = self.convertStatement(statement.Finally).(*_blockNode)
+		}
+		return self.markNode(node, statement)
 
-	return
-}
+	case *ast.VariableStatement:
+		node := newVariableDeclarationListNode()
+		for _, variable := range statement.List {
+			node.VariableList = append(node.VariableList, self.convertVariable(variable))
+		}
+		return self.markNode(node, statement)
+
+	case *ast.WhileStatement:
+		node := newWhileNode(self.convertExpression(statement.Test), self.convertIterationBody(statement.Body))
+		node.labelSet[""] = true
+		return self.markNode(node, statement)
+
+	case *ast.WithStatement:
+		return self.markNode(newWithNode(self.convertExpression(statement.Object), self.convertStatement(statement.Body)), statement)
 
-func (self *_parser) ScanRegularExpression() _token {
-	token := self.lexer.ScanRegularExpression()
-	self.history = append(self.history, token)
-	if len(self.history) > 4 {
-		self.history = self.history[len(self.history)-4:]
 	}
-	return token
+
+	panic(hereBeDragons("%T", statement))
 }
 
-func (self *_parser) Next() _token {
-	token := self.lexer.Scan()
-	self.history = append(self.history, token)
-	if len(self.history) > 4 {
-		self.history = self.history[len(self.history)-4:]
+func (self *_converter) convertVariable(variable *ast.VariableExpression) *_variableDeclarationNode {
+	node := newVariableDeclarationNode(variable.Name)
+	if variable.Initializer != nil {
+		node.Operator = "="
+		node.Initializer = self.convertExpression(variable.Initializer)
 	}
-	return token
+	self.markNode(node, variable)
+	return node
 }
 
-func (self *_parser) History(index int) _token {
-	if 0 > index {
-		index = len(self.history) + index
+func (self *_converter) convertExpressionList(list []ast.Expression) []_node {
+	result := []_node{}
+	for _, expression := range list {
+		result = append(result, self.convertExpression(expression))
 	}
-	if index >= len(self.history) {
-		panic(fmt.Errorf("Index %d is out of range for history (%d)", index, len(self.history)))
-	}
-	return self.history[index]
+	return result
 }
 
-func (self *_parser) PeekLineSkip() bool {
-	return self.lexer.Copy().ScanLineSkip()
-}
+func (self *_converter) convertExpression(expression ast.Expression) _node {
+	switch expression := expression.(type) {
 
-func (self *_parser) Peek() _token {
-	return self.lexer.Copy().Scan()
-}
+	case *ast.ArrayLiteral:
+		return self.markNode(newArrayNode(self.convertExpressionList(expression.Value)), expression)
 
-func (self *_parser) Parse() *_programNode {
-	self.EnterScope()
-	defer self.LeaveScope()
+	case *ast.AssignExpression:
+		return self.markNode(newAssignmentNode(expression.Operator, self.convertExpression(expression.Left), self.convertExpression(expression.Right)), expression)
 
-	node := newProgramNode()
-	node.Body = self.parseStatementUntil(func() bool {
-		return self.Match("EOF")
-	})
-	node.VariableList = self.Scope().VariableList
-	node.FunctionList = self.Scope().FunctionList
+	case *ast.BinaryExpression:
+		left := self.convertExpression(expression.Left)
+		right := self.convertExpression(expression.Right)
+		switch expression.Operator {
+		case "<", ">", "<=", ">=", "==", "!=", "===", "!==":
+			return self.markNode(newComparisonNode(expression.Operator, left, right), expression)
+		}
+		node := newBinaryOperationNode(expression.Operator, left, right)
+		self.markNode(node, expression)
+		switch expression.Operator {
+		case "&&", "||":
+			node.block = self.branch(node, 2)
+		}
+		return node
 
-	return node
-}
+	case *ast.BooleanLiteral:
+		text := "false"
+		if expression.Value {
+			text = "true"
+		}
+		return self.markNode(newBooleanNode(text), expression)
 
-func (self *_parser) ParseAsFunction() *_programNode {
-	self.EnterScope()
-	defer self.LeaveScope()
-	self.Scope().InFunction = true
+	case *ast.BracketExpression:
+		return self.markNode(newBracketMemberNode(self.convertExpression(expression.Left), self.convertExpression(expression.Member)), expression)
 
-	node := newProgramNode()
-	node.Body = self.parseStatementUntil(func() bool {
-		return self.Match("EOF")
-	})
-	node.VariableList = self.Scope().VariableList
-	node.FunctionList = self.Scope().FunctionList
+	case *ast.CallExpression:
+		node := newCallNode(self.convertExpression(expression.Callee))
+		if expression.ArgumentList != nil {
+			node.ArgumentList = self.convertExpressionList(expression.ArgumentList)
+		}
+		return self.markNode(node, expression)
 
-	return node
-}
+	case *ast.ConditionalExpression:
+		node := newConditionalNode(self.convertExpression(expression.Test), self.convertExpression(expression.Consequent), self.convertExpression(expression.Alternate))
+		self.markNode(node, expression)
+		node.block = self.branch(node, 2)
+		return node
+
+	case *ast.DotExpression:
+		return self.markNode(newDotMemberNode(self.convertExpression(expression.Left), expression.Member), expression)
+
+	case *ast.EmptyExpression:
+		return self.markNode(newEmptyNode(), expression)
+
+	case *ast.FunctionLiteral:
+		return self.convertFunction(expression, false)
+
+	case *ast.Identifier:
+		return self.markNode(newIdentifierNode(expression.Name), expression)
+
+	case *ast.NewExpression:
+		node := newNewNode(self.convertExpression(expression.Callee))
+		if expression.ArgumentList != nil {
+			node.ArgumentList = self.convertExpressionList(expression.ArgumentList)
+		}
+		return self.markNode(node, expression)
+
+	case *ast.NullLiteral:
+		return self.markNode(newNullNode("null"), expression)
+
+	case *ast.NumberLiteral:
+		return self.markNode(newNumberNode(expression.Literal), expression)
+
+	case *ast.ObjectLiteral:
+		node := newObjectNode()
+		for _, property := range expression.Value {
+			propertyNode := newObjectPropertyNode(self.convertPropertyKey(property.Key), self.convertExpression(property.Value))
+			propertyNode.Kind = property.Kind
+			self.markNode(propertyNode, property)
+			node.AddProperty(propertyNode)
+		}
+		return self.markNode(node, expression)
+
+	case *ast.RegExpLiteral:
+		{
+			// Test during parsing that this is a valid regular expression
+			_, err := compileRegExp(expression.Pattern, strings.Contains(expression.Flags, "i"), strings.Contains(expression.Flags, "m"))
+			if err != nil {
+				panic(&_syntaxError{
+					Message:  "Invalid regular expression: " + err.Error(),
+					Filename: self.filename,
+					Line:     expression.Line,
+					Column:   expression.Column,
+				})
+			}
+		}
+		return self.markNode(newRegExpNode(expression.Pattern, expression.Flags), expression)
+
+	case *ast.SequenceExpression:
+		return self.markNode(newCommaNode(self.convertExpressionList(expression.Sequence)), expression)
+
+	case *ast.StringLiteral:
+		return self.markNode(newStringNode(expression.Value), expression)
+
+	case *ast.ThisExpression:
+		return self.markNode(newThisNode(), expression)
+
+	case *ast.UnaryExpression:
+		operator := expression.Operator
+		switch operator {
+		case "++", "--":
+			if expression.Postfix {
+				operator = "=" + operator // =++ =--
+			} else {
+				operator = operator + "=" // ++= --=
+			}
+		}
+		return self.markNode(newUnaryOperationNode(operator, self.convertExpression(expression.Operand)), expression)
+
+	case *ast.VariableExpression:
+		return self.convertVariable(expression)
 
-func (self *_parser) Unexpected(token _token) *_syntaxError {
-	switch token.Kind {
-	case "EOF":
-		return self.History(-1).newSyntaxError("Unexpected end of input")
-	case "illegal":
-		return token.newSyntaxError("Unexpected token ILLEGAL (%s)", token.Text)
 	}
-	return token.newSyntaxError("Unexpected token %s", token.Text)
-}
 
-func (self *_parser) markNode(node _node) {
-	node.setPosition(self.lexer.lineCount)
+	panic(hereBeDragons("%T", expression))
 }
 
-func isIdentifierName(token _token) bool {
-	switch token.Kind {
-	case "identifier", "boolean":
-		return true
+func (self *_converter) convertPropertyKey(key ast.Expression) string {
+	switch key := key.(type) {
+	case *ast.Identifier:
+		return key.Name
+	case *ast.StringLiteral:
+		return key.Value
+	case *ast.NumberLiteral:
+		return toString(toValue_float64(stringToFloat(key.Literal)))
 	}
-	return keywordTable[token.Kind]
+	panic(hereBeDragons("%T", key))
 }
@@ -0,0 +1,584 @@
+package parser
+
+import (
+	"github.com/robertkrimen/otto/ast"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func (self *_parser) ParsePrimaryExpression() ast.Expression {
+	token := self.Peek()
+	switch token.Kind {
+	case "identifier":
+		return self.ConsumeIdentifier()
+	case "string":
+		return self.ConsumeString()
+	case "boolean":
+		return self.ConsumeBoolean()
+	case "number":
+		return self.ConsumeNumber()
+	case "null":
+		return self.ConsumeNull()
+	case "function":
+		return self.ParseFunction(false)
+	case "this":
+		return &ast.ThisExpression{
+			Position: self.position(self.Next()),
+		}
+	case "{":
+		return self.ParseObjectLiteral()
+	case "[":
+		return self.ParseArrayLiteral()
+	case "(":
+		self.Expect("(")
+		result := self.ParseExpression()
+		self.Expect(")")
+		return result
+	case "/", "/=": // Here, "/" & "/=" actually indicate
+		// the beginning of a regular expression
+		return self.ParseRegExpLiteral(token)
+	}
+
+	panic(self.Unexpected(token))
+}
+
+func (self *_parser) ParseObjectPropertyKey() ast.Expression {
+	if self.Match("identifier") {
+		return self.ConsumeIdentifier()
+	} else if self.Match("number") {
+		return self.ConsumeNumber()
+	} else if self.Match("string") {
+		return self.ConsumeString()
+	}
+	token := self.Next()
+	if !isIdentifierName(token) {
+		panic(self.Unexpected(token))
+	}
+	return &ast.Identifier{
+		Position: self.position(token),
+		Name:     token.Text,
+	}
+}
+
+func (self *_parser) ParseObjectProperty() *ast.Property {
+
+	key := self.ParseObjectPropertyKey()
+	if identifier, ok := key.(*ast.Identifier); ok && !self.Match(":") {
+		switch identifier.Name {
+		case "get", "set":
+			return self.ParseObjectAccessor(identifier)
+		}
+	}
+	self.Expect(":")
+	value := self.ParseAssignmentExpression()
+
+	return &ast.Property{
+		Position: key.Pos(),
+		Key:      key,
+		Kind:     "value",
+		Value:    value,
+	}
+}
+
+// ParseObjectAccessor parses the rest of a getter (get name() {...}) or a
+// setter (set name(value) {...}) of an object literal, after the get or set.
+func (self *_parser) ParseObjectAccessor(kind *ast.Identifier) *ast.Property {
+
+	key := self.ParseObjectPropertyKey()
+	token := self.Peek()
+	function := &ast.FunctionLiteral{
+		Position: self.position(token),
+	}
+	self.ParseFunctionRest(function)
+
+	if kind.Name == "get" && len(function.ParameterList) != 0 {
+		panic(token.newSyntaxError("Getter must not have any formal parameters."))
+	}
+	if kind.Name == "set" && len(function.ParameterList) != 1 {
+		panic(token.newSyntaxError("Setter must have exactly one formal parameter."))
+	}
+
+	return &ast.Property{
+		Position: kind.Pos(),
+		Key:      key,
+		Kind:     kind.Name,
+		Value:    function,
+	}
+}
+
+func (self *_parser) ParseRegExpLiteral(token _token) *ast.RegExpLiteral {
+
+	pattern := self.ScanRegularExpression().Text
+
+	flags := ""
+	if self.Match("identifier") { // gim
+		flags = self.Consume()
+	}
+
+	return &ast.RegExpLiteral{
+		Position: self.position(token),
+		Pattern:  pattern,
+		Flags:    flags,
+	}
+}
+
+func (self *_parser) ParseObjectLiteral() *ast.ObjectLiteral {
+
+	node := &ast.ObjectLiteral{
+		Position: self.position(self.Expect("{")),
+	}
+
+	kind := map[string]string{} // The kind of each key so far: value, get, set, or accessor (get and set)
+	for !self.Match("}") {
+		property := self.ParseObjectProperty()
+		self.checkObjectProperty(kind, property)
+		node.Value = append(node.Value, property)
+
+		if self.Accept(",") {
+			continue
+		}
+	}
+	self.Expect("}")
+
+	return node
+}
+
+// checkObjectProperty checks that the property of an object literal does not
+// conflict with an earlier property of the same name (11.1.5), given the kind of
+// each key so far, which it updates.
+func (self *_parser) checkObjectProperty(kind map[string]string, property *ast.Property) {
+	name := propertyKeyName(property.Key)
+	previous, exists := kind[name]
+	kind[name] = property.Kind
+	if !exists {
+		return
+	}
+	switch {
+	case previous == "value" && property.Kind == "value":
+		if self.Scope().Strict {
+			panic(newSyntaxErrorAt(property.Key.Pos(), "Duplicate data property in object literal not allowed in strict mode"))
+		}
+	case previous == "value" || property.Kind == "value":
+		panic(newSyntaxErrorAt(property.Key.Pos(), "Object literal may not have data and accessor property with the same name"))
+	case previous == property.Kind || previous == "accessor":
+		panic(newSyntaxErrorAt(property.Key.Pos(), "Object literal may not have multiple get/set accessors with the same name"))
+	default:
+		kind[name] = "accessor"
+	}
+}
+
+// propertyKeyName returns the name of the property with the key (an identifier,
+// string, or number) of an object literal.
+func propertyKeyName(key ast.Expression) string {
+	switch key := key.(type) {
+	case *ast.Identifier:
+		return key.Name
+	case *ast.StringLiteral:
+		return key.Value
+	case *ast.NumberLiteral:
+		var value float64
+		if literal := strings.ToLower(key.Literal); strings.HasPrefix(literal, "0x") {
+			integer, err := strconv.ParseUint(literal[2:], 16, 64)
+			if err != nil {
+				return key.Literal
+			}
+			value = float64(integer)
+		} else {
+			var err error
+			value, err = strconv.ParseFloat(literal, 64)
+			if err != nil {
+				return key.Literal
+			}
+		}
+		// As ToString (9.8.1), for the usual numbers
+		if magnitude := math.Abs(value); magnitude == 0 || 1e-6 <= magnitude && magnitude < 1e21 {
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		return strings.NewReplacer("e-0", "e-", "e+0", "e+").Replace(strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	return ""
+}
+
+func (self *_parser) ParseArrayValue() ast.Expression {
+	return self.ParseAssignmentExpression()
+}
+
+func (self *_parser) ParseArrayLiteral() *ast.ArrayLiteral {
+
+	node := &ast.ArrayLiteral{
+		Position: self.position(self.Expect("[")),
+		Value:    []ast.Expression{},
+	}
+	for !self.Match("]") {
+		if self.Match(",") {
+			node.Value = append(node.Value, &ast.EmptyExpression{
+				Position: self.position(self.Next()),
+			})
+			continue
+		}
+		node.Value = append(node.Value, self.ParseArrayValue())
+		if !self.Match("]") {
+			self.Expect(",")
+		}
+	}
+	self.Expect("]")
+
+	return node
+}
+
+func (self *_parser) ParseArgumentList() (argumentList []ast.Expression) {
+	self.Expect("(")
+	if !self.Match(")") {
+		argumentList = make([]ast.Expression, 0)
+		for {
+			argumentList = append(argumentList, self.ParseAssignmentExpression())
+			if !self.Accept(",") {
+				break
+			}
+		}
+	}
+	self.Expect(")")
+	return argumentList
+}
+
+func (self *_parser) ParseCallExpression(left ast.Expression) ast.Expression {
+	return &ast.CallExpression{
+		Position:     left.Pos(),
+		Callee:       left,
+		ArgumentList: self.ParseArgumentList(),
+	}
+}
+
+func (self *_parser) ParseDotMember(left ast.Expression) ast.Expression {
+	self.Expect(".")
+	token := self.Next()
+	if !isIdentifierName(token) {
+		panic(token.newSyntaxError("Unexpected token %s", token.Kind))
+	}
+	return &ast.DotExpression{
+		Position: left.Pos(),
+		Left:     left,
+		Member:   token.Text,
+	}
+}
+
+func (self *_parser) ParseBracketMember(left ast.Expression) ast.Expression {
+	self.Expect("[")
+	member := self.ParseExpression()
+	self.Expect("]")
+	return &ast.BracketExpression{
+		Position: left.Pos(),
+		Left:     left,
+		Member:   member,
+	}
+}
+
+func (self *_parser) ParseNewExpression() ast.Expression {
+	node := &ast.NewExpression{
+		Position: self.position(self.Expect("new")),
+	}
+	node.Callee = self.ParseLeftHandSideExpression()
+	if self.Match("(") {
+		node.ArgumentList = self.ParseArgumentList()
+	}
+	return node
+}
+
+func (self *_parser) ParseLeftHandSideExpression() ast.Expression {
+
+	var left ast.Expression
+	if self.Match("new") {
+		left = self.ParseNewExpression()
+	} else {
+		left = self.ParsePrimaryExpression()
+	}
+
+	for {
+		if self.Match(".") {
+			left = self.ParseDotMember(left)
+		} else if self.Match("[") {
+			left = self.ParseBracketMember(left)
+		} else {
+			break
+		}
+	}
+
+	return left
+}
+
+func (self *_parser) ParseLeftHandSideExpressionAllowCall() ast.Expression {
+
+	var left ast.Expression
+	if self.Match("new") {
+		left = self.ParseNewExpression()
+	} else {
+		left = self.ParsePrimaryExpression()
+	}
+
+	for {
+		if self.Match(".") {
+			left = self.ParseDotMember(left)
+		} else if self.Match("[") {
+			left = self.ParseBracketMember(left)
+		} else if self.Match("(") {
+			left = self.ParseCallExpression(left)
+		} else {
+			break
+		}
+	}
+
+	return left
+}
+
+// isAssignable returns true if the expression can appear on the left-hand side of an assignment
+func isAssignable(expression ast.Expression) bool {
+	switch expression.(type) {
+	case *ast.Identifier, *ast.DotExpression, *ast.BracketExpression:
+		return true
+	}
+	return false
+}
+
+func (self *_parser) ParsePostfixExpression() ast.Expression {
+	left := self.ParseLeftHandSideExpressionAllowCall()
+
+	// TODO Need better syntax checking here
+	// Strictness checking, etc.
+
+	switch token := self.Peek(); token.Kind {
+	case "++", "--": // Postfix, either =++ or =--
+		if self.Match("\n") { // TODO Why?
+			break
+		}
+		if !isAssignable(left) {
+			panic(self.History(-1).newSyntaxError("Invalid left-hand side in assignment"))
+		}
+		self.checkStrictAssignment(left)
+		return &ast.UnaryExpression{
+			Position: left.Pos(),
+			Operator: self.Consume(),
+			Operand:  left,
+			Postfix:  true,
+		}
+	}
+
+	return left
+}
+
+func (self *_parser) ParseUnaryExpression() ast.Expression {
+
+	// TODO Need better syntax checking here
+	// Strictness checking, basically (trying to delete a non-reference, etc.)
+
+	switch token := self.Peek(); token.Kind {
+	case "+", "-", "!", "~", "delete", "void", "typeof":
+		self.Next()
+		operand := self.ParseUnaryExpression()
+		if _, ok := operand.(*ast.Identifier); ok && token.Kind == "delete" && self.Scope().Strict {
+			panic(token.newSyntaxError("Delete of an unqualified identifier in strict mode."))
+		}
+		return &ast.UnaryExpression{
+			Position: self.position(token),
+			Operator: token.Kind,
+			Operand:  operand,
+		}
+	case "++", "--": // Prefix, either ++= or --=
+		self.Next()
+		operand := self.ParseUnaryExpression()
+		if !isAssignable(operand) {
+			panic(self.History(-1).newSyntaxError("Invalid left-hand side in assignment"))
+		}
+		self.checkStrictAssignment(operand)
+		return &ast.UnaryExpression{
+			Position: self.position(token),
+			Operator: token.Kind,
+			Operand:  operand,
+		}
+	}
+
+	return self.ParsePostfixExpression()
+}
+
+func (self *_parser) newBinaryExpression(operator string, left ast.Expression, right ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Position: left.Pos(),
+		Operator: operator,
+		Left:     left,
+		Right:    right,
+	}
+}
+
+func (self *_parser) ParseMultiplicativeExpression() ast.Expression {
+	left := self.ParseUnaryExpression()
+
+REPEAT:
+	switch self.Peek().Kind {
+	case "*", "/", "%":
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseUnaryExpression())
+		goto REPEAT
+	}
+
+	return left
+}
+
+func (self *_parser) ParseAdditiveExpression() ast.Expression {
+	left := self.ParseMultiplicativeExpression()
+
+REPEAT:
+	switch self.Peek().Kind {
+	case "+", "-":
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseMultiplicativeExpression())
+		goto REPEAT
+	}
+
+	return left
+}
+
+func (self *_parser) ParseShiftExpression() ast.Expression {
+	left := self.ParseAdditiveExpression()
+
+REPEAT:
+	switch self.Peek().Kind {
+	case "<<", ">>", ">>>":
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseAdditiveExpression())
+		goto REPEAT
+	}
+	return left
+}
+
+func (self *_parser) ParseRelationalExpression() ast.Expression {
+	previousAllowIn := self.Scope().AllowIn
+	self.Scope().AllowIn = true
+	left := self.ParseShiftExpression()
+	self.Scope().AllowIn = previousAllowIn // TODO This should be deferred
+
+	switch self.Peek().Kind {
+	case "<", ">", "<=", ">=", "instanceof":
+		return self.newBinaryExpression(self.Consume(), left, self.ParseRelationalExpression())
+	case "in":
+		if !self.Scope().AllowIn {
+			return left
+		}
+		return self.newBinaryExpression(self.Consume(), left, self.ParseRelationalExpression())
+	}
+
+	return left
+}
+
+func (self *_parser) ParseEqualityExpression() ast.Expression {
+	left := self.ParseRelationalExpression()
+
+REPEAT:
+	switch self.Peek().Kind {
+	case "==", "!=", "===", "!==":
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseRelationalExpression())
+		goto REPEAT
+	}
+
+	return left
+}
+
+func (self *_parser) ParseBitwiseANDExpression() ast.Expression {
+	left := self.ParseEqualityExpression()
+
+	for self.Match("&") {
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseEqualityExpression())
+	}
+
+	return left
+}
+
+func (self *_parser) ParseBitwiseXORExpression() ast.Expression {
+	left := self.ParseBitwiseANDExpression()
+
+	for self.Match("^") {
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseBitwiseANDExpression())
+	}
+
+	return left
+}
+
+func (self *_parser) ParseBitwiseORExpression() ast.Expression {
+	left := self.ParseBitwiseXORExpression()
+
+	for self.Match("|") {
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseBitwiseXORExpression())
+	}
+
+	return left
+}
+
+func (self *_parser) ParseLogicalANDExpression() ast.Expression {
+	left := self.ParseBitwiseORExpression()
+
+	for self.Match("&&") {
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseBitwiseORExpression())
+	}
+
+	return left
+}
+
+func (self *_parser) ParseLogicalORExpression() ast.Expression {
+	left := self.ParseLogicalANDExpression()
+
+	for self.Match("||") {
+		left = self.newBinaryExpression(self.Consume(), left, self.ParseLogicalANDExpression())
+	}
+
+	return left
+}
+
+func (self *_parser) ParseConditionlExpression() ast.Expression {
+	left := self.ParseLogicalORExpression()
+
+	if self.Accept("?") {
+		consequent := self.ParseAssignmentExpression()
+		self.Expect(":")
+		return &ast.ConditionalExpression{
+			Position:   left.Pos(),
+			Test:       left,
+			Consequent: consequent,
+			Alternate:  self.ParseAssignmentExpression(),
+		}
+	}
+
+	return left
+}
+
+func (self *_parser) ParseAssignmentExpression() ast.Expression {
+	left := self.ParseConditionlExpression()
+	if self.matchAssignment() {
+		if !isAssignable(left) {
+			panic(newReferenceErrorAt(left.Pos(), "Invalid left-hand side in assignment"))
+		}
+		self.checkStrictAssignment(left)
+		return &ast.AssignExpression{
+			Position: left.Pos(),
+			Operator: self.Consume(),
+			Left:     left,
+			Right:    self.ParseAssignmentExpression(),
+		}
+	}
+	return left
+}
+
+func (self *_parser) ParseExpression() ast.Expression {
+	left := self.ParseAssignmentExpression()
+
+	if self.Match(",") {
+		node := &ast.SequenceExpression{
+			Position: left.Pos(),
+			Sequence: []ast.Expression{left},
+		}
+		for {
+			if !self.Accept(",") {
+				break
+			}
+			node.Sequence = append(node.Sequence, self.ParseAssignmentExpression())
+		}
+		return node
+	}
+
+	return left
+}
@@ -1,4 +1,4 @@
-package otto
+package parser
 
 import (
 	"bytes"
@@ -84,7 +84,9 @@ func init() {
 
 type _token struct {
 	Line, Column, Character int
+	StartColumn             int // Column of the first character of the token
 	Kind, File, Text        string
+	Literal                 string // The source of the token (e.g. a string with its quotes and escapes)
 	Error                   bool
 }
 
@@ -157,14 +159,13 @@ func (self *_lexer) ScanBlockComment() int {
 			self.next() // /
 			return lineCount
 		case chr == endOfFile:
-			panic(&_syntaxError{
+			panic(&Error{
 				Message: "Unexpected token ILLEGAL",
 			})
 		case self.scanEndOfLine(chr, false):
 			lineCount += 1
 		}
 	}
-	panic(hereBeDragons())
 }
 
 func (self *_lexer) ScanSkip() int {
@@ -501,9 +502,12 @@ func (self *_lexer) emitWith(kind string, text string) _token {
 		Line:      1 + self.lineCount,
 		Column:    1 + self.tailOffset - self.zeroColumnOffset,
 
-		Kind:  kind,
-		Text:  text,
-		Error: false,
+		StartColumn: 1 + self.headOffset - self.zeroColumnOffset,
+
+		Kind:    kind,
+		Text:    text,
+		Literal: self.word(),
+		Error:   false,
 	}
 	if kind == "punctuator" {
 		token.Kind = token.Text
@@ -512,7 +516,7 @@ func (self *_lexer) emitWith(kind string, text string) _token {
 	self.headOffset = self.tailOffset
 	self.head = self.tail
 
-	if ottoDebug {
+	if parserDebug {
 		fmt.Printf("emit: %s %s\n", token.Kind, token.Text)
 	}
 	if kind == "illegal" {
@@ -1,7 +1,7 @@
-package otto
+package parser
 
 import (
-	. "./terst"
+	. "../terst"
 	"fmt"
 	"strings"
 	"testing"
@@ -0,0 +1,483 @@
+/*
+Package parser implements a parser for JavaScript.
+
+	import (
+		"github.com/robertkrimen/otto/parser"
+	)
+
+Parse and return an AST
+
+	filename := "" // A filename is optional
+	src := `
+		// Sample xyzzy example
+		(function(){
+			if (3.14159 > 0) {
+				console.log("Hello, World.");
+				return;
+			}
+
+			var xyzzy = NaN;
+			console.log("Nothing happens.");
+			return xyzzy;
+		})();
+	`
+
+	// Parse some JavaScript, yielding a *ast.Program and/or an error
+	program, err := parser.ParseFile(filename, src)
+
+# Warning
+
+The parser and AST interfaces are still works-in-progress (particularly where
+node types are concerned) and may change in the future.
+*/
+package parser
+
+import (
+	"fmt"
+	"github.com/robertkrimen/otto/ast"
+	"strings"
+)
+
+const parserDebug = false
+
+const endOfFile = -1
+
+// ParseFile parses the source of a single JavaScript program, returning the
+// corresponding *ast.Program.
+//
+// If the source is not valid, then the returned error will be an *Error
+// describing the problem (and where it occurred).
+func ParseFile(filename string, src string) (*ast.Program, error) {
+	var program *ast.Program
+	err := catchError(filename, func() {
+		parser := newParser()
+		parser.lexer.Source = src
+		program = parser.Parse()
+		program.Filename = filename
+	})
+	if err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// ParseFunction parses the given body as the body of a function (so that,
+// for example, return is permitted), returning a function literal with the
+// given parameters.
+//
+// This is used to implement the Function constructor: new Function("abc", "def", "return abc + def")
+func ParseFunction(parameterList []string, body string) (*ast.FunctionLiteral, error) {
+	var function *ast.FunctionLiteral
+	err := catchError("", func() {
+		function = &ast.FunctionLiteral{}
+		for _, name := range parameterList {
+			if !isIdentifier(name) || keywordTable[name] {
+				panic(&Error{
+					Name:    "SyntaxError",
+					Message: name,
+				})
+			}
+			function.ParameterList = append(function.ParameterList, &ast.Identifier{
+				Name: name,
+			})
+		}
+		parser := newParser()
+		parser.lexer.Source = body
+		program := parser.ParseAsFunction()
+		function.Body = &ast.BlockStatement{
+			Position: program.Position,
+			List:     program.Body,
+		}
+		function.DeclarationList = program.DeclarationList
+		function.Strict = program.Strict
+		if function.Strict {
+			parser.checkStrictParameterList(function)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return function, nil
+}
+
+func catchError(filename string, parse func()) (err error) {
+	defer func() {
+		if caught := recover(); caught != nil {
+			if caught, ok := caught.(*Error); ok {
+				caught.Filename = filename
+				err = caught
+				return
+			}
+			panic(caught)
+		}
+	}()
+	parse()
+	return nil
+}
+
+// Error is the error returned by ParseFile (or ParseFunction) when the source
+// is not valid JavaScript.
+type Error struct {
+	Name     string // SyntaxError, or ReferenceError (for an invalid left-hand side in assignment)
+	Message  string
+	Filename string
+
+	// Line, Column, and Character (offset) are 1-based, but will be 0 if the
+	// position is not known
+	Line      int
+	Column    int
+	Character int
+}
+
+func (self Error) String() string {
+	name := self.Name
+	if name == "" {
+		name = "SyntaxError"
+	}
+	if len(self.Message) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s: %s", name, self.Message)
+}
+
+func (self Error) Error() string {
+	switch {
+	case self.Line == 0:
+		return self.String()
+	case self.Filename == "":
+		return fmt.Sprintf("%s (line %d)", self.String(), self.Line)
+	}
+	return fmt.Sprintf("%s (%s: line %d)", self.String(), self.Filename, self.Line)
+}
+
+func (self _token) newSyntaxError(description string, argumentList ...interface{}) *Error {
+	return &Error{
+		Name:      "SyntaxError",
+		Message:   fmt.Sprintf(description, argumentList...),
+		Line:      self.Line,
+		Column:    self.Column,
+		Character: self.Character,
+	}
+}
+
+// newSyntaxErrorAt returns a SyntaxError at the position (of a node, rather than a token).
+func newSyntaxErrorAt(position ast.Position, description string, argumentList ...interface{}) *Error {
+	return &Error{
+		Name:    "SyntaxError",
+		Message: fmt.Sprintf(description, argumentList...),
+		Line:    position.Line,
+		Column:  position.Column,
+	}
+}
+
+// newReferenceErrorAt returns a ReferenceError at the position (of a node).
+func newReferenceErrorAt(position ast.Position, description string, argumentList ...interface{}) *Error {
+	return &Error{
+		Name:    "ReferenceError",
+		Message: fmt.Sprintf(description, argumentList...),
+		Line:    position.Line,
+		Column:  position.Column,
+	}
+}
+
+type _parser struct {
+	lexer   _lexer
+	Stack   [](*_sourceScope)
+	history []_token
+}
+
+func newParser() *_parser {
+	self := &_parser{
+		history: make([]_token, 0, 4),
+	}
+	self.lexer.readIn = make([]rune, 0)
+	return self
+}
+
+func (self *_parser) Consume() string {
+	return self.Next().Text
+}
+
+type _sourceScope struct {
+	DeclarationList []ast.Declaration
+	labelSet        map[string]bool
+	AllowIn         bool
+	InFunction      bool
+	InSwitch        bool
+	InIteration     bool
+	Strict          bool // Within strict mode code
+}
+
+func (self *_sourceScope) Declare(declaration ast.Declaration) {
+	self.DeclarationList = append(self.DeclarationList, declaration)
+}
+
+func newSourceScope() *_sourceScope {
+	self := &_sourceScope{
+		labelSet: map[string]bool{},
+		AllowIn:  true,
+	}
+	return self
+}
+
+func (self *_sourceScope) HasLabel(name string) bool {
+	_, exists := self.labelSet[name]
+	return exists
+}
+
+func (self *_parser) EnterScope() {
+	scope := newSourceScope()
+	if len(self.Stack) > 0 {
+		scope.Strict = self.Scope().Strict // Strictness is inherited
+	}
+	self.Stack = append(self.Stack, scope)
+}
+
+func (self *_parser) LeaveScope() {
+	self.Stack = self.Stack[:len(self.Stack)-1]
+}
+
+func (self *_parser) Scope() *_sourceScope {
+	return self.Stack[len(self.Stack)-1]
+}
+
+func (self *_parser) Accept(kind string) bool {
+	if kind == "\n" {
+		// This is a PeekLineSkip, except we
+		// retain the adjusted lexer if we really
+		// skipped a line
+		lexerCopy := self.lexer.Copy()
+		didSkip := lexerCopy.ScanLineSkip()
+		if didSkip {
+			self.lexer = *lexerCopy
+		}
+		return didSkip
+	}
+	if self.Match(kind) {
+		self.Next()
+		return true
+	}
+	return false
+}
+
+func (self *_parser) Match(kind string) bool {
+	if kind == "\n" {
+		return self.PeekLineSkip()
+	}
+	return self.Peek().Kind == kind
+}
+
+func (self *_parser) Expect(kind string) _token {
+	token := self.Next()
+	if token.Kind != kind {
+		panic(self.Unexpected(token))
+	}
+	return token
+}
+
+var assignmentTable map[string]bool = map[string]bool{}
+
+func init() {
+	for _, value := range strings.Fields("= *= /= %= += -= <<= >>= >>>= &= ^= |=") {
+		assignmentTable[value] = true
+	}
+}
+
+func (self *_parser) matchAssignment() bool {
+	return assignmentTable[self.Peek().Kind]
+}
+
+func (self *_parser) position(token _token) ast.Position {
+	return ast.Position{
+		Line:   token.Line,
+		Column: token.StartColumn,
+	}
+}
+
+// peekPosition is the position of the next token (without consuming it)
+func (self *_parser) peekPosition() ast.Position {
+	return self.position(self.Peek())
+}
+
+func (self *_parser) ConsumeNull() *ast.NullLiteral {
+	return &ast.NullLiteral{
+		Position: self.position(self.Next()),
+	}
+}
+
+func (self *_parser) throwUnexpectedError(token _token) {
+	if futureKeywordTable[token.Kind] {
+		panic(token.newSyntaxError("Unexpected reserved word"))
+	}
+	panic(token.newSyntaxError("Unexpected token %s", token.Kind))
+}
+
+func (self *_parser) ConsumeIdentifier() *ast.Identifier {
+	token := self.Next()
+	if token.Kind != "identifier" {
+		self.throwUnexpectedError(token) // panic
+	}
+	return &ast.Identifier{
+		Position: self.position(token),
+		Name:     token.Text,
+	}
+}
+
+func (self *_parser) ConsumeString() *ast.StringLiteral {
+	token := self.Next()
+	return &ast.StringLiteral{
+		Position: self.position(token),
+		Literal:  token.Literal,
+		Value:    token.Text,
+	}
+}
+
+func (self *_parser) ConsumeBoolean() *ast.BooleanLiteral {
+	token := self.Next()
+	return &ast.BooleanLiteral{
+		Position: self.position(token),
+		Value:    token.Text == "true",
+	}
+}
+
+func (self *_parser) ConsumeNumber() *ast.NumberLiteral {
+	token := self.Next()
+	if self.Scope().Strict && len(token.Text) > 1 && token.Text[0] == '0' && '0' <= token.Text[1] && token.Text[1] <= '9' {
+		panic(token.newSyntaxError("Octal literals are not allowed in strict mode."))
+	}
+	return &ast.NumberLiteral{
+		Position: self.position(token),
+		Literal:  token.Text,
+	}
+}
+
+func (self *_parser) ConsumeSemicolon() {
+
+	if self.Accept(";") {
+		return
+	}
+
+	if self.Accept("\n") {
+		return
+	}
+
+	if self.Accept(";") {
+		return
+	}
+
+	if !self.Match("EOF") && !self.Match("}") {
+		panic(self.Unexpected(self.Peek()))
+	}
+
+	return
+}
+
+func (self *_parser) ScanRegularExpression() _token {
+	token := self.lexer.ScanRegularExpression()
+	self.history = append(self.history, token)
+	if len(self.history) > 4 {
+		self.history = self.history[len(self.history)-4:]
+	}
+	return token
+}
+
+func (self *_parser) Next() _token {
+	token := self.lexer.Scan()
+	self.history = append(self.history, token)
+	if len(self.history) > 4 {
+		self.history = self.history[len(self.history)-4:]
+	}
+	return token
+}
+
+func (self *_parser) History(index int) _token {
+	if 0 > index {
+		index = len(self.history) + index
+	}
+	if index >= len(self.history) {
+		panic(fmt.Errorf("Index %d is out of range for history (%d)", index, len(self.history)))
+	}
+	return self.history[index]
+}
+
+func (self *_parser) PeekLineSkip() bool {
+	return self.lexer.Copy().ScanLineSkip()
+}
+
+func (self *_parser) Peek() _token {
+	return self.lexer.Copy().Scan()
+}
+
+func (self *_parser) Parse() *ast.Program {
+	self.EnterScope()
+	defer self.LeaveScope()
+
+	node := &ast.Program{
+		Position: self.peekPosition(),
+	}
+	node.Body = self.parseSourceElements(func() bool {
+		return self.Match("EOF")
+	})
+	node.DeclarationList = self.Scope().DeclarationList
+	node.Strict = self.Scope().Strict
+
+	return node
+}
+
+func (self *_parser) ParseAsFunction() *ast.Program {
+	self.EnterScope()
+	defer self.LeaveScope()
+	self.Scope().InFunction = true
+
+	node := &ast.Program{
+		Position: self.peekPosition(),
+	}
+	node.Body = self.parseSourceElements(func() bool {
+		return self.Match("EOF")
+	})
+	node.DeclarationList = self.Scope().DeclarationList
+	node.Strict = self.Scope().Strict
+
+	return node
+}
+
+func (self *_parser) Unexpected(token _token) *Error {
+	switch token.Kind {
+	case "EOF":
+		return self.History(-1).newSyntaxError("Unexpected end of input")
+	case "illegal":
+		return token.newSyntaxError("Unexpected token ILLEGAL (%s)", token.Text)
+	}
+	return token.newSyntaxError("Unexpected token %s", token.Text)
+}
+
+func isIdentifierName(token _token) bool {
+	switch token.Kind {
+	case "identifier", "boolean":
+		return true
+	}
+	return keywordTable[token.Kind]
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for index, chr := range name {
+		if chr == '\\' {
+			return false
+		}
+		if index == 0 && !isIdentifierStart(chr) || !isIdentifierPart(chr) {
+			return false
+		}
+	}
+	return true
+}
+
+func boolFields(input string) (result map[string]bool) {
+	result = map[string]bool{}
+	for _, word := range strings.Fields(input) {
+		result[word] = true
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	. "../terst"
+	"github.com/robertkrimen/otto/ast"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	Terst(t)
+
+	program, err := ParseFile("xyzzy.js", `
+        var abc = 1 + 2;
+        function def(ghi) {
+            return ghi.jkl;
+        }
+        abc;
+    `)
+	Is(err, nil)
+	Is(program.Filename, "xyzzy.js")
+	Is(len(program.Body), 3)
+	Is(len(program.DeclarationList), 2)
+
+	{
+		statement := program.Body[0].(*ast.VariableStatement)
+		Is(statement.Pos(), ast.Position{Line: 2, Column: 9})
+		Is(statement.List[0].Name, "abc")
+		Is(statement.List[0].Pos(), ast.Position{Line: 2, Column: 13})
+		binary := statement.List[0].Initializer.(*ast.BinaryExpression)
+		Is(binary.Operator, "+")
+		Is(binary.Pos(), ast.Position{Line: 2, Column: 19})
+		Is(binary.Right.(*ast.NumberLiteral).Literal, "2")
+	}
+
+	{
+		function := program.Body[1].(*ast.FunctionStatement).Function
+		Is(function.Name.Name, "def")
+		Is(function.Pos(), ast.Position{Line: 3, Column: 9})
+		Is(function.ParameterList[0].Name, "ghi")
+		Is(function.ParameterList[0].Pos(), ast.Position{Line: 3, Column: 22})
+		statement := function.Body.List[0].(*ast.ReturnStatement)
+		Is(statement.Pos(), ast.Position{Line: 4, Column: 13})
+		dot := statement.Argument.(*ast.DotExpression)
+		Is(dot.Left.(*ast.Identifier).Name, "ghi")
+		Is(dot.Member, "jkl")
+
+		declaration := program.DeclarationList[1].(*ast.FunctionDeclaration)
+		Is(declaration.Function == function, true)
+	}
+
+	{
+		statement := program.Body[2].(*ast.ExpressionStatement)
+		Is(statement.Expression.(*ast.Identifier).Pos(), ast.Position{Line: 6, Column: 9})
+	}
+}
+
+func TestParseFileError(t *testing.T) {
+	Terst(t)
+
+	_, err := ParseFile("xyzzy.js", "abc = 1;\ndef = }")
+	Is(err, "SyntaxError: Unexpected token } (xyzzy.js: line 2)")
+	{
+		err := err.(*Error)
+		Is(err.Filename, "xyzzy.js")
+		Is(err.Line, 2)
+		Is(err.Column, 8)
+	}
+
+	_, err = ParseFile("", "1 = 2")
+	Is(err, "ReferenceError: Invalid left-hand side in assignment (line 1)")
+
+	_, err = ParseFile("xyzzy.js", "abc = 1;\n  def() = 2")
+	Is(err, "ReferenceError: Invalid left-hand side in assignment (xyzzy.js: line 2)")
+	Is(err.(*Error).Column, 3)
+
+	_, err = ParseFile("", "return")
+	Is(err, "SyntaxError: Illegal return statement (line 1)")
+}
+
+func TestParseFunction(t *testing.T) {
+	Terst(t)
+
+	function, err := ParseFunction([]string{"abc", "def"}, "return abc + def")
+	Is(err, nil)
+	Is(len(function.ParameterList), 2)
+	Is(function.ParameterList[1].Name, "def")
+	Is(len(function.Body.List), 1)
+
+	_, err = ParseFunction([]string{"abc", "if"}, "")
+	Is(err, "SyntaxError: if")
+}
+
+func TestParseDebugger(t *testing.T) {
+	Terst(t)
+
+	program, err := ParseFile("", "abc;\n  debugger\nabc")
+	Is(err, nil)
+	Is(len(program.Body), 3)
+	statement := program.Body[1].(*ast.DebuggerStatement)
+	Is(statement.Pos(), ast.Position{Line: 2, Column: 3})
+}
+
+func TestParseObjectAccessor(t *testing.T) {
+	Terst(t)
+
+	program, err := ParseFile("", "({ get: 1, get abc() {}, set abc(def) {} })")
+	Is(err, nil)
+	object := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ObjectLiteral)
+	Is(len(object.Value), 3)
+	Is(object.Value[0].Kind, "value")
+	Is(object.Value[0].Key.(*ast.Identifier).Name, "get")
+	Is(object.Value[1].Kind, "get")
+	Is(object.Value[1].Key.(*ast.Identifier).Name, "abc")
+	Is(object.Value[1].Pos(), ast.Position{Line: 1, Column: 12})
+	Is(object.Value[2].Kind, "set")
+	Is(object.Value[2].Value.(*ast.FunctionLiteral).ParameterList[0].Name, "def")
+
+	// A duplicate name (11.1.5)
+	_, err = ParseFile("", "({ abc: 1, abc: 2, 1: 3, '1': 4, get def() {}, set def(ghi) {} })")
+	Is(err, nil)
+
+	_, err = ParseFile("", "({ abc: 1, get abc() {} })")
+	Is(err, "SyntaxError: Object literal may not have data and accessor property with the same name (line 1)")
+
+	_, err = ParseFile("", "({ get abc() {}, 'abc': 1 })")
+	Is(err, "SyntaxError: Object literal may not have data and accessor property with the same name (line 1)")
+
+	_, err = ParseFile("", "({ get abc() {}, get abc() {} })")
+	Is(err, "SyntaxError: Object literal may not have multiple get/set accessors with the same name (line 1)")
+
+	_, err = ParseFile("", "({ get abc() {}, set abc(def) {}, set abc(def) {} })")
+	Is(err, "SyntaxError: Object literal may not have multiple get/set accessors with the same name (line 1)")
+
+	_, err = ParseFile("", "({ 1.0: 1, get 1() {} })")
+	Is(err, "SyntaxError: Object literal may not have data and accessor property with the same name (line 1)")
+
+	_, err = ParseFile("", "({ 0x10: 1, get '16'() {} })")
+	Is(err, "SyntaxError: Object literal may not have data and accessor property with the same name (line 1)")
+
+	_, err = ParseFile("", "'use strict'; ({ abc: 1, 'abc': 2 })")
+	Is(err, "SyntaxError: Duplicate data property in object literal not allowed in strict mode (line 1)")
+}
+
+func TestParseStrict(t *testing.T) {
+	Terst(t)
+
+	program, err := ParseFile("", "'use strict'; function abc() {}")
+	Is(err, nil)
+	Is(program.Strict, true)
+	Is(program.DeclarationList[0].(*ast.FunctionDeclaration).Function.Strict, true)
+
+	program, err = ParseFile("", "abc(); 'use strict'; function def() { 'use strict'; with ({}) {} }")
+	Is(err, "SyntaxError: Strict mode code may not include a with statement (line 1)")
+
+	program, err = ParseFile("", "function abc() { 'use strict'; } with ({}) {}")
+	Is(err, nil)
+	Is(program.Strict, false)
+
+	_, err = ParseFile("", "function abc(def, def) { 'use strict'; }")
+	Is(err, "SyntaxError: Strict mode function may not have duplicate parameter names (line 1)")
+
+	_, err = ParseFile("", "'use strict'; var eval = 1;")
+	Is(err, "SyntaxError: Unexpected eval or arguments in strict mode (line 1)")
+
+	_, err = ParseFile("", "'use strict'; arguments++;")
+	Is(err, "SyntaxError: Unexpected eval or arguments in strict mode (line 1)")
+
+	_, err = ParseFile("", "'use strict'; var abc; delete abc;")
+	Is(err, "SyntaxError: Delete of an unqualified identifier in strict mode. (line 1)")
+
+	_, err = ParseFile("", "'use strict'; 010;")
+	Is(err, "SyntaxError: Octal literals are not allowed in strict mode. (line 1)")
+
+	// An escape is not the directive
+	program, err = ParseFile("", "'use\\x20strict'; 'use str\\\nict'; with ({}) {}")
+	Is(err, nil)
+	Is(program.Strict, false)
+}
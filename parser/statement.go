@@ -0,0 +1,629 @@
+package parser
+
+import (
+	"github.com/robertkrimen/otto/ast"
+)
+
+func (self *_parser) ParseStatement() ast.Statement {
+
+	switch self.Peek().Kind {
+	case ";":
+		return &ast.EmptyStatement{
+			Position: self.position(self.Next()),
+		}
+	case "if":
+		return self.ParseIf()
+	case "do":
+		return self.ParseDoWhile()
+	case "while":
+		return self.ParseWhile()
+	case "for":
+		return self.ParseForOrForIn()
+	case "continue":
+		return self.ParseContinue()
+	case "with":
+		return self.ParseWith()
+	case "break":
+		return self.ParseBreak()
+	case "{":
+		return self.ParseBlock()
+	case "var":
+		return self.ParseVariableStatement()
+	case "function":
+		function := self.ParseFunctionDeclaration()
+		return &ast.FunctionStatement{
+			Position: function.Position,
+			Function: function,
+		}
+	case "switch":
+		return self.ParseSwitch()
+	case "return":
+		return self.ParseReturnStatement()
+	case "throw":
+		return self.ParseThrow()
+	case "try":
+		return self.ParseTryCatch()
+	case "debugger":
+		position := self.position(self.Next())
+		self.ConsumeSemicolon()
+		return &ast.DebuggerStatement{
+			Position: position,
+		}
+	}
+
+	position := self.peekPosition()
+	expression := self.ParseExpression()
+
+	if identifier, yes := expression.(*ast.Identifier); yes && self.Accept(":") {
+		labelSet := self.Scope().labelSet
+		label := identifier.Name
+		if labelSet[label] {
+			panic(self.History(-2).newSyntaxError("Label '%s' has already been declared", label))
+		}
+		labelSet[label] = true
+		statement := self.ParseStatement()
+		delete(labelSet, label)
+		return &ast.LabelledStatement{
+			Position:  position,
+			Label:     identifier,
+			Statement: statement,
+		}
+	}
+
+	self.ConsumeSemicolon()
+
+	return &ast.ExpressionStatement{
+		Position:   position,
+		Expression: expression,
+	}
+}
+
+func (self *_parser) ParseTryCatch() ast.Statement {
+	position := self.position(self.Expect("try"))
+
+	node := &ast.TryStatement{
+		Position: position,
+		Body:     self.ParseBlock(),
+	}
+
+	found := false
+	if self.Match("catch") {
+		position := self.position(self.Next())
+		self.Expect("(")
+		parameter := self.ConsumeIdentifier()
+		if self.Scope().Strict {
+			self.checkStrictIdentifier(parameter)
+		}
+		self.Expect(")")
+		node.Catch = &ast.CatchStatement{
+			Position:  position,
+			Parameter: parameter,
+			Body:      self.ParseBlock(),
+		}
+		found = true
+	}
+
+	if self.Accept("finally") {
+		node.Finally = self.ParseBlock()
+		found = true
+	}
+
+	if !found {
+		panic(self.Peek().newSyntaxError("Missing catch or finally after try"))
+	}
+
+	return node
+}
+
+func (self *_parser) ParseWith() ast.Statement {
+	token := self.Expect("with")
+	if self.Scope().Strict {
+		panic(token.newSyntaxError("Strict mode code may not include a with statement"))
+	}
+	position := self.position(token)
+
+	return &ast.WithStatement{
+		Position: position,
+		Object:   self.ParseExpression(),
+		Body:     self.ParseStatement(),
+	}
+}
+
+func (self *_parser) ParseContinue() ast.Statement {
+	position, label := self.ParseContinueBreak("continue")
+	if self.Scope().InIteration {
+		return &ast.ContinueStatement{
+			Position: position,
+			Label:    label,
+		}
+	}
+	panic(self.Peek().newSyntaxError("Illegal continue statement"))
+}
+
+func (self *_parser) ParseBreak() ast.Statement {
+	position, label := self.ParseContinueBreak("break")
+	scope := self.Scope()
+	if scope.InIteration || scope.InSwitch {
+		return &ast.BreakStatement{
+			Position: position,
+			Label:    label,
+		}
+	}
+	panic(self.Peek().newSyntaxError("Illegal break statement"))
+}
+
+func (self *_parser) ParseContinueBreak(kind string) (ast.Position, *ast.Identifier) {
+	position := self.position(self.Expect(kind))
+
+	if self.Accept(";") || self.Accept("\n") {
+		return position, nil
+	}
+
+	var label *ast.Identifier
+	if self.Match("identifier") {
+		label = self.ConsumeIdentifier()
+		if !self.Scope().HasLabel(label.Name) {
+			panic(self.History(-1).newSyntaxError("Undefined label '%s'", label.Name))
+		}
+	}
+
+	self.ConsumeSemicolon()
+
+	return position, label
+}
+
+func (self *_parser) parseInFunction(parse func()) {
+	in := self.Scope().InFunction
+	self.Scope().InFunction = true
+	defer func() {
+		self.Scope().InFunction = in
+	}()
+	parse()
+}
+
+func (self *_parser) parseInSwitch(parse func()) {
+	in := self.Scope().InSwitch
+	self.Scope().InSwitch = true
+	defer func() {
+		self.Scope().InSwitch = in
+	}()
+	parse()
+}
+
+func (self *_parser) parseInIteration(parse func() ast.Statement) ast.Statement {
+	in := self.Scope().InIteration
+	self.Scope().InIteration = true
+	defer func() {
+		self.Scope().InIteration = in
+	}()
+	return parse()
+}
+
+func (self *_parser) ParseDoWhile() ast.Statement {
+	position := self.position(self.Expect("do"))
+	body := self.parseInIteration(func() ast.Statement {
+		return self.ParseStatement()
+	})
+	self.Expect("while")
+	self.Expect("(")
+	test := self.ParseExpression()
+	self.Expect(")")
+
+	return &ast.DoWhileStatement{
+		Position: position,
+		Test:     test,
+		Body:     body,
+	}
+}
+
+func (self *_parser) ParseWhile() ast.Statement {
+	position := self.position(self.Expect("while"))
+	self.Expect("(")
+	test := self.ParseExpression()
+	self.Expect(")")
+	body := self.parseInIteration(func() ast.Statement {
+		return self.ParseStatement()
+	})
+
+	return &ast.WhileStatement{
+		Position: position,
+		Test:     test,
+		Body:     body,
+	}
+}
+
+func (self *_parser) ParseIf() ast.Statement {
+	position := self.position(self.Expect("if"))
+	self.Expect("(")
+	node := &ast.IfStatement{
+		Position: position,
+		Test:     self.ParseExpression(),
+	}
+	self.Expect(")")
+	node.Consequent = self.ParseStatement()
+	if self.Accept("else") {
+		node.Alternate = self.ParseStatement()
+	}
+
+	return node
+}
+
+func (self *_parser) parseStatementUntil(stop func() bool) []ast.Statement {
+	list := []ast.Statement{}
+	for {
+		if stop() {
+			break
+		}
+		list = append(list, self.ParseStatement())
+	}
+	return list
+}
+
+// parseSourceElements parses the statements of a program or function body, which
+// begin with the directive prologue (where "use strict" makes the scope strict).
+func (self *_parser) parseSourceElements(stop func() bool) []ast.Statement {
+	list := []ast.Statement{}
+	prologue := true
+	for !stop() {
+		statement := self.ParseStatement()
+		if prologue {
+			prologue = false
+			if expression, ok := statement.(*ast.ExpressionStatement); ok {
+				if literal, ok := expression.Expression.(*ast.StringLiteral); ok {
+					prologue = true // A directive
+					// Only the exact "use strict" (or 'use strict'), without escapes
+					if len(literal.Literal) > 2 && literal.Literal[1:len(literal.Literal)-1] == "use strict" {
+						self.Scope().Strict = true
+					}
+				}
+			}
+		}
+		list = append(list, statement)
+	}
+	return list
+}
+
+// checkStrictParameterList checks the name and parameters of a strict function,
+// which is only known to be strict after the body is parsed.
+func (self *_parser) checkStrictParameterList(node *ast.FunctionLiteral) {
+	if node.Name != nil {
+		self.checkStrictIdentifier(node.Name)
+	}
+	seen := map[string]bool{}
+	for _, parameter := range node.ParameterList {
+		self.checkStrictIdentifier(parameter)
+		if seen[parameter.Name] {
+			panic(newSyntaxErrorAt(parameter.Position, "Strict mode function may not have duplicate parameter names"))
+		}
+		seen[parameter.Name] = true
+	}
+}
+
+// checkStrictIdentifier checks that the identifier (being declared or assigned) is
+// not eval or arguments.
+func (self *_parser) checkStrictIdentifier(identifier *ast.Identifier) {
+	switch identifier.Name {
+	case "eval", "arguments":
+		panic(newSyntaxErrorAt(identifier.Position, "Unexpected eval or arguments in strict mode"))
+	}
+}
+
+// checkStrictAssignment checks the target of an assignment (or ++ or --) in strict code.
+func (self *_parser) checkStrictAssignment(target ast.Expression) {
+	if identifier, ok := target.(*ast.Identifier); ok && self.Scope().Strict {
+		self.checkStrictIdentifier(identifier)
+	}
+}
+
+func (self *_parser) ParseBlock() *ast.BlockStatement {
+	node := &ast.BlockStatement{
+		Position: self.position(self.Expect("{")),
+	}
+	node.List = self.parseStatementUntil(func() bool {
+		return self.Accept("}")
+	})
+
+	return node
+}
+
+func (self *_parser) ParseReturnStatement() ast.Statement {
+	position := self.position(self.Expect("return"))
+
+	if !self.Scope().InFunction {
+		panic(self.History(-1).newSyntaxError("Illegal return statement"))
+	}
+
+	node := &ast.ReturnStatement{
+		Position: position,
+	}
+
+	if self.Match("\n") {
+		return node
+	}
+
+	if !self.Match(";") {
+		if !self.Match("}") && !self.Match("EOF") {
+			node.Argument = self.ParseExpression()
+		}
+	}
+
+	self.ConsumeSemicolon()
+
+	return node
+}
+
+func (self *_parser) ParseThrow() ast.Statement {
+	position := self.position(self.Expect("throw"))
+
+	if self.Match("\n") {
+		// TODO Better error message
+		panic(self.Peek().newSyntaxError("Illegal newline after throw"))
+	}
+
+	node := &ast.ThrowStatement{
+		Position: position,
+		Argument: self.ParseExpression(),
+	}
+
+	self.ConsumeSemicolon()
+
+	return node
+}
+
+func (self *_parser) ParseSwitch() ast.Statement {
+	position := self.position(self.Expect("switch"))
+
+	self.Expect("(")
+	node := &ast.SwitchStatement{
+		Position:     position,
+		Discriminant: self.ParseExpression(),
+		Default:      -1,
+	}
+	self.Expect(")")
+
+	self.Expect("{")
+
+	self.parseInSwitch(func() {
+		for index := 0; true; index++ {
+			if self.Accept("}") {
+				break
+			}
+
+			clause := self.ParseCase()
+			if clause.Test == nil {
+				if node.Default != -1 {
+					panic(self.History(-1).newSyntaxError("More than one default clause in switch statement"))
+				}
+				node.Default = index
+			}
+			node.Body = append(node.Body, clause)
+		}
+	})
+
+	return node
+}
+
+func (self *_parser) ParseCase() *ast.CaseStatement {
+
+	node := &ast.CaseStatement{
+		Position: self.peekPosition(),
+	}
+	if !self.Accept("default") {
+		self.Expect("case")
+		node.Test = self.ParseExpression()
+	}
+	self.Expect(":")
+
+	node.Consequent = self.parseStatementUntil(func() bool {
+		return false ||
+			self.Match("EOF") ||
+			self.Match("}") ||
+			self.Match("default") ||
+			self.Match("case")
+	})
+
+	return node
+}
+
+func (self *_parser) ParseVariable() *ast.VariableExpression {
+	identifier := self.ConsumeIdentifier()
+	if self.Scope().Strict {
+		self.checkStrictIdentifier(identifier)
+	}
+	node := &ast.VariableExpression{
+		Position: identifier.Position,
+		Name:     identifier.Name,
+	}
+
+	for _, value := range []string{"=", ":="} {
+		if self.Accept(value) {
+			node.Initializer = self.ParseAssignmentExpression()
+			break
+		}
+	}
+
+	return node
+}
+
+func (self *_parser) ParseVariableDeclaration() *ast.VariableStatement {
+	node := &ast.VariableStatement{
+		Position: self.position(self.Expect("var")),
+	}
+
+	for {
+		node.List = append(node.List, self.ParseVariable())
+
+		if !self.Accept(",") {
+			break
+		}
+	}
+
+	self.Scope().Declare(&ast.VariableDeclaration{
+		List: node.List,
+	})
+
+	return node
+}
+
+func (self *_parser) ParseVariableStatement() *ast.VariableStatement {
+
+	node := self.ParseVariableDeclaration()
+
+	self.ConsumeSemicolon()
+
+	return node
+}
+
+func (self *_parser) ParseFunction(declare bool) *ast.FunctionLiteral {
+
+	node := &ast.FunctionLiteral{
+		Position: self.position(self.Expect("function")),
+	}
+
+	if self.Match("identifier") {
+		node.Name = self.ConsumeIdentifier()
+		if declare {
+			self.Scope().Declare(&ast.FunctionDeclaration{
+				Function: node,
+			})
+		}
+	} else if declare {
+		// Trigger a panic, because we really should see
+		// an identifier here
+		self.Expect("identifier")
+	}
+
+	self.ParseFunctionRest(node)
+
+	return node
+}
+
+// ParseFunctionRest parses the parameters and body of a function, after the
+// function keyword and name (if any).
+func (self *_parser) ParseFunctionRest(node *ast.FunctionLiteral) {
+
+	token := self.Peek()
+	if token.Kind != "(" {
+		panic(self.Unexpected(token))
+	}
+
+	self.Expect("(")
+	for !self.Accept(")") {
+		node.ParameterList = append(node.ParameterList, self.ConsumeIdentifier())
+		if !self.Match(")") {
+			self.Expect(",")
+		}
+	}
+
+	{
+		self.EnterScope()
+		defer self.LeaveScope()
+		self.parseInFunction(func() {
+			node.Body = &ast.BlockStatement{
+				Position: self.position(self.Expect("{")),
+			}
+			node.Body.List = self.parseSourceElements(func() bool {
+				return self.Accept("}")
+			})
+		})
+		node.DeclarationList = self.Scope().DeclarationList
+		node.Strict = self.Scope().Strict
+	}
+
+	if node.Strict {
+		self.checkStrictParameterList(node)
+	}
+}
+
+func (self *_parser) ParseFunctionDeclaration() *ast.FunctionLiteral {
+	return self.ParseFunction(true)
+}
+
+func (self *_parser) parseForIn(position ast.Position, into ast.Expression) *ast.ForInStatement {
+
+	// Already have consumed "<into> in"
+
+	source := self.ParseExpression()
+	self.Expect(")")
+
+	body := self.parseInIteration(func() ast.Statement {
+		return self.ParseStatement()
+	})
+
+	return &ast.ForInStatement{
+		Position: position,
+		Into:     into,
+		Source:   source,
+		Body:     body,
+	}
+}
+
+func (self *_parser) parseFor(position ast.Position, initializer ast.Node) *ast.ForStatement {
+
+	// Already have consumed "<initializer> ;"
+
+	var test, update ast.Expression
+
+	if !self.Match(";") {
+		test = self.ParseExpression()
+	}
+	self.Expect(";")
+
+	if !self.Match(")") {
+		update = self.ParseExpression()
+	}
+	self.Expect(")")
+
+	body := self.parseInIteration(func() ast.Statement {
+		return self.ParseStatement()
+	})
+
+	return &ast.ForStatement{
+		Position:    position,
+		Initializer: initializer,
+		Test:        test,
+		Update:      update,
+		Body:        body,
+	}
+}
+
+func (self *_parser) ParseForOrForIn() ast.Statement {
+	position := self.position(self.Expect("for"))
+	self.Expect("(")
+
+	var left ast.Node
+
+	isIn := false
+	if !self.Match(";") {
+		previousAllowIn := self.Scope().AllowIn
+		self.Scope().AllowIn = false
+		if self.Match("var") {
+			declarationList := self.ParseVariableDeclaration()
+			if len(declarationList.List) == 1 && self.Accept("in") {
+				isIn = true
+				// We only want (there should be only) one _declaration
+				// (12.2 Variable Statement)
+				left = declarationList.List[0]
+			} else {
+				left = declarationList
+			}
+		} else {
+			left = self.ParseExpression()
+			isIn = self.Accept("in")
+		}
+		self.Scope().AllowIn = previousAllowIn
+	}
+
+	if !isIn {
+		self.Expect(";")
+		return self.parseFor(position, left)
+	}
+
+	switch left.(type) {
+	case *ast.Identifier, *ast.DotExpression, *ast.BracketExpression, *ast.VariableExpression:
+	default:
+		panic(self.History(-1).newSyntaxError("Invalid left-hand side in for-in"))
+	}
+
+	return self.parseForIn(position, left.(ast.Expression))
+}
@@ -1413,7 +1413,7 @@ Label '_' has already been declared
 3:-:-
 	`)
 
-	test("/Xyzzy(?!Nothing happens)/", "---\nInvalid regular expression: invalid or unsupported Perl syntax: `(?!`\n1:-:-")
+	test("/Xyzzy(?#Nothing happens)/", "---\nInvalid regular expression: invalid or unsupported Perl syntax: `(?#`\n1:-:-")
 
 	test(`
 	function(){}
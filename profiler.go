@@ -0,0 +1,322 @@
+package otto
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+)
+
+// Profiler is a Tracer that counts the calls of, and measures the time spent in,
+// each function and line of a script, for the pprof tool:
+//
+//		profiler := otto.NewProfiler()
+//		Otto.SetTracer(profiler)
+//		Otto.Run(`...`)
+//		Otto.SetTracer(nil)
+//
+//		file, _ := os.Create("otto.pprof")
+//		profiler.WriteProfile(file)
+//		file.Close()
+//
+//		$ go tool pprof -top otto.pprof
+//
+// The profile has two values for each sample (a stack of JavaScript functions and
+// lines): calls, the number of times the innermost function was called from that
+// stack, and time, the (wall clock) nanoseconds spent evaluating the innermost line.
+// The time between statements is counted toward the statement before, including
+// the time spent in any native (Go) function that the statement calls. Time spent
+// outside of the runtime (between runs) is not counted.
+//
+// It is a tracing (not a sampling) profiler: every call and statement is counted
+// (as it happens), so the script runs slower while it is being profiled (and the
+// time of a short statement includes some of the cost of counting it).
+//
+// A Profiler should only be the tracer of one runtime at a time.
+type Profiler struct {
+	now     func() time.Time
+	start   time.Time
+	last    time.Time // The time of the last event, or zero if not running
+	stack   []_profileFrame
+	root    _profileSample    // The (empty) stack that each stack begins with
+	sample  []*_profileSample // The sample of each stack, in order of creation
+	elapsed time.Duration     // The total time counted
+}
+
+type _profileFrame struct {
+	function _profileFunction
+	line     int                     // The current line
+	parent   *_profileSample         // The sample of the stack, up to the frame before
+	sample   *_profileSample         // The sample of the stack, up to this frame (at the current line)
+	each     map[int]*_profileSample // The samples of the stack, up to this frame (at each line)
+}
+
+// _profileSample is the sample of a stack, and a node in the tree of stacks, so
+// that the sample of a stack is found by frame and line instead of by a key made
+// of the whole stack.
+type _profileSample struct {
+	stack []_profileLocation // Beginning with the innermost frame
+	calls int64
+	time  int64
+	child map[_profileFunction]map[int]*_profileSample // The samples of each (function) frame called from this stack, by line
+}
+
+type _profileFunction struct {
+	name     string
+	filename string
+	start    int // The line of the function
+}
+
+type _profileLocation struct {
+	_profileFunction
+	line int
+}
+
+// NewProfiler returns a Profiler, which does nothing until it is the tracer of
+// a runtime (see SetTracer).
+func NewProfiler() *Profiler {
+	return &Profiler{
+		now: time.Now,
+	}
+}
+
+func (self *Profiler) OnEnterFunction(frame Frame) {
+	self.count()
+	if self.start.IsZero() {
+		self.start = self.last
+	}
+	function := _profileFunction{
+		name:     frame.Function,
+		filename: frame.Filename,
+		start:    frame.Line,
+	}
+	if function.name == "" {
+		function.name = "(anonymous)"
+		if frame.Line == 0 {
+			function.name = "(global)"
+		}
+	}
+	parent := self.top()
+	each := parent.child[function]
+	if each == nil {
+		if parent.child == nil {
+			parent.child = map[_profileFunction]map[int]*_profileSample{}
+		}
+		each = map[int]*_profileSample{}
+		parent.child[function] = each
+	}
+	self.stack = append(self.stack, _profileFrame{
+		function: function,
+		parent:   parent,
+		each:     each,
+	})
+	self.enter(frame.Line).calls += 1
+}
+
+func (self *Profiler) OnExitFunction(frame Frame) {
+	self.count()
+	if len(self.stack) > 0 {
+		self.stack = self.stack[:len(self.stack)-1]
+	}
+	if len(self.stack) == 0 {
+		self.last = time.Time{} // Not running
+	}
+}
+
+func (self *Profiler) OnStatement(frame Frame) {
+	self.count()
+	if len(self.stack) > 0 && self.stack[len(self.stack)-1].line != frame.Line {
+		self.enter(frame.Line)
+	}
+}
+
+// count counts the time since the last event toward the current stack.
+func (self *Profiler) count() {
+	now := self.now()
+	if !self.last.IsZero() && len(self.stack) > 0 {
+		elapsed := now.Sub(self.last)
+		self.stack[len(self.stack)-1].sample.time += int64(elapsed)
+		self.elapsed += elapsed
+	}
+	self.last = now
+}
+
+// top returns the sample of the current stack, or the root if there is none.
+func (self *Profiler) top() *_profileSample {
+	if len(self.stack) == 0 {
+		return &self.root
+	}
+	return self.stack[len(self.stack)-1].sample
+}
+
+// enter moves the innermost frame to the line, and returns the sample of the
+// (new) current stack, which is made (once) if it is new.
+func (self *Profiler) enter(line int) *_profileSample {
+	frame := &self.stack[len(self.stack)-1]
+	frame.line = line
+	sample := frame.each[line]
+	if sample == nil {
+		sample = &_profileSample{
+			stack: append([]_profileLocation{{frame.function, line}}, frame.parent.stack...),
+		}
+		frame.each[line] = sample
+		self.sample = append(self.sample, sample)
+	}
+	frame.sample = sample
+	return sample
+}
+
+// WriteProfile writes the profile (so far) to the writer, in the (gzipped
+// protocol buffer) format of pprof.
+func (self *Profiler) WriteProfile(writer io.Writer) error {
+	encoder := newProfileEncoder()
+
+	sampleType := [][2]string{{"calls", "count"}, {"time", "nanoseconds"}}
+	for _, valueType := range sampleType {
+		encoder.message(1, func() { // sample_type
+			encoder.int64(1, encoder.string(valueType[0]))
+			encoder.int64(2, encoder.string(valueType[1]))
+		})
+	}
+	for _, sample := range self.sample {
+		locationList := make([]uint64, 0, len(sample.stack))
+		for _, location := range sample.stack {
+			locationList = append(locationList, encoder.location(location))
+		}
+		encoder.message(2, func() { // sample
+			encoder.packed(1, locationList)
+			encoder.packed(2, []uint64{uint64(sample.calls), uint64(sample.time)})
+		})
+	}
+	encoder.message(11, func() { // period_type
+		encoder.int64(1, encoder.string("time"))
+		encoder.int64(2, encoder.string("nanoseconds"))
+	})
+	encoder.int64(12, 1) // period
+	if !self.start.IsZero() {
+		encoder.int64(9, self.start.UnixNano()) // time_nanos
+	}
+	encoder.int64(10, int64(self.elapsed)) // duration_nanos
+
+	gzipWriter := gzip.NewWriter(writer)
+	if _, err := gzipWriter.Write(encoder.finish()); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// _profileEncoder encodes a profile (profile.proto, of pprof) as a protocol buffer
+type _profileEncoder struct {
+	buffer     []byte
+	stringList []string
+	stringId   map[string]int64
+
+	locationList []byte // The encoded locations and functions, which come after the samples
+	locationId   map[_profileLocation]uint64
+	functionId   map[_profileFunction]uint64
+}
+
+func newProfileEncoder() *_profileEncoder {
+	return &_profileEncoder{
+		stringList: []string{""},
+		stringId:   map[string]int64{"": 0},
+		locationId: map[_profileLocation]uint64{},
+		functionId: map[_profileFunction]uint64{},
+	}
+}
+
+func (self *_profileEncoder) varint(value uint64) {
+	for value >= 0x80 {
+		self.buffer = append(self.buffer, byte(value)|0x80)
+		value >>= 7
+	}
+	self.buffer = append(self.buffer, byte(value))
+}
+
+func (self *_profileEncoder) int64(field int, value int64) {
+	if value == 0 {
+		return
+	}
+	self.varint(uint64(field) << 3) // Varint
+	self.varint(uint64(value))
+}
+
+func (self *_profileEncoder) bytes(field int, value []byte) {
+	self.varint(uint64(field)<<3 | 2) // Length-delimited
+	self.varint(uint64(len(value)))
+	self.buffer = append(self.buffer, value...)
+}
+
+func (self *_profileEncoder) packed(field int, valueList []uint64) {
+	buffer := self.buffer
+	self.buffer = nil
+	for _, value := range valueList {
+		self.varint(value)
+	}
+	packed := self.buffer
+	self.buffer = buffer
+	self.bytes(field, packed)
+}
+
+func (self *_profileEncoder) message(field int, encode func()) {
+	buffer := self.buffer
+	self.buffer = nil
+	encode()
+	message := self.buffer
+	self.buffer = buffer
+	self.bytes(field, message)
+}
+
+// string returns the index of the string in the string table.
+func (self *_profileEncoder) string(value string) int64 {
+	if id, exists := self.stringId[value]; exists {
+		return id
+	}
+	id := int64(len(self.stringList))
+	self.stringList = append(self.stringList, value)
+	self.stringId[value] = id
+	return id
+}
+
+// location returns the id of the location (adding it and its function, if new).
+func (self *_profileEncoder) location(location _profileLocation) uint64 {
+	if id, exists := self.locationId[location]; exists {
+		return id
+	}
+	buffer := self.buffer
+	self.buffer = self.locationList
+
+	functionId, exists := self.functionId[location._profileFunction]
+	if !exists {
+		functionId = uint64(len(self.functionId) + 1)
+		self.functionId[location._profileFunction] = functionId
+		self.message(5, func() { // function
+			self.int64(1, int64(functionId))
+			self.int64(2, self.string(location.name))
+			self.int64(3, self.string(location.name))
+			self.int64(4, self.string(location.filename))
+			self.int64(5, int64(location.start))
+		})
+	}
+
+	id := uint64(len(self.locationId) + 1)
+	self.locationId[location] = id
+	self.message(4, func() { // location
+		self.int64(1, int64(id))
+		self.message(4, func() { // line
+			self.int64(1, int64(functionId))
+			self.int64(2, int64(location.line))
+		})
+	})
+
+	self.locationList = self.buffer
+	self.buffer = buffer
+	return id
+}
+
+func (self *_profileEncoder) finish() []byte {
+	self.buffer = append(self.buffer, self.locationList...)
+	for _, value := range self.stringList {
+		self.bytes(6, []byte(value)) // string_table
+	}
+	return self.buffer
+}
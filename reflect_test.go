@@ -2,6 +2,7 @@ package otto
 
 import (
 	. "./terst"
+	"errors"
 	"math"
 	"reflect"
 	"testing"
@@ -127,12 +128,250 @@ func Test_reflectStruct(t *testing.T) {
 			abc.FuncVarArgs("abc", "def", "ghi");
 		`, "3")
 
-		test(`raise:
+		test(`
             abc.FuncNoArgsMultRet();
-        `, "TypeError")
+        `, "def")
 	}
 }
 
+type testTagStruct struct {
+	FirstName string
+	Password  string `js:"-"`
+	LastLogin int    `json:"last_login,omitempty"`
+	Nickname  string `js:"nick" json:"nickname"`
+	URLPath   string
+	testEmbeddedStruct
+	hidden string
+}
+
+type testEmbeddedStruct struct {
+	Xyzzy int
+}
+
+func (self testTagStruct) FullName() string {
+	return self.FirstName + "!"
+}
+
+func Test_reflectStructFieldName(t *testing.T) {
+	Terst(t)
+
+	Otto, test := runTestWithOtto()
+
+	abc := &testTagStruct{FirstName: "Abc", Password: "secret", Nickname: "Def", URLPath: "/", hidden: "hidden"}
+	abc.Xyzzy = 1
+	failSet("abc", abc)
+
+	// By default, only the js tag is honored
+	test(`
+        var result = [];
+        for (var name in abc) {
+            result.push(name);
+        }
+        result;
+    `, "FirstName,LastLogin,nick,URLPath,Xyzzy,FullName")
+
+	test(`
+        abc.LastLogin = 3;
+        [ abc.FirstName, abc.Password, abc.nick, abc.Nickname, abc.Xyzzy, abc.hidden, abc.FullName() ];
+    `, "Abc,,Def,,1,,Abc!")
+	Is(abc.LastLogin, 3)
+
+	test(`
+        abc.Password = "Xyzzy";
+        abc.Password;
+    `, "Xyzzy")
+	Is(abc.Password, "secret")
+
+	Otto.SetFieldNameMapper(TagFieldNameMapper("json", true))
+
+	test(`
+        var result = [];
+        for (var name in abc) {
+            result.push(name);
+        }
+        result;
+    `, "firstName,last_login,nick,urlPath,xyzzy,fullName,Password")
+
+	test(`
+        abc.firstName = "Ghi";
+        abc.last_login = 4;
+        abc.urlPath = "/index";
+        [ abc.firstName, abc.FirstName, abc.last_login, abc.urlPath, abc.fullName() ];
+    `, "Ghi,,4,/index,Ghi!")
+	Is(abc.FirstName, "Ghi")
+	Is(abc.LastLogin, 4)
+	Is(abc.URLPath, "/index")
+
+	Is(lowerCamelCase("FirstName"), "firstName")
+	Is(lowerCamelCase("ID"), "id")
+	Is(lowerCamelCase("URLPath"), "urlPath")
+	Is(lowerCamelCase("X"), "x")
+	Is(lowerCamelCase("xyzzy"), "xyzzy")
+}
+
+type TestEmbeddedUser struct {
+	Name string
+	*TestEmbeddedAccount
+}
+
+func (self TestEmbeddedUser) Greeting() string {
+	return "Hello, " + self.Name
+}
+
+type TestEmbeddedAccount struct {
+	Hidden int `js:"hidden"`
+}
+
+type testEmbeddedDouble struct {
+	TestEmbeddedUser
+	TestEmbeddedStruct `js:"inner"`
+	Other              TestEmbeddedUser
+	Name               int
+}
+
+type TestEmbeddedStruct struct {
+	Xyzzy int
+}
+
+func Test_reflectStructEmbedded(t *testing.T) {
+	Terst(t)
+
+	_, test := runTestWithOtto()
+
+	user := &TestEmbeddedUser{Name: "Xyzzy"}
+	failSet("user", user)
+
+	// The embedded struct is not a property, and a field of a nil embedded pointer
+	// is not enumerated (until it is set)
+	test(`
+        var result = [];
+        for (var name in user) {
+            result.push(name);
+        }
+        [ result, user.hidden ];
+    `, "Name,Greeting,")
+
+	test(`
+        user.hidden = 3;
+        var result = [];
+        for (var name in user) {
+            result.push(name);
+        }
+        [ result, user.hidden ];
+    `, "Name,hidden,Greeting,3")
+	Is(user.TestEmbeddedAccount.Hidden, 3)
+
+	double := &testEmbeddedDouble{Name: 1}
+	failSet("double", double)
+
+	// A field hides a more deeply embedded field, and a tag names an embedded struct
+	test(`
+        var result = [];
+        for (var name in double) {
+            result.push(name);
+        }
+        double.Name = 2;
+        [ result, double.Name, typeof double.inner ];
+    `, "inner,Other,Name,Greeting,2,object")
+	Is(double.Name, 2)
+
+	// A method is not shadowed
+	test(`raise:
+        "use strict";
+        user.Greeting = 1;
+    `, "TypeError")
+	test(`
+        user.Greeting = 1;
+        user.Greeting();
+    `, "Hello, Xyzzy")
+}
+
+type testFunctionOptions struct {
+	Limit  int    `js:"limit"`
+	Prefix string `js:"prefix"`
+}
+
+var errTestFunction = errors.New("Nothing happens.")
+
+func Test_reflectFunction(t *testing.T) {
+	Terst(t)
+
+	Otto, test := runTestWithOtto()
+
+	failSet("search", func(query []string, options testFunctionOptions) ([]string, error) {
+		if options.Limit < 0 {
+			return nil, errTestFunction
+		}
+		result := []string{}
+		for _, value := range query {
+			if len(result) < options.Limit {
+				result = append(result, options.Prefix+value)
+			}
+		}
+		return result, nil
+	})
+	failSet("sum", func(initial float64, valueList ...int) (int, float64) {
+		total := 0
+		for _, value := range valueList {
+			total += value
+		}
+		return len(valueList), initial + float64(total)
+	})
+	failSet("check", func(value Value) error {
+		if value.IsUndefined() {
+			return errTestFunction
+		}
+		return nil
+	})
+
+	test(`
+        search([ "abc", "def", "ghi" ], { limit: 2, prefix: "+" });
+    `, "+abc,+def")
+
+	test(`
+        search([ "abc" ]);
+    `, "")
+
+	test(`
+        [ sum(0.5, 1, 2, 3), sum(1), sum() ];
+    `, "3,6.5,0,1,0,0")
+
+	test(`
+        check(null);
+    `, "undefined")
+
+	test(`raise:
+        search([ "abc" ], { limit: "2" });
+    `, "TypeError: arguments[1].limit: cannot convert string to int")
+
+	test(`raise:
+        sum(0, 1, 2.5);
+    `, "TypeError: arguments[2]: cannot convert 2.5 to int")
+
+	test(`
+        var result;
+        try {
+            search([], { limit: -1 });
+        } catch (error) {
+            result = [ error instanceof Error, error.message ];
+        }
+        result;
+    `, "true,Nothing happens.")
+
+	_, err := Otto.Run(`search([], { limit: -1 });`)
+	Is(err, "Error: Nothing happens. (line 1)")
+	Is(errors.Is(err, errTestFunction), true)
+
+	_, err = Otto.Run(`
+        try {
+            check();
+        } catch (error) {
+            throw error;
+        }
+    `)
+	Is(errors.Is(err, errTestFunction), true)
+}
+
 func Test_reflectMap(t *testing.T) {
 	Terst(t)
 
@@ -410,3 +649,162 @@ func Test_reflectMapInterface(t *testing.T) {
 		Is(mno.Ghi, "Something happens.")
 	}
 }
+
+// testHostObject is a HostObject that records each call (as "get:abc", etc.)
+type testHostObject struct {
+	property map[string]Value
+	log      []string
+}
+
+func (self *testHostObject) Get(name string) Value {
+	self.log = append(self.log, "get:"+name)
+	return self.property[name]
+}
+
+func (self *testHostObject) Set(name string, value Value) bool {
+	self.log = append(self.log, "set:"+name)
+	if name == "readOnly" {
+		return false
+	}
+	self.property[name] = value
+	return true
+}
+
+func (self *testHostObject) Has(name string) bool {
+	_, exists := self.property[name]
+	return exists
+}
+
+func (self *testHostObject) Delete(name string) bool {
+	self.log = append(self.log, "delete:"+name)
+	if name == "readOnly" {
+		return false
+	}
+	delete(self.property, name)
+	return true
+}
+
+func (self *testHostObject) Keys() []string {
+	return []string{"abc", "def"}
+}
+
+type testHostFunction struct {
+	testHostObject
+}
+
+func (self *testHostFunction) Call(call FunctionCall) Value {
+	return toValue_string("called:" + call.Argument(0).String())
+}
+
+func (self *testHostFunction) Construct(call FunctionCall) Value {
+	object, _ := call.Otto.Object(`({})`)
+	object.Set("constructed", call.Argument(0))
+	return object.Value()
+}
+
+// testPanicHostObject is a HostObject that panics when getting or enumerating
+type testPanicHostObject struct {
+	testHostObject
+}
+
+func (self *testPanicHostObject) Get(name string) Value {
+	panic("boom")
+}
+
+func (self *testPanicHostObject) Keys() []string {
+	panic(errors.New("no keys"))
+}
+
+func Test_reflectHostObject(t *testing.T) {
+	Terst(t)
+
+	Otto, test := runTestWithOtto()
+
+	host := &testHostObject{
+		property: map[string]Value{
+			"abc": toValue_int(1),
+			"def": toValue_string("Xyzzy"),
+		},
+	}
+	failSet("record", host)
+
+	test(`
+        record.ghi = record.abc + 1;
+        [ record.abc, record.def, record.ghi, record.xyz, "abc" in record, "xyz" in record ];
+    `, "1,Xyzzy,2,,true,false")
+	Is(host.log, []string{"get:abc", "set:ghi", "get:abc", "get:def", "get:ghi"})
+	Is(host.property["ghi"], "2")
+
+	test(`
+        var result = [];
+        for (var name in record) {
+            result.push(name);
+        }
+        [ result, Object.keys(record), typeof record, record.hasOwnProperty("def"), String(record) ];
+    `, "abc,def,abc,def,object,true,[object Object]")
+
+	test(`
+        delete record.def;
+        record.readOnly = 1;
+        [ record.def, record.readOnly ];
+    `, ",")
+
+	test(`raise:
+        (function(){
+            "use strict";
+            record.readOnly = 1;
+        })();
+    `, "TypeError: Cannot assign to property 'readOnly' of a host object")
+
+	host.property["readOnly"] = toValue_int(2)
+	host.log = nil
+	test(`
+        [ delete record.xyz, delete record.readOnly, record.readOnly ];
+    `, "true,false,2")
+	Is(host.log, []string{"get:readOnly", "delete:readOnly", "get:readOnly"}) // Not delete:xyz
+
+	test(`raise:
+        (function(){
+            "use strict";
+            delete record.readOnly;
+        })();
+    `, "TypeError: Cannot delete property 'readOnly' of a host object")
+
+	test(`raise:
+        Object.defineProperty(record, "readOnly", { value: 3, writable: true, enumerable: true, configurable: true });
+    `, "TypeError: Cannot define property 'readOnly' of a host object")
+
+	test(`raise:
+        Object.defineProperty(record, "xyz", { get: function() {} });
+    `, "TypeError: Cannot define property 'xyz' of a host object (except as a writable, enumerable, and configurable value)")
+
+	value, _ := Otto.Get("record")
+	export, _ := value.Export()
+	Is(export == host, true)
+
+	failSet("callable", &testHostFunction{testHostObject{property: map[string]Value{}}})
+	test(`
+        [ typeof callable, callable("abc"), new callable("def").constructed ];
+    `, "function,called:abc,def")
+
+	// A panic (of a method of the HostObject) is an Error
+	failSet("panic", &testPanicHostObject{testHostObject{property: map[string]Value{"abc": toValue_int(1)}}})
+	test(`
+        var result = [];
+        try {
+            panic.abc;
+        } catch (error) {
+            result.push(error.message);
+        }
+        try {
+            Object.keys(panic);
+        } catch (error) {
+            result.push(error.message);
+        }
+        result;
+    `, "boom,no keys")
+
+	test(`raise:
+        panic.abc;
+    `, "Error: boom")
+}
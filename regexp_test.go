@@ -268,3 +268,109 @@ func TestRegExp_compile(t *testing.T) {
         abc.compile('^\w+');
     `, "undefined")
 }
+
+func TestRegExp_backtrack(t *testing.T) {
+	Terst(t)
+
+	test := runTest()
+
+	// Lookahead
+	test(`/Xyzzy(?!Nothing happens)/.test("Xyzzy, something happens")`, "true")
+	test(`/Xyzzy(?!Nothing happens)/.test("XyzzyNothing happens")`, "false")
+	test(`/^(?=.*\d)(?=.*[a-z]).{6,}$/.test("abc123")`, "true")
+	test(`/^(?=.*\d)(?=.*[a-z]).{6,}$/.test("abcdef")`, "false")
+	test(`/(?=(a+))a*b\1/.exec("baaabac")`, "aba,a")
+	test(`/(.*?)a(?!(a+)b\2c)\2(.*)/.exec("baaabaac")`, "baaabaac,ba,,abaac")
+
+	// Backreference
+	test(`/(\w)\1/.exec("abccd")`, "cc,c")
+	test(`/<(\w+)>.*<\/\1>/.test("<b>bold</b>")`, "true")
+	test(`/<(\w+)>.*<\/\1>/.test("<b>bold</i>")`, "false")
+	test(`/(a)\1/i.test("aA")`, "true")
+	test(`/\1(a)/.exec("aa")`, "a,a")
+	test(`"abab-cdcd-efgh".replace(/(\w\w)\1/g, "[$1]")`, "[ab]-[cd]-efgh")
+	test(`"a-b--c".split(/(-)\1?/)`, "a,-,b,-,c")
+
+	// A repetition resets the captures within it
+	test(`
+        var abc = /(z)((a+)?(b+)?(c))*/.exec("zaacbbbcac");
+        [ abc.length, abc[0], abc[1], abc[2], abc[3], abc[4], abc[5] ].join(",");
+    `, "6,zaacbbbcac,z,ac,a,,c")
+	test(`typeof /(z)((a+)?(b+)?(c))*/.exec("zaacbbbcac")[4]`, "undefined")
+	test(`/((a)|b)+/.exec("ab").join(",")`, "ab,b,")
+
+	// \s includes \v, etc.
+	test(`/^\s+$/.test("\v \u00a0\ufeff")`, "true")
+	test(`/^[\s]+$/.test("\v \u2003")`, "true")
+	test(`/\S/.test("\v")`, "false")
+
+	// lastIndex is in UTF-16 code units
+	test(`
+        var abc = /(?=b)./g;
+        abc.exec("ééb");
+        [ abc.lastIndex, "ééb".length ];
+    `, "3,3")
+
+	test(`
+        var abc = /\d/g;
+        abc.lastIndex = 2;
+        var def = abc.exec("1é23");
+        [ def, def.index, abc.lastIndex ];
+    `, "2,2,3")
+
+	test(`raise:
+        /(a+)+(?=b)/.test("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa");
+    `, "RangeError: Regular expression is too complex (backtracking limit exceeded)")
+
+	test(`raise:
+        new RegExp("(a)(?=b)\\2");
+    `, "SyntaxError: Invalid regular expression: invalid escape sequence: `\\2`")
+}
+
+func Test_compileRegExp(t *testing.T) {
+	Terst(t)
+
+	matcher, err := compileRegExp(`a(?=b)`, false, false)
+	Is(err, nil)
+	_, backtrack := matcher.(*_regExpProgram)
+	IsTrue(backtrack)
+
+	matcher, err = compileRegExp(`a\s`, false, false)
+	Is(err, nil)
+	_, backtrack = matcher.(*_regExpProgram)
+	IsFalse(backtrack)
+
+	Is(matcher.FindAllStringIndex("a\va a", -1), [][]int{{0, 2}, {2, 4}})
+
+	for pattern, valid := range map[string]bool{
+		`(a)*`:     true,
+		`(?:a|b)+`: true,
+		`((a)|b)+`: false,
+		`(?:(a))?`: false,
+		`((?:a))*`: true,
+		`(a(b))c*`: true,
+		`\((a)\)+`: true,
+		`[(a)]+`:   true,
+	} {
+		Is(isValidRegExp(pattern), valid)
+	}
+
+	program, err := compileRegExpProgram(`a*`, false, false)
+	Is(err, nil)
+	Is(program.FindAllStringIndex("baaac", -1), [][]int{{0, 0}, {1, 4}, {5, 5}})
+	Is(program.FindStringSubmatchIndex("xéy"), []int{0, 0})
+
+	program, err = compileRegExpProgram(`(é)(y)?`, false, false)
+	Is(err, nil)
+	Is(program.FindStringSubmatchIndex("xéz"), []int{1, 3, 1, 3, -1, -1})
+
+	// The groups of a repetition are undefined at the start of each repetition (15.10.2.5)
+	program, err = compileRegExpProgram(`(z)((a+)?(b+)?(c))*`, false, false)
+	Is(err, nil)
+	Is(program.FindStringSubmatchIndex("zaacbbbcac"), []int{0, 10, 0, 1, 8, 10, 8, 9, -1, -1, 9, 10})
+
+	_, err = compileRegExpProgram(`a{2,1}`, false, false)
+	Is(err, "numbers out of order in {} quantifier: `{2,1}`")
+	_, err = compileRegExpProgram(`[b-a]`, false, false)
+	Is(err, "invalid character class range: `b-a`")
+}
@@ -7,9 +7,12 @@ package registry
 
 var registry []*Entry = make([]*Entry, 0)
 
+var nativeRegistry map[string]*Entry = make(map[string]*Entry)
+
 type Entry struct {
 	active bool
 	source func() string
+	native interface{}
 }
 
 func newEntry(source func() string) *Entry {
@@ -27,7 +30,11 @@ func (self *Entry) Disable() {
 	self.active = false
 }
 
+// Source returns the source of the entry, which is empty for a native module.
 func (self Entry) Source() string {
+	if self.source == nil {
+		return ""
+	}
 	return self.source()
 }
 
@@ -45,3 +52,56 @@ func Register(source func() string) *Entry {
 	registry = append(registry, entry)
 	return entry
 }
+
+// RegisterNative registers a module implemented in Go, which a script can
+// then load (lazily) by name with require:
+//
+//		registry.RegisterNative("crypto", func(vm *otto.Otto) *otto.Object {
+//			crypto, _ := vm.Object(`({})`)
+//			crypto.Set("md5", func(call otto.FunctionCall) otto.Value {
+//				...
+//			})
+//			return crypto
+//		})
+//
+//		Otto.Run(`var crypto = require("crypto")`)
+//
+// The loader must be a func(*otto.Otto) *otto.Object (this package cannot
+// refer to otto), and is called at most once for each runtime, the first time
+// the module is required. The object it returns becomes the exports of the
+// module.
+//
+// A native module takes precedence over a module of the same name from the
+// module loader of the runtime. Registering a second module with the same
+// name replaces the first.
+//
+// A runtime only has a (global) require if there was a native module when it
+// was made, or if it has a module loader.
+func RegisterNative(name string, loader interface{}) *Entry {
+	entry := &Entry{
+		active: true,
+		native: loader,
+	}
+	nativeRegistry[name] = entry
+	return entry
+}
+
+// HasNative returns whether there is any native module (that is not disabled).
+func HasNative() bool {
+	for _, entry := range nativeRegistry {
+		if entry.active {
+			return true
+		}
+	}
+	return false
+}
+
+// Native returns the loader of the native module with the given name, or nil
+// if there is no such module (or it is disabled).
+func Native(name string) interface{} {
+	entry, exists := nativeRegistry[name]
+	if !exists || !entry.active {
+		return nil
+	}
+	return entry.native
+}
@@ -0,0 +1,225 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/robertkrimen/otto"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// _lineEditor is a LineReader for a terminal, with (minimal) line editing, a
+// history, and <tab> completion (see Completer):
+//
+//		<left> <right> ^B ^F    Move the cursor back or forward
+//		<home> <end> ^A ^E      Move the cursor to the start or end of the line
+//		<backspace> <delete>    Delete the character before or at the cursor
+//		^K ^U                   Delete the line after or before the cursor
+//		<up> <down> ^P ^N       Go back or forward in the history
+//		<tab>                   Complete the name at the cursor (or list the completions)
+//		^C                      Abandon the line
+//		^D                      End the input (on an empty line)
+//
+// The terminal is put in raw mode (by raw, if not nil) only while a line is read.
+type _lineEditor struct {
+	input    *bufio.Reader
+	output   io.Writer
+	raw      func() (func(), error) // Puts the terminal in raw mode, returning a function that restores it
+	complete func(line string, pos int) (string, []string, string)
+	history  []string
+}
+
+func newLineEditor(vm *otto.Otto, input io.Reader, output io.Writer) *_lineEditor {
+	return &_lineEditor{
+		input:    bufio.NewReader(input),
+		output:   output,
+		complete: Completer(vm),
+	}
+}
+
+func (self *_lineEditor) AppendHistory(line string) {
+	if length := len(self.history); length > 0 && self.history[length-1] == line {
+		return
+	}
+	self.history = append(self.history, line)
+}
+
+func (self *_lineEditor) Prompt(prompt string) (string, error) {
+	if self.raw != nil {
+		restore, err := self.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	line := []rune{}
+	pos := 0                     // The cursor, in runes
+	history := len(self.history) // The line of the history being edited (or the new line)
+	edited := ""                 // The new line, while going back in the history
+	fmt.Fprint(self.output, prompt)
+	for {
+		chr, _, err := self.input.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				fmt.Fprint(self.output, "\n")
+				return string(line), nil
+			}
+			return "", err
+		}
+
+		switch chr {
+		case '\r', '\n':
+			fmt.Fprint(self.output, "\n")
+			return string(line), nil
+		case 3: // ^C
+			fmt.Fprint(self.output, "^C\n")
+			return "", nil
+		case 4: // ^D
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case 1: // ^A
+			pos = 0
+		case 5: // ^E
+			pos = len(line)
+		case 2: // ^B
+			if pos > 0 {
+				pos -= 1
+			}
+		case 6: // ^F
+			if pos < len(line) {
+				pos += 1
+			}
+		case 8, 127: // ^H, <backspace>
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos -= 1
+			}
+		case 11: // ^K
+			line = line[:pos]
+		case 21: // ^U
+			line = line[pos:]
+			pos = 0
+		case 16, 14: // ^P, ^N
+			line, pos, history, edited = self.browse(chr == 16, line, pos, history, edited)
+		case '\t':
+			line, pos = self.tab(line, pos)
+		case 27: // <escape>, the start of a sequence (for a key like <up>)
+			switch self.escape() {
+			case "A": // <up>
+				line, pos, history, edited = self.browse(true, line, pos, history, edited)
+			case "B": // <down>
+				line, pos, history, edited = self.browse(false, line, pos, history, edited)
+			case "C": // <right>
+				if pos < len(line) {
+					pos += 1
+				}
+			case "D": // <left>
+				if pos > 0 {
+					pos -= 1
+				}
+			case "H", "1~", "7~": // <home>
+				pos = 0
+			case "F", "4~", "8~": // <end>
+				pos = len(line)
+			case "3~": // <delete>
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if chr < ' ' {
+				continue // Ignore any other control character
+			}
+			line = append(line[:pos], append([]rune{chr}, line[pos:]...)...)
+			pos += 1
+		}
+		self.redraw(prompt, line, pos)
+	}
+}
+
+// escape reads the rest of an escape sequence (after the <escape>), and returns
+// the parameters and the final character (e.g. "A" for <up>, or "3~" for
+// <delete>), or "" if it is not a (CSI or SS3) sequence.
+func (self *_lineEditor) escape() string {
+	chr, _, err := self.input.ReadRune()
+	if err != nil || chr != '[' && chr != 'O' {
+		return ""
+	}
+	sequence := ""
+	for {
+		chr, _, err := self.input.ReadRune()
+		if err != nil {
+			return ""
+		}
+		sequence += string(chr)
+		if '@' <= chr && chr <= '~' && !('0' <= chr && chr <= '9') {
+			return sequence
+		}
+	}
+}
+
+// browse goes back (or forward) in the history, keeping the new line (edited)
+// while the line is from the history.
+func (self *_lineEditor) browse(back bool, line []rune, pos int, history int, edited string) ([]rune, int, int, string) {
+	if back && history > 0 {
+		if history == len(self.history) {
+			edited = string(line)
+		}
+		history -= 1
+		line = []rune(self.history[history])
+	} else if !back && history < len(self.history) {
+		history += 1
+		if history == len(self.history) {
+			line = []rune(edited)
+		} else {
+			line = []rune(self.history[history])
+		}
+	}
+	return line, len(line), history, edited
+}
+
+// tab completes the name at the cursor: with the only completion, or with the
+// prefix common to every completion, or else by listing the completions.
+func (self *_lineEditor) tab(line []rune, pos int) ([]rune, int) {
+	if self.complete == nil {
+		return line, pos
+	}
+	head, completionList, tail := self.complete(string(line), len(string(line[:pos])))
+	if len(completionList) == 0 {
+		return line, pos
+	}
+
+	completion := []rune(completionList[0])
+	for _, each := range completionList[1:] {
+		each := []rune(each)
+		length := 0
+		for length < len(completion) && length < len(each) && completion[length] == each[length] {
+			length += 1
+		}
+		completion = completion[:length]
+	}
+	if len(completionList) > 1 && len([]rune(head))+len(completion) <= pos {
+		// Nothing more to complete, so list the completions
+		fmt.Fprint(self.output, "\n"+strings.Join(completionList, "  ")+"\n")
+		return line, pos
+	}
+
+	pos = len([]rune(head)) + len(completion)
+	return []rune(head + string(completion) + tail), pos
+}
+
+// redraw draws the line (after the prompt) over the current line of the terminal,
+// with the cursor at pos.
+func (self *_lineEditor) redraw(prompt string, line []rune, pos int) {
+	back := ""
+	if count := len(line) - pos; count > 0 {
+		back = "\x1b[" + strconv.Itoa(count) + "D"
+	}
+	fmt.Fprint(self.output, "\r"+prompt+string(line)+"\x1b[K"+back)
+}
@@ -0,0 +1,386 @@
+/*
+Package repl implements an interactive read-eval-print loop for an otto runtime.
+
+	import (
+		"github.com/robertkrimen/otto"
+		"github.com/robertkrimen/otto/repl"
+	)
+
+	repl.Run(otto.New())
+
+Input that is not yet complete (e.g. an unclosed function body) is continued
+on the next line, and the value of each statement is printed after it is run.
+The input is read by a LineReader. For Run, this is a line editor (with history
+and <tab> completion of the names of global variables and object properties, see
+Completer) if the standard input is a terminal (on Linux, macOS, or BSD), or
+else a plain reader of the standard input.
+
+The following commands are available at the prompt:
+
+	.break          Abandon the current (multi-line) input
+	.exit           Exit the REPL
+	.help           Show the available commands
+	.load <file>    Run a file in the runtime
+	.save <file>    Save the input of the session to a file
+*/
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/robertkrimen/otto"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A LineReader reads the input of the REPL, a line at a time. It can be another
+// line editor, such as github.com/peterh/liner (a *liner.State is a LineReader):
+//
+//		state := liner.NewLiner()
+//		defer state.Close()
+//		state.SetWordCompleter(repl.Completer(vm))
+//		repl.RunWithLineReader(vm, "> ", state)
+type LineReader interface {
+	// Prompt shows the prompt, and returns the next line (without the end of
+	// the line), or io.EOF at the end of the input.
+	Prompt(prompt string) (string, error)
+
+	// AppendHistory adds the (non-blank) line to the history, if there is one.
+	AppendHistory(line string)
+}
+
+// Run will start a REPL with the prompt "> ", reading from the standard input
+// and writing to the standard output, until the input ends (^D) or .exit is
+// entered.
+func Run(vm *otto.Otto) error {
+	return RunWithPrompt(vm, "> ")
+}
+
+// RunWithPrompt is like Run, but with the given prompt.
+func RunWithPrompt(vm *otto.Otto, prompt string) error {
+	// A line editor, if the standard input is a terminal (that can be put in raw mode)
+	if restore, err := makeRaw(os.Stdin.Fd()); err == nil {
+		restore()
+		editor := newLineEditor(vm, os.Stdin, os.Stdout)
+		editor.raw = func() (func(), error) {
+			return makeRaw(os.Stdin.Fd())
+		}
+		return RunWithLineReader(vm, prompt, editor)
+	}
+	return RunWithLineReader(vm, prompt, &_lineReader{
+		input:  bufio.NewReader(os.Stdin),
+		output: os.Stdout,
+	})
+}
+
+// RunWithLineReader is like RunWithPrompt, but reads the input from the
+// LineReader.
+func RunWithLineReader(vm *otto.Otto, prompt string, reader LineReader) error {
+	self := newREPL(vm, os.Stdout)
+	if info, err := os.Stdout.Stat(); err == nil {
+		self.color = info.Mode()&os.ModeCharDevice != 0 // A terminal
+	}
+
+	for !self.exit {
+		line, err := reader.Prompt(self.prompt(prompt))
+		if err != nil {
+			if err == io.EOF {
+				fmt.Fprintln(self.output)
+				return nil
+			}
+			return err
+		}
+		if strings.TrimSpace(line) != "" {
+			reader.AppendHistory(line)
+		}
+		self.input(line)
+	}
+	return nil
+}
+
+// _lineReader is a LineReader without line editing (or history), for input that
+// is not a terminal
+type _lineReader struct {
+	input  *bufio.Reader
+	output io.Writer
+}
+
+func (self *_lineReader) Prompt(prompt string) (string, error) {
+	fmt.Fprint(self.output, prompt)
+	line, err := self.input.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (self *_lineReader) AppendHistory(line string) {}
+
+// errIncomplete is the (syntax) error of input that may be continued
+const errIncomplete = "Unexpected end of input"
+
+type _repl struct {
+	vm      *otto.Otto
+	object  *otto.Object // The Object constructor (as it was when the REPL began)
+	output  io.Writer
+	color   bool
+	buffer  string   // The input so far, if incomplete
+	session []string // The (complete) input of the session, for .save
+	exit    bool
+}
+
+func newREPL(vm *otto.Otto, output io.Writer) *_repl {
+	object, _ := vm.Object("Object")
+	return &_repl{
+		vm:     vm,
+		object: object,
+		output: output,
+	}
+}
+
+// prompt is the prompt for the next line, which is "..." if the
+// input so far is incomplete.
+func (self *_repl) prompt(prompt string) string {
+	if self.buffer != "" {
+		return strings.Repeat(".", len(strings.TrimRight(prompt, " "))+2) + " "
+	}
+	return prompt
+}
+
+// input handles a single line of input.
+func (self *_repl) input(line string) {
+	// A command is only recognized at the start of the input (since the line
+	// could otherwise be a continuation like ".abc()"), except for .break
+	command := strings.TrimSpace(line)
+	if self.buffer == "" && strings.HasPrefix(command, ".") || command == ".break" {
+		self.command(strings.Fields(command))
+		return
+	}
+	if self.buffer == "" && strings.TrimSpace(line) == "" {
+		return
+	}
+
+	source := self.buffer + line + "\n"
+	script, err := otto.Compile("repl", source)
+	if err != nil {
+		if err, ok := err.(*otto.Error); ok && err.Message == errIncomplete {
+			self.buffer = source
+			return
+		}
+		self.buffer = ""
+		self.printError(err)
+		return
+	}
+	self.buffer = ""
+	self.session = append(self.session, source)
+
+	value, err := self.vm.RunScript(script)
+	if err != nil {
+		self.printError(err)
+		return
+	}
+	self.print(value)
+}
+
+func (self *_repl) print(value otto.Value) {
+	fmt.Fprintln(self.output, value.Inspect(otto.InspectOptions{Color: self.color}))
+}
+
+func (self *_repl) printError(err error) {
+	if err, ok := err.(*otto.Error); ok {
+		fmt.Fprintln(self.output, err.String())
+		for _, frame := range err.Stack {
+			fmt.Fprintln(self.output, "    at "+frame.String())
+		}
+		return
+	}
+	fmt.Fprintln(self.output, err)
+}
+
+var commandHelp = strings.TrimSpace(`
+.break          Abandon the current (multi-line) input
+.exit           Exit the REPL
+.help           Show the available commands
+.load <file>    Run a file in the runtime
+.save <file>    Save the input of the session to a file
+`)
+
+func (self *_repl) command(argumentList []string) {
+	argument := func() (string, error) {
+		if len(argumentList) < 2 {
+			return "", errors.New(argumentList[0] + ": missing filename")
+		}
+		return strings.Join(argumentList[1:], " "), nil
+	}
+
+	switch argumentList[0] {
+	case ".break":
+		self.buffer = ""
+	case ".exit":
+		self.exit = true
+	case ".help":
+		fmt.Fprintln(self.output, commandHelp)
+	case ".load":
+		filename, err := argument()
+		if err != nil {
+			self.printError(err)
+			return
+		}
+		source, err := ioutil.ReadFile(filename)
+		if err != nil {
+			self.printError(err)
+			return
+		}
+		self.session = append(self.session, string(source))
+		value, err := self.vm.RunFile(filename, string(source))
+		if err != nil {
+			self.printError(err)
+			return
+		}
+		self.print(value)
+	case ".save":
+		filename, err := argument()
+		if err != nil {
+			self.printError(err)
+			return
+		}
+		err = ioutil.WriteFile(filename, []byte(strings.Join(self.session, "")), 0644)
+		if err != nil {
+			self.printError(err)
+			return
+		}
+		fmt.Fprintf(self.output, "Session saved to: %s\n", filename)
+	default:
+		fmt.Fprintf(self.output, "Invalid REPL keyword: %s (try .help)\n", argumentList[0])
+	}
+}
+
+var keywordList = []string{
+	"break", "case", "catch", "continue", "debugger", "default", "delete",
+	"do", "else", "false", "finally", "for", "function", "if", "in",
+	"instanceof", "new", "null", "return", "switch", "this", "throw", "true",
+	"try", "typeof", "var", "void", "while", "with",
+}
+
+// Completer returns the completer of the REPL, for a line editor (e.g. the
+// SetWordCompleter of github.com/peterh/liner), which completes the name of a
+// global variable (or keyword), or the property of an object, in the line (at
+// pos):
+//
+//		Ma          => Math
+//		Math.fl     => Math.floor
+//		abc.def.g   => abc.def.ghi
+//
+// The object is found by looking up each name of the path in turn, without
+// calling a getter (or running any other code of the script).
+func Completer(vm *otto.Otto) func(line string, pos int) (head string, completionList []string, tail string) {
+	return newREPL(vm, ioutil.Discard).complete
+}
+
+func (self *_repl) complete(line string, pos int) (head string, completionList []string, tail string) {
+	head, tail = line[:pos], line[pos:]
+
+	start := len(head)
+	for start > 0 && (isIdentifierPart(head[start-1]) || head[start-1] == '.') {
+		start -= 1
+	}
+	word := head[start:]
+	head = head[:start]
+
+	path := strings.Split(word, ".")
+	word = path[len(path)-1]
+	path = path[:len(path)-1]
+	prefix := ""
+	if len(path) > 0 {
+		prefix = strings.Join(path, ".") + "."
+	}
+
+	object, err := self.vm.Object("this")
+	if err != nil {
+		return
+	}
+	for _, name := range path {
+		value, exists := self.lookup(object, name)
+		if !exists || !value.IsObject() {
+			return
+		}
+		object = value.Object()
+	}
+
+	seen := map[string]bool{}
+	nameList := []string{}
+	for _, keys := range object.KeysByParent() {
+		for _, name := range keys {
+			if !seen[name] && strings.HasPrefix(name, word) && isIdentifier(name) {
+				seen[name] = true
+				nameList = append(nameList, name)
+			}
+		}
+	}
+	if len(path) == 0 {
+		for _, name := range keywordList {
+			if !seen[name] && strings.HasPrefix(name, word) {
+				seen[name] = true
+				nameList = append(nameList, name)
+			}
+		}
+	}
+	sort.Strings(nameList)
+
+	for _, name := range nameList {
+		completionList = append(completionList, prefix+name)
+	}
+	return
+}
+
+// lookup returns the value of the property of the object (or of its prototype
+// chain), by its property descriptor, or false if there is no such property, or
+// it is an accessor (since the getter is not called).
+func (self *_repl) lookup(object *otto.Object, name string) (otto.Value, bool) {
+	if self.object == nil {
+		return otto.Value{}, false
+	}
+	for value := object.Value(); value.IsObject(); {
+		descriptor, err := self.object.Call("getOwnPropertyDescriptor", value, name)
+		if err != nil {
+			return otto.Value{}, false
+		}
+		if descriptor.IsObject() {
+			// A property descriptor is a plain object, so Get runs no code
+			get, _ := descriptor.Object().Get("get")
+			set, _ := descriptor.Object().Get("set")
+			if get.IsDefined() || set.IsDefined() {
+				return otto.Value{}, false
+			}
+			value, err := descriptor.Object().Get("value")
+			return value, err == nil
+		}
+		value, err = self.object.Call("getPrototypeOf", value)
+		if err != nil {
+			return otto.Value{}, false
+		}
+	}
+	return otto.Value{}, false
+}
+
+func isIdentifierPart(chr byte) bool {
+	return chr == '$' || chr == '_' ||
+		'a' <= chr && chr <= 'z' || 'A' <= chr && chr <= 'Z' ||
+		'0' <= chr && chr <= '9' || chr >= 0x80
+}
+
+func isIdentifier(name string) bool {
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		return false
+	}
+	for index := 0; index < len(name); index++ {
+		if !isIdentifierPart(name[index]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package repl
+
+import (
+	. "../terst"
+	"bufio"
+	"bytes"
+	"github.com/robertkrimen/otto"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPL_input(t *testing.T) {
+	Terst(t)
+
+	output := &bytes.Buffer{}
+	self := newREPL(otto.New(), output)
+
+	self.input("1 + 2")
+	Is(output.String(), "3\n")
+	Is(self.prompt("> "), "> ")
+
+	output.Reset()
+	self.input("function abc(def) {")
+	Is(output.String(), "")
+	Is(self.prompt("> "), "... ")
+	self.input("    return { def: def, ghi: [ 1, 'jkl' ] };")
+	Is(output.String(), "")
+	self.input("}")
+	Is(output.String(), "undefined\n")
+	Is(self.prompt("> "), "> ")
+	output.Reset()
+	self.input("abc(true)")
+	Is(output.String(), "{ def: true, ghi: [ 1, 'jkl' ] }\n")
+
+	output.Reset()
+	self.input("var xyzzy = [")
+	self.input(".break")
+	Is(self.prompt("> "), "> ")
+	self.input("xyzzy")
+	Is(output.String(), "ReferenceError: xyzzy is not defined\n    at repl:1:1\n")
+
+	output.Reset()
+	self.input("abc = }")
+	Is(output.String(), "SyntaxError: Unexpected token }\n")
+	Is(self.prompt("> "), "> ")
+
+	output.Reset()
+	self.input(".nothing")
+	Is(output.String(), "Invalid REPL keyword: .nothing (try .help)\n")
+
+	self.input(".exit")
+	Is(self.exit, true)
+}
+
+func TestREPL_loadSave(t *testing.T) {
+	Terst(t)
+
+	directory, err := ioutil.TempDir("", "otto-repl")
+	Is(err, nil)
+	defer os.RemoveAll(directory)
+	filename := filepath.Join(directory, "session.js")
+
+	output := &bytes.Buffer{}
+	self := newREPL(otto.New(), output)
+	self.input("var abc = 1;")
+	self.input("function def() {")
+	self.input("    return abc + 1;")
+	self.input("}")
+	self.input("ghi = ]") // Not saved
+	output.Reset()
+	self.input(".save " + filename)
+	Is(output.String(), "Session saved to: "+filename+"\n")
+
+	source, err := ioutil.ReadFile(filename)
+	Is(err, nil)
+	Is(string(source), "var abc = 1;\nfunction def() {\n    return abc + 1;\n}\n")
+
+	output.Reset()
+	self = newREPL(otto.New(), output)
+	self.input(".load " + filename)
+	self.input("def()")
+	Is(output.String(), "undefined\n2\n")
+
+	output.Reset()
+	self.input(".load")
+	Is(output.String(), ".load: missing filename\n")
+}
+
+func TestREPL_complete(t *testing.T) {
+	Terst(t)
+
+	self := newREPL(otto.New(), ioutil.Discard)
+	self.input("var xyzzy = { abc: { def: 1, deg: 2 }, 'j-k': 3 };")
+
+	head, completionList, tail := self.complete("xyz", 3)
+	Is(head, "")
+	Is(completionList, []string{"xyzzy"})
+	Is(tail, "")
+
+	head, completionList, tail = self.complete("1 + xyzzy.abc.d", 15)
+	Is(head, "1 + ")
+	Is(completionList, []string{"xyzzy.abc.def", "xyzzy.abc.deg"})
+	Is(tail, "")
+
+	head, completionList, tail = self.complete("Math.fl(1)", 7)
+	Is(head, "")
+	Is(completionList, []string{"Math.floor"})
+	Is(tail, "(1)")
+
+	_, completionList, _ = self.complete("xyzzy.", 6)
+	Is(completionList[0], "xyzzy.abc") // But not 'j-k'
+	Is(len(completionList) > 1, true)  // Object.prototype
+
+	_, completionList, _ = self.complete("typ", 3)
+	Is(completionList, []string{"typeof"})
+
+	_, completionList, _ = self.complete("nothing.ab", 10)
+	Is(len(completionList), 0)
+
+	// A getter is not called
+	self.input("var called = false, jkl = Object.create({ get mno() { called = true; return { pqr: 1 } } });")
+	_, completionList, _ = self.complete("jkl.mno.p", 9)
+	Is(len(completionList), 0)
+	_, completionList, _ = Completer(self.vm)("jkl.m", 5)
+	Is(completionList, []string{"jkl.mno"})
+	value, _ := self.vm.Get("called")
+	Is(value, "false")
+}
+
+func TestREPL_lineReader(t *testing.T) {
+	Terst(t)
+
+	output := &bytes.Buffer{}
+	reader := &_lineReader{
+		input:  bufio.NewReader(strings.NewReader("1 + 2\r\nabc")),
+		output: output,
+	}
+	line, err := reader.Prompt("> ")
+	Is(err, nil)
+	Is(line, "1 + 2")
+	line, err = reader.Prompt("> ")
+	Is(err, nil)
+	Is(line, "abc")
+	_, err = reader.Prompt("> ")
+	Is(err, io.EOF)
+	Is(output.String(), "> > > ")
+}
+
+func TestREPL_lineEditor(t *testing.T) {
+	Terst(t)
+
+	vm := otto.New()
+	vm.Run(`var xyzzy = { abc: { def: 1, deg: 2 } };`)
+	input := strings.Join([]string{
+		"xy\t.a\t.d\t\tf(1)\r",             // xyzzy.abc.def(1), listing def and deg
+		"\x1b[A\x1b[D\x7f2\r",              // Up, left, backspace, 2
+		"abc\x1b[H1 + \x1b[F\x1b[3~ + 3\r", // Home, end, delete (nothing)
+		"ghi\x03",                          // ^C
+		"jkl\x1b[A\x1b[B\r",                // Up, down (back to jkl)
+		"\x04",                             // ^D
+	}, "")
+	output := &bytes.Buffer{}
+	editor := newLineEditor(vm, strings.NewReader(input), output)
+
+	line, err := editor.Prompt("> ")
+	Is(err, nil)
+	Is(line, "xyzzy.abc.def(1)")
+	Is(strings.Contains(output.String(), "\nxyzzy.abc.def  xyzzy.abc.deg\n"), true)
+	editor.AppendHistory(line)
+
+	for _, expect := range []string{"xyzzy.abc.def(2)", "1 + abc + 3", "", "jkl"} {
+		line, err = editor.Prompt("> ")
+		Is(err, nil)
+		Is(line, expect)
+		if line != "" {
+			editor.AppendHistory(line)
+		}
+	}
+
+	_, err = editor.Prompt("> ")
+	Is(err, io.EOF)
+	Is(editor.history, []string{"xyzzy.abc.def(1)", "xyzzy.abc.def(2)", "1 + abc + 3", "jkl"})
+}
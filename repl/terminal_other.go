@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package repl
+
+import (
+	"errors"
+)
+
+// makeRaw fails, since raw mode is not supported (so there is no line editing).
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("repl: raw mode is not supported")
+}
@@ -1,10 +1,68 @@
 package otto
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"strconv"
 )
 
+// ErrInterrupted is returned by RunContext (and CallContext) when execution is
+// aborted because the context was cancelled.
+var ErrInterrupted = errors.New("otto: execution interrupted")
+
+// _interrupt is panicked (during evaluation) when the context of the runtime is done
+type _interrupt struct {
+	err error
+}
+
+// _interruptPanic is a panic of an Interrupt function (e.g. Halt), which goes to the
+// caller of Run, etc. as is (rather than being converted to an Error by a native
+// function it passes through)
+type _interruptPanic struct {
+	runtime *_runtime
+	value   interface{}
+}
+
+func (self *_runtime) checkContext() {
+	if err := self.contextErr(); err != nil {
+		panic(&_interrupt{err})
+	}
+}
+
+// contextErr returns the error for the first of the contexts that is done (the
+// outermost), or nil if none is.
+func (self *_runtime) contextErr() error {
+	for index, done := range self.done {
+		select {
+		case <-done:
+			if self.context[index].Err() == context.DeadlineExceeded {
+				return context.DeadlineExceeded
+			}
+			return ErrInterrupted
+		default:
+		}
+	}
+	return nil
+}
+
+// withContext runs the given function with execution bound to the context, as
+// well as to the context of any (outer) call that it is nested in (e.g. from a
+// native function), so that a nested call cannot escape the outer deadline.
+func (self *_runtime) withContext(ctx context.Context, function func() error) error {
+	length := len(self.context)
+	self.context = append(self.context, ctx)
+	self.done = append(self.done, ctx.Done())
+	defer func() {
+		self.context[length], self.done[length] = nil, nil
+		self.context, self.done = self.context[:length], self.done[:length]
+	}()
+	if err := self.contextErr(); err != nil {
+		return err
+	}
+	return function()
+}
+
 type _global struct {
 	Object         *_object // Object( ... ), new Object( ... ) - 1 (length)
 	Function       *_object // Function( ... ), new Function( ... ) - 1
@@ -49,13 +107,34 @@ type _runtime struct {
 
 	Global _global
 
-	eval *_object // The builtin eval, for determine indirect versus direct invocation
+	eval    *_object // The builtin eval, for determine indirect versus direct invocation
+	thrower *_object // The function that throws a TypeError, for the arguments of strict functions (made when first needed)
 
 	Otto *Otto
+
+	context []context.Context // The contexts of RunContext/CallContext (outermost first), if any
+	done    []<-chan struct{} // The Done() of each context, checked during evaluation
+
+	limits Limits
+	usage  _usage
+
+	console *_console
+	modules *_moduleSystem // The modules of require (made when first needed)
+
+	debugger *Debugger
+	tracer   Tracer
+	coverage *Coverage
+
+	native int // The depth of native functions (see callNative)
+
+	fieldNameMapper FieldNameMapper
+	goStructTypes   map[reflect.Type]*_goStructType // By FieldNameMapper (made when first needed)
+
+	utf16Position _utf16Position // The last position found in a string (see utf16Index)
 }
 
 func (self *_runtime) EnterGlobalExecutionContext() {
-	self.EnterExecutionContext(newExecutionContext(self.GlobalEnvironment, self.GlobalEnvironment, self.GlobalObject))
+	self.EnterExecutionContext(newExecutionContext(self.GlobalEnvironment, self.GlobalEnvironment, toValue_object(self.GlobalObject)))
 }
 
 func (self *_runtime) EnterExecutionContext(scope *_executionContext) {
@@ -82,14 +161,27 @@ func (self *_runtime) EnterFunctionExecutionContext(function *_object, this Valu
 		scopeEnvironment = self.GlobalEnvironment
 	}
 	environment := self.newFunctionEnvironment(scopeEnvironment)
-	var thisObject *_object
-	switch this._valueType {
-	case valueUndefined, valueNull:
-		thisObject = self.GlobalObject
-	default:
-		thisObject = self.toObject(this)
+	strict := false
+	if node := functionNode(function); node != nil {
+		strict = node.strict
 	}
-	self.EnterExecutionContext(newExecutionContext(environment, environment, thisObject))
+	if strict {
+		// The this of strict code is not coerced (to an object, or the global object)
+		if this.isEmpty() {
+			this = UndefinedValue()
+		}
+	} else {
+		switch this._valueType {
+		case valueUndefined, valueNull:
+			this = toValue_object(self.GlobalObject)
+		default:
+			this = toValue_object(self.toObject(this))
+		}
+	}
+	executionContext := newExecutionContext(environment, environment, this)
+	executionContext.function = function
+	executionContext.strict = strict
+	self.EnterExecutionContext(executionContext)
 	return environment
 }
 
@@ -101,9 +193,22 @@ func (self *_runtime) EnterEvalExecutionContext(call FunctionCall) {
 	new := newExecutionContext(parent.LexicalEnvironment, parent.VariableEnvironment, parent.this)
 	// FIXME Make passing through of self.GlobalObject more general? Whenever newExecutionContext is passed a nil object?
 	new.eval = true
+	new.strict = parent.strict
 	self.EnterExecutionContext(new)
 }
 
+// stackTrace returns the stack of the runtime, beginning with the innermost frame.
+// A native (Go) function does not get a frame of its own.
+func (self *_runtime) stackTrace() []Frame {
+	stack := make([]Frame, 0, len(self.Stack))
+	for index := len(self.Stack) - 1; index >= 0; index-- {
+		if frame, valid := self.Stack[index].frame(); valid {
+			stack = append(stack, frame)
+		}
+	}
+	return stack
+}
+
 func (self *_runtime) GetValue(value Value) Value {
 	if value.isReference() {
 		return value.reference().GetValue()
@@ -111,11 +216,34 @@ func (self *_runtime) GetValue(value Value) Value {
 	return value
 }
 
+// strict returns whether the code being evaluated is strict mode code.
+func (self *_runtime) strict() bool {
+	return self._executionContext(0).strict
+}
+
+// typeErrorThrower returns the function that throws a TypeError, the getter and setter
+// of arguments.callee and arguments.caller in strict code.
+func (self *_runtime) typeErrorThrower() *_object {
+	if self.thrower == nil {
+		self.thrower = self.newNativeFunction(func(FunctionCall) Value {
+			panic(newTypeError("'caller' and 'callee' may not be accessed in strict mode"))
+		})
+	}
+	return self.thrower
+}
+
 func (self *_runtime) PutValue(reference _reference, value Value) {
 	if !reference.PutValue(value) {
 		// Why? -- If reference.Base == nil
-		strict := false
-		self.GlobalObject.defineProperty(reference.GetName(), value, 0111, strict)
+		if reference.IsStrict() {
+			// Assignment to an undeclared variable
+			var node _node
+			if reference, ok := reference.(*_propertyReference); ok {
+				node = reference.node
+			}
+			panic(newReferenceError("notDefined", reference.GetName(), node))
+		}
+		self.GlobalObject.defineProperty(reference.GetName(), value, 0111, false)
 	}
 }
 
@@ -138,12 +266,22 @@ func (self *_runtime) _callNode(function *_object, environment *_functionEnviron
 	}
 
 	if !node.ArgumentsIsParameter {
+		if node.strict {
+			// The arguments of a strict function are not mapped to its parameters
+			indexOfParameterName = make([]string, len(argumentList))
+		}
 		arguments := self.newArgumentsObject(indexOfParameterName, environment, len(argumentList))
-		arguments.defineProperty("callee", toValue_object(function), 0101, false)
+		if node.strict {
+			thrower := self.typeErrorThrower()
+			arguments.defineOwnProperty("callee", _property{_propertyGetSet{thrower, thrower}, 0000}, false)
+			arguments.defineOwnProperty("caller", _property{_propertyGetSet{thrower, thrower}, 0000}, false)
+		} else {
+			arguments.defineProperty("callee", toValue_object(function), 0101, false)
+		}
 		environment.arguments = arguments
 		self.localSet("arguments", toValue_object(arguments))
 		for index, _ := range argumentList {
-			if index < len(node.ParameterList) {
+			if index < len(node.ParameterList) && !node.strict {
 				continue
 			}
 			indexAsString := strconv.FormatInt(int64(index), 10)
@@ -163,11 +301,20 @@ func (self *_runtime) _callNode(function *_object, environment *_functionEnviron
 }
 
 func (self *_runtime) Call(function *_object, this Value, argumentList []Value, evalHint bool) Value {
+	if self.limits.CallDepth >= 0 {
+		self.checkCallDepth()
+	}
 	// Pass eval boolean through to EnterFunctionExecutionContext for further testing
 	_functionEnvironment := self.EnterFunctionExecutionContext(function, this)
 	defer func() {
 		self.LeaveExecutionContext()
 	}()
+	if self.tracer != nil {
+		if frame, valid := traceFunction(function); valid {
+			self.tracer.OnEnterFunction(frame)
+			defer self.tracer.OnExitFunction(frame)
+		}
+	}
 
 	if evalHint {
 		evalHint = function == self.eval // If evalHint is true, then it IS a direct eval
@@ -179,6 +326,23 @@ func (self *_runtime) Call(function *_object, this Value, argumentList []Value,
 	return callValue
 }
 
+// errorObject returns the Error object of the error (raised by the runtime itself),
+// which is the same object each time (e.g. for the debugger, and then for catch),
+// once the error has its object.
+func (self *_runtime) errorObject(caught _error) *_object {
+	if caught.object != nil {
+		return caught.object
+	}
+	error := self.newError(caught.Name, caught.MessageValue())
+	if caught.trace != nil {
+		self.setErrorStack(error, caught.trace)
+	}
+	if caught.err != nil {
+		self.setGoError(error, caught.err)
+	}
+	return error
+}
+
 func (self *_runtime) tryCatchEvaluate(inner func() Value) (tryValue Value, exception bool) {
 	// resultValue = The value of the block (e.g. the last statement)
 	// throw = Something was thrown
@@ -193,7 +357,7 @@ func (self *_runtime) tryCatchEvaluate(inner func() Value) (tryValue Value, exce
 			switch caught := caught.(type) {
 			case _error:
 				exception = true
-				tryValue = toValue_object(self.newError(caught.Name, caught.MessageValue()))
+				tryValue = toValue_object(self.errorObject(caught))
 			case *_syntaxError:
 				exception = true
 				tryValue = toValue_object(self.newError("SyntaxError", toValue_string(caught.Message)))
@@ -213,6 +377,7 @@ func (self *_runtime) tryCatchEvaluate(inner func() Value) (tryValue Value, exce
 func (self *_runtime) declare(kind string, declarationList []_declaration) {
 	executionContext := self._executionContext(0)
 	eval := executionContext.eval
+	strict := executionContext.strict
 	environment := executionContext.VariableEnvironment
 
 	for _, _declaration := range declarationList {
@@ -223,11 +388,11 @@ func (self *_runtime) declare(kind string, declarationList []_declaration) {
 				environment.CreateMutableBinding(name, eval == true)
 			}
 			// TODO 10.5.5.e
-			environment.SetMutableBinding(name, value, false) // TODO strict
+			environment.SetMutableBinding(name, value, strict)
 		} else {
 			if !environment.HasBinding(name) {
 				environment.CreateMutableBinding(name, eval == true)
-				environment.SetMutableBinding(name, UndefinedValue(), false) // TODO strict
+				environment.SetMutableBinding(name, UndefinedValue(), strict)
 			}
 		}
 	}
@@ -294,7 +459,7 @@ func testObjectCoercible(value Value) (isObject bool, mustCoerce bool) {
 
 func (self *_runtime) ToValue(value interface{}) (Value, error) {
 	result := UndefinedValue()
-	err := catchPanic(func() {
+	err := self.catchPanic(func() {
 		result = self.toValue(value)
 	})
 	return result, err
@@ -311,6 +476,8 @@ func (self *_runtime) toValue(value interface{}) Value {
 	case Object, *Object, _object, *_object:
 		// Nothing happens.
 		// FIXME
+	case HostObject:
+		return toValue_object(self.newHostObject(value))
 	default:
 		{
 			value := reflect.ValueOf(value)
@@ -323,20 +490,7 @@ func (self *_runtime) toValue(value interface{}) Value {
 					return toValue_object(self.newGoArray(value))
 				}
 			case reflect.Func:
-				return toValue_object(self.newNativeFunction(func(call FunctionCall) Value {
-					args := make([]reflect.Value, len(call.ArgumentList))
-					for i, a := range call.ArgumentList {
-						args[i] = reflect.ValueOf(a.export())
-					}
-
-					retvals := value.Call(args)
-					if len(retvals) > 1 {
-						panic(newTypeError())
-					} else if len(retvals) == 1 {
-						return toValue(retvals[0].Interface())
-					}
-					return UndefinedValue()
-				}))
+				return toValue_object(self.newGoFunction(value))
 			case reflect.Struct:
 				return toValue_object(self.newGoStructObject(value))
 			case reflect.Map:
@@ -363,14 +517,36 @@ func (runtime *_runtime) newGoArray(value reflect.Value) *_object {
 	return self
 }
 
-func (self *_runtime) run(source string) Value {
-	return self.evaluate(mustParse(source))
+func (self *_runtime) run(filename, source string) Value {
+	if filename == "" && self.coverage != nil {
+		filename = anonymousFilename(source)
+	}
+	program, err := parseFile(filename, source, self.coverage != nil)
+	if err != nil {
+		panic(err)
+	}
+	return self.evaluate(program)
+}
+
+func (self *_runtime) runSafe(filename, source string) (Value, error) {
+	return self.evaluateSafe(func() Value {
+		return self.run(filename, source)
+	})
+}
+
+func (self *_runtime) runScriptSafe(script *Script) (Value, error) {
+	return self.evaluateSafe(func() Value {
+		if self.coverage != nil {
+			return self.evaluate(script.coverageProgram())
+		}
+		return self.evaluate(script.program)
+	})
 }
 
-func (self *_runtime) runSafe(source string) (Value, error) {
+func (self *_runtime) evaluateSafe(evaluate func() Value) (Value, error) {
 	result := UndefinedValue()
-	err := catchPanic(func() {
-		result = self.run(source)
+	err := self.catchPanic(func() {
+		result = evaluate()
 	})
 	switch result._valueType {
 	case valueReference:
@@ -0,0 +1,81 @@
+package otto
+
+import (
+	"sync"
+)
+
+// Script is a compiled (parsed) program that can be run many times, by
+// any number of runtimes, without the cost of parsing the source again.
+//
+// A Script is immutable, and is safe to share between goroutines (although
+// each runtime should still only be used by one goroutine at a time).
+type Script struct {
+	filename string
+	source   string
+	program  *_programNode
+
+	coverageOnce sync.Once
+	coverage     *_programNode // The program, with its statements and branches (for coverage)
+}
+
+// Compile will parse the given source, identified by filename, and return
+// a Script for running with RunScript:
+//
+//		script, err := otto.Compile("example.js", `abc = 1 + 1`)
+//		if err != nil {
+//			// err is a *Error (a SyntaxError)
+//			...
+//		}
+//		for _, Otto := range runtimeList {
+//			value, err := Otto.RunScript(script)
+//			...
+//		}
+//
+// The filename may be empty.
+func Compile(filename, source string) (*Script, error) {
+	var program *_programNode
+	err := catchPanic(func() {
+		var err interface{}
+		program, err = parseFile(filename, source, false)
+		if err != nil {
+			panic(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Script{
+		filename: filename,
+		source:   source,
+		program:  program,
+	}, nil
+}
+
+// coverageProgram returns the program of the script for a runtime with coverage,
+// which is converted again (once) to record its statements and branches (under a
+// synthetic filename, if it has none, see SetCoverage).
+func (self *Script) coverageProgram() *_programNode {
+	self.coverageOnce.Do(func() {
+		filename := self.filename
+		if filename == "" {
+			filename = anonymousFilename(self.source)
+		}
+		// The source was parsed (without error) before
+		self.coverage, _ = parseFile(filename, self.source, true)
+	})
+	return self.coverage
+}
+
+// Filename returns the filename the script was compiled with.
+func (self *Script) Filename() string {
+	return self.filename
+}
+
+// String returns the source of the script, prefixed with a comment
+// naming the file (if any).
+func (self *Script) String() string {
+	if self.filename == "" {
+		return self.source
+	}
+	return "// " + self.filename + "\n" + self.source
+}
@@ -0,0 +1,91 @@
+package otto
+
+import (
+	. "./terst"
+	"sync"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	Terst(t)
+
+	script, err := Compile("xyzzy.js", `
+        var abc;
+        if (!abc) {
+            abc = 0;
+        }
+        abc += 2;
+    `)
+	Is(err, nil)
+	Is(script.Filename(), "xyzzy.js")
+
+	Otto := New()
+	value, err := Otto.RunScript(script)
+	Is(err, nil)
+	Is(value, "2")
+
+	value, err = Otto.RunScript(script)
+	Is(err, nil)
+	Is(value, "4")
+
+	value, err = New().RunScript(script)
+	Is(err, nil)
+	Is(value, "2")
+
+	script, err = Compile("xyzzy.js", `abc = 1;
+        def.ghi;
+    `)
+	Is(err, nil)
+	_, err = Otto.RunScript(script)
+	Is(err, "ReferenceError: def is not defined (xyzzy.js: line 2)")
+
+	_, err = Compile("xyzzy.js", "abc = 1;\ndef = }")
+	Is(err, "SyntaxError: Unexpected token } (xyzzy.js: line 2)")
+	Is(err.(*Error).Column, 8)
+
+	_, err = Compile("xyzzy.js", "abc = 1;\n  def() = 2")
+	Is(err, "ReferenceError: Invalid left-hand side in assignment (xyzzy.js: line 2)")
+	Is(err.(*Error).Column, 3)
+}
+
+func TestScript_concurrent(t *testing.T) {
+	Terst(t)
+
+	script, err := Compile("", `
+        function fibonacci(n) {
+            return n < 2 ? n : fibonacci(n - 1) + fibonacci(n - 2);
+        }
+        fibonacci(15);
+    `)
+	Is(err, nil)
+
+	resultList := make([]string, 8)
+	errList := make([]error, 8)
+	group := sync.WaitGroup{}
+	for index := range resultList {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			value, err := New().RunScript(script)
+			resultList[index], errList[index] = value.String(), err
+		}(index)
+	}
+	group.Wait()
+
+	for index := range resultList {
+		Is(errList[index], nil)
+		Is(resultList[index], "610")
+	}
+}
+
+func BenchmarkScript(b *testing.B) {
+	Otto := New()
+	script, _ := Compile("", `
+        var abc = [ 1, 2, 3 ];
+        abc.length + abc[0];
+    `)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Otto.RunScript(script)
+	}
+}
@@ -0,0 +1,173 @@
+package otto
+
+import (
+	. "./terst"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	Terst(t)
+
+	test := runTest()
+
+	// Assignment to an undeclared variable
+	test(`raise:
+        "use strict";
+        abc = 1;
+    `, "ReferenceError: abc is not defined")
+
+	test(`
+        (function(){
+            "use strict";
+            try {
+                def = 1;
+            } catch (error) {
+                return [ error instanceof ReferenceError, typeof def ];
+            }
+        })();
+    `, "true,undefined")
+
+	// Only the directive prologue
+	test(`
+        var abc = 1;
+        "use strict";
+        ghi = 2;
+    `, "2")
+
+	// this is not coerced
+	test(`
+        function abc() {
+            "use strict";
+            return this;
+        }
+        function def() {
+            return this;
+        }
+        [ typeof abc(), typeof abc.call(1), abc.call(null) === null, def() === this, typeof def.call(1) ];
+    `, "undefined,number,true,true,object")
+
+	// Writing to a non-writable property
+	test(`raise:
+        (function(){
+            "use strict";
+            var abc = Object.freeze({ def: 1 });
+            abc.def = 2;
+        })();
+    `, "TypeError")
+
+	test(`
+        var abc = Object.freeze({ def: 1 });
+        abc.def = 2;
+        abc.def;
+    `, "1")
+
+	// arguments is not mapped
+	test(`
+        function abc(def) {
+            "use strict";
+            def = 2;
+            arguments[0] = 3;
+            return [ def, arguments[0] ];
+        }
+        function ghi(def) {
+            def = 2;
+            return arguments[0];
+        }
+        [ abc(1), ghi(1) ];
+    `, "2,3,2")
+
+	test(`raise:
+        (function(){
+            "use strict";
+            return arguments.callee;
+        })();
+    `, "TypeError: 'caller' and 'callee' may not be accessed in strict mode")
+
+	// A function within strict code is strict
+	test(`
+        "use strict";
+        var abc = (function(){
+            return this;
+        })();
+        typeof abc;
+    `, "undefined")
+
+	// eval
+	test(`
+        (function(){
+            "use strict";
+            eval("var abc = 1;");
+            return typeof abc;
+        })();
+    `, "undefined")
+
+	test(`
+        eval("'use strict'; var jkl = 1;");
+        typeof jkl;
+    `, "undefined")
+
+	test(`raise:
+        "use strict";
+        with ({}) {}
+    `, "SyntaxError: Strict mode code may not include a with statement")
+
+	test(`raise:
+        function abc(def, def) {
+            "use strict";
+        }
+    `, "SyntaxError: Strict mode function may not have duplicate parameter names")
+
+	test(`
+        function abc(def, def) {
+            return def;
+        }
+        abc(1, 2);
+    `, "2")
+
+	// Only the exact directive (without escapes)
+	test(`
+        "use\x20strict";
+        mno = 1;
+    `, "1")
+
+	test(`
+        (function(){
+            'use strict';
+            return this;
+        })();
+    `, "undefined")
+
+	// Writing to a property of a primitive
+	test(`raise:
+        "use strict";
+        var abc = "abc";
+        abc.def = 1;
+    `, "TypeError")
+
+	test(`raise:
+        "use strict";
+        (1)["def"] = 1;
+    `, "TypeError")
+
+	test(`
+        var abc = "abc";
+        abc.def = 1;
+        abc.length = 0;
+        [ abc.def, abc.length ];
+    `, ",3")
+
+	test(`
+        "use strict";
+        Object.defineProperty(Number.prototype, "xyzzy", {
+            set: function(value) {
+                this.constructor.prototype.nothing = [ typeof this, value ];
+            },
+            configurable: true
+        });
+        (1).xyzzy = 2;
+        var result = Number.prototype.nothing;
+        delete Number.prototype.xyzzy;
+        delete Number.prototype.nothing;
+        result;
+    `, "number,2")
+}
@@ -0,0 +1,71 @@
+package otto
+
+// Tracer is notified as a runtime evaluates, for tracing, profiling, coverage,
+// etc. Each method is called on the goroutine running the script, which waits
+// for it to return, so a Tracer should be quick.
+//
+//		type lineCounter map[int]int
+//
+//		func (self lineCounter) OnEnterFunction(frame otto.Frame) {}
+//		func (self lineCounter) OnExitFunction(frame otto.Frame)  {}
+//		func (self lineCounter) OnStatement(frame otto.Frame) {
+//			self[frame.Line] += 1
+//		}
+//
+//		counter := lineCounter{}
+//		Otto.SetTracer(counter)
+//		Otto.Run(`...`)
+//
+// A Tracer must not use the runtime (e.g. calling Run or Get) from within a method.
+type Tracer interface {
+	// OnEnterFunction is called when a function is entered, before the first
+	// statement of the function. The frame is of the function, at the position of
+	// its definition.
+	//
+	// The global code of a program (as given to Run, RunFile, etc.) is entered and
+	// exited as a function, with a frame that has no Function, Line, or Column
+	// (except for the code given to eval, which has the Function "eval").
+	//
+	// A native (Go) function is not traced.
+	OnEnterFunction(frame Frame)
+
+	// OnExitFunction is called when a function is exited, whether it returned
+	// or threw an exception. The frame is the same as for OnEnterFunction.
+	OnExitFunction(frame Frame)
+
+	// OnStatement is called before each statement is evaluated. The frame is of
+	// the function (or global code) being evaluated, at the position of the statement.
+	OnStatement(frame Frame)
+}
+
+// SetTracer sets the tracer of the runtime (or removes any tracer, if nil).
+func (self Otto) SetTracer(tracer Tracer) {
+	self.runtime.tracer = tracer
+}
+
+// traceFunction returns the frame of the function for the tracer, or false if
+// the function is native.
+func traceFunction(function *_object) (Frame, bool) {
+	node := functionNode(function)
+	if node == nil {
+		return Frame{}, false
+	}
+	position := node.position()
+	return Frame{
+		Function: node.Name,
+		Filename: position.Filename,
+		Line:     position.Line,
+		Column:   position.Column,
+	}, true
+}
+
+// traceProgram returns the frame of the (global) code of the program for the tracer.
+func (self *_runtime) traceProgram(node *_programNode) Frame {
+	frame := Frame{
+		Filename: node.position().Filename,
+	}
+	if self._executionContext(0).eval {
+		frame.Function = "eval"
+	}
+	return frame
+}
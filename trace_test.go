@@ -0,0 +1,126 @@
+package otto
+
+import (
+	. "./terst"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type _testTracer []string
+
+func (self *_testTracer) OnEnterFunction(frame Frame) {
+	*self = append(*self, fmt.Sprintf("enter %s %s:%d", frame.Function, frame.Filename, frame.Line))
+}
+
+func (self *_testTracer) OnExitFunction(frame Frame) {
+	*self = append(*self, fmt.Sprintf("exit %s", frame.Function))
+}
+
+func (self *_testTracer) OnStatement(frame Frame) {
+	*self = append(*self, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+}
+
+func TestTracer(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	tracer := &_testTracer{}
+	Otto.SetTracer(tracer)
+	_, err := Otto.RunFile("xyzzy.js", `
+        function abc(def) {
+            return def + 1;
+        }
+        var ghi = [ 1, 2 ].map(abc);
+        try {
+            (function(){
+                throw new Error();
+            })();
+        } catch (err) {
+        }
+        eval("ghi;");
+    `)
+	Is(err, nil)
+	Is(strings.Join(*tracer, "; "), strings.Join([]string{
+		"enter  xyzzy.js:0",
+		":5",
+		"enter abc xyzzy.js:2", "abc:3", "exit abc",
+		"enter abc xyzzy.js:2", "abc:3", "exit abc",
+		":6", ":7",
+		"enter  xyzzy.js:7", ":8", "exit ",
+		":12",
+		"enter eval :0", "eval:1", "exit eval",
+		"exit ",
+	}, "; "))
+
+	*tracer = (*tracer)[:0]
+	Otto.SetTracer(nil)
+	Otto.Run(`abc(1)`)
+	Is(len(*tracer), 0)
+}
+
+func TestProfiler(t *testing.T) {
+	Terst(t)
+
+	Otto := New()
+	profiler := NewProfiler()
+	now := time.Unix(0, 0)
+	profiler.now = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+	Otto.SetTracer(profiler)
+	_, err := Otto.RunFile("xyzzy.js", `
+        function abc(def) {
+            var ghi = def + 1;
+            return ghi;
+        }
+        abc(1);
+        abc(2);
+    `)
+	Is(err, nil)
+	now = now.Add(time.Hour) // Not counted
+	_, err = Otto.RunFile("xyzzy.js", `abc(3);`)
+	Is(err, nil)
+
+	sampleList := []string{}
+	for _, sample := range profiler.sample {
+		stack := []string{}
+		for _, location := range sample.stack {
+			stack = append(stack, fmt.Sprintf("%s:%d", location.name, location.line))
+		}
+		sampleList = append(sampleList, fmt.Sprintf("%s %d %v", strings.Join(stack, " < "), sample.calls, time.Duration(sample.time)))
+	}
+	sort.Strings(sampleList)
+	Is(strings.Join(sampleList, "; "), strings.Join([]string{
+		"(global):0 2 2ms",
+		"(global):1 0 2ms",
+		"(global):6 0 2ms",
+		"(global):7 0 2ms",
+		"abc:2 < (global):1 1 1ms",
+		"abc:2 < (global):6 1 1ms",
+		"abc:2 < (global):7 1 1ms",
+		"abc:3 < (global):1 0 1ms",
+		"abc:3 < (global):6 0 1ms",
+		"abc:3 < (global):7 0 1ms",
+		"abc:4 < (global):1 0 1ms",
+		"abc:4 < (global):6 0 1ms",
+		"abc:4 < (global):7 0 1ms",
+	}, "; "))
+	Is(profiler.elapsed, 17*time.Millisecond)
+
+	buffer := &bytes.Buffer{}
+	err = profiler.WriteProfile(buffer)
+	Is(err, nil)
+	reader, err := gzip.NewReader(buffer)
+	Is(err, nil)
+	profile, err := ioutil.ReadAll(reader)
+	Is(err, nil)
+	Is(bytes.Contains(profile, []byte("nanoseconds")), true)
+	Is(bytes.Contains(profile, []byte("xyzzy.js")), true)
+}
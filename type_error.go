@@ -1,9 +1,76 @@
 package otto
 
-func (runtime *_runtime) newErrorObject(message Value) *_object {
+import (
+	"fmt"
+)
+
+// _errorObject is the value of an Error object
+type _errorObject struct {
+	stack []Frame
+	err   error // The Go error, if the error was returned by a Go function
+}
+
+func (self *_object) errorValue() _errorObject {
+	value, _ := self.value.(_errorObject)
+	return value
+}
+
+func (runtime *_runtime) newErrorObject(prototype *_object, message Value) *_object {
 	self := runtime.newClassObject("Error")
+	self.prototype = prototype
 	if message.IsDefined() {
 		self.defineProperty("message", toValue_string(toString(message)), 0111, false)
 	}
+	runtime.setErrorStack(self, runtime.stackTrace())
 	return self
 }
+
+// setErrorStack records the given stack on the error object, both as the
+// (internal) value and as the "stack" property:
+//
+//		TypeError: Nothing happens.
+//		    at abc (<anonymous>:3)
+//		    at <anonymous>:6
+func (runtime *_runtime) setErrorStack(self *_object, stack []Frame) {
+	self.value = _errorObject{
+		stack: stack,
+		err:   self.errorValue().err,
+	}
+	trace := errorToString(self)
+	for _, frame := range stack {
+		trace += "\n    at " + frame.String()
+	}
+	self.defineProperty("stack", toValue_string(trace), 0101, false)
+}
+
+// setGoError records the Go error (returned by a Go function) on the error object.
+func (runtime *_runtime) setGoError(self *_object, err error) {
+	value := self.errorValue()
+	value.err = err
+	self.value = value
+}
+
+// errorToString is Error.prototype.toString
+func errorToString(self *_object) string {
+	name := "Error"
+	nameValue := self.get("name")
+	if nameValue.IsDefined() {
+		name = toString(nameValue)
+	}
+
+	message := ""
+	messageValue := self.get("message")
+	if messageValue.IsDefined() {
+		message = toString(messageValue)
+	}
+
+	if len(name) == 0 {
+		return message
+	}
+
+	if len(message) == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s: %s", name, message)
+}
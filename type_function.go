@@ -276,6 +276,16 @@ func (self FunctionCall) Argument(index int) Value {
 	return valueOfArrayIndex(self.ArgumentList, index)
 }
 
+// Throw throws the value (e.g. an Error object from Otto.MakeTypeError) as a
+// JavaScript exception, from a native function, and does not return.
+//
+// Any other panic from a native function (or a HostFunction, etc.) is thrown as an
+// Error with the message of the panic (and, for an error, the error as the Err of
+// the *Error, if it is not caught), except for a panic of an Interrupt function.
+func (self FunctionCall) Throw(value Value) {
+	panic(newException(value))
+}
+
 func (self FunctionCall) getArgument(index int) (Value, bool) {
 	return getValueOfArrayIndex(self.ArgumentList, index)
 }
@@ -0,0 +1,70 @@
+package otto
+
+import (
+	"reflect"
+	"strconv"
+)
+
+var goFunctionErrorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// newGoFunction is a native function that calls the Go function (of any type):
+//
+// Each argument is converted (see Otto.ExportTo) to the type of its parameter,
+// and a missing argument is the zero value. The arguments beyond the last
+// parameter go to the variadic parameter, if any, or are ignored.
+//
+// If the last result is an error, then a non-nil error is thrown (as an Error
+// with the message of the error, which is the Err of the *Error that is returned
+// to Go), and is otherwise dropped. The function returns undefined for no other
+// result, the value of the result for one, or an Array of the results for more.
+func (runtime *_runtime) newGoFunction(function reflect.Value) *_object {
+	functionType := function.Type()
+	return runtime.newNativeFunction(func(call FunctionCall) Value {
+		parameterCount := functionType.NumIn()
+		argumentCount := len(call.ArgumentList)
+		if functionType.IsVariadic() {
+			parameterCount -= 1
+			if argumentCount < parameterCount {
+				argumentCount = parameterCount
+			}
+		} else {
+			argumentCount = parameterCount
+		}
+
+		argumentList := make([]reflect.Value, argumentCount)
+		for index := range argumentList {
+			var argumentType reflect.Type
+			if index < parameterCount {
+				argumentType = functionType.In(index)
+			} else {
+				argumentType = functionType.In(parameterCount).Elem()
+			}
+			argument := reflect.New(argumentType).Elem()
+			err := runtime.exportTo(call.Argument(index), argument, "arguments["+strconv.Itoa(index)+"]")
+			if err != nil {
+				panic(newTypeError("%s", err.Error()))
+			}
+			argumentList[index] = argument
+		}
+
+		resultList := function.Call(argumentList)
+		if count := len(resultList); count > 0 && functionType.Out(count-1) == goFunctionErrorType {
+			if err, _ := resultList[count-1].Interface().(error); err != nil {
+				panic(newGoError(err))
+			}
+			resultList = resultList[:count-1]
+		}
+
+		switch len(resultList) {
+		case 0:
+			return UndefinedValue()
+		case 1:
+			return runtime.toValue(resultList[0].Interface())
+		}
+		valueList := make([]Value, len(resultList))
+		for index, result := range resultList {
+			valueList[index] = runtime.toValue(result.Interface())
+		}
+		return toValue_object(runtime.newArrayOf(valueList))
+	})
+}
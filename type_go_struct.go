@@ -2,6 +2,7 @@ package otto
 
 import (
 	"reflect"
+	"sort"
 )
 
 func (runtime *_runtime) newGoStructObject(value reflect.Value) *_object {
@@ -26,33 +27,189 @@ func _newGoStructObject(value reflect.Value) *_goStructObject {
 	return self
 }
 
-func (self _goStructObject) getValue(name string) reflect.Value {
-	if field := reflect.Indirect(self.value).FieldByName(name); (field != reflect.Value{}) {
-		return field
+// _goStructType is the (mapped) name of each field and method of a Go struct type
+type _goStructType struct {
+	nameList []string         // The fields, then the methods, in order
+	field    map[string][]int // The index (path) of each field
+	method   map[string]int   // The index of each method
+}
+
+// goStructType returns the _goStructType of the type (a struct, or a pointer to a
+// struct), as mapped by the FieldNameMapper of the runtime.
+func (runtime *_runtime) goStructType(valueType reflect.Type) *_goStructType {
+	if structType, exists := runtime.goStructTypes[valueType]; exists {
+		return structType
+	}
+	mapper := runtime.fieldNameMapper
+	if mapper == nil {
+		mapper = defaultFieldNameMapper
+	}
+	self := &_goStructType{
+		field:  map[string][]int{},
+		method: map[string]int{},
+	}
+	add := func(name string) bool {
+		if name == "" {
+			return false
+		}
+		if _, exists := self.field[name]; exists {
+			return false
+		}
+		if _, exists := self.method[name]; exists {
+			return false
+		}
+		self.nameList = append(self.nameList, name)
+		return true
 	}
 
-	if method := self.value.MethodByName(name); (method != reflect.Value{}) {
-		return method
+	fieldType := valueType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	for _, field := range goStructFieldList(fieldType, mapper) {
+		if name := mapper.FieldName(fieldType, field); add(name) {
+			self.field[name] = field.Index
+		}
+	}
+	for index := 0; index < valueType.NumMethod(); index++ {
+		if name := mapper.MethodName(valueType, valueType.Method(index)); add(name) {
+			self.method[name] = index
+		}
 	}
 
-	return reflect.Value{}
+	if runtime.goStructTypes == nil {
+		runtime.goStructTypes = map[reflect.Type]*_goStructType{}
+	}
+	runtime.goStructTypes[valueType] = self
+	return self
 }
 
-func (self _goStructObject) field(name string) (reflect.StructField, bool) {
-	return reflect.Indirect(self.value).Type().FieldByName(name)
+// goStructFieldList returns the exported fields of the struct type, including the
+// fields of each embedded struct (but not the embedded struct itself, unless it is
+// named by a tag), except for a field that is hidden by a field of the same name
+// that is less deeply embedded (or is as deeply embedded as another), as in Go.
+// The Index of each field is its index path, and the fields are in the order of
+// the struct, with the fields of an embedded struct in place of it.
+func goStructFieldList(structType reflect.Type, mapper FieldNameMapper) []reflect.StructField {
+	type embedded struct {
+		structType reflect.Type
+		index      []int
+	}
+
+	fieldList := []reflect.StructField{}
+	hidden := map[string]bool{}              // The names at a lesser depth
+	expanded := map[reflect.Type]bool{}      // The struct types embedded at a lesser depth
+	current := []embedded{{structType, nil}} // The struct types at the current depth
+	for len(current) > 0 {
+		next := []embedded{}
+		count := map[string]int{}
+		candidateList := []reflect.StructField{}
+		for _, each := range current {
+			if expanded[each.structType] {
+				continue
+			}
+			for index := 0; index < each.structType.NumField(); index++ {
+				field := each.structType.Field(index)
+				field.Index = append(append([]int{}, each.index...), index)
+				if field.Anonymous {
+					fieldType := field.Type
+					if fieldType.Kind() == reflect.Ptr {
+						fieldType = fieldType.Elem()
+					}
+					name, tagged := "", false
+					if mapper, valid := mapper.(_tagFieldNameMapper); valid {
+						name, tagged = mapper.tag(field)
+					}
+					if fieldType.Kind() == reflect.Struct && !tagged {
+						// An embedded struct (through a pointer only if it is exported, so
+						// that it can be made if it is nil)
+						if field.PkgPath == "" || field.Type.Kind() != reflect.Ptr {
+							next = append(next, embedded{fieldType, field.Index})
+						}
+						count[field.Name] += 1
+						continue
+					}
+					if tagged && name == "" {
+						continue
+					}
+				}
+				if field.PkgPath != "" {
+					continue
+				}
+				count[field.Name] += 1
+				candidateList = append(candidateList, field)
+			}
+		}
+		for _, field := range candidateList {
+			if !hidden[field.Name] && count[field.Name] == 1 {
+				fieldList = append(fieldList, field)
+			}
+		}
+		for name := range count {
+			hidden[name] = true
+		}
+		for _, each := range current {
+			expanded[each.structType] = true
+		}
+		current = next
+	}
+
+	sort.Slice(fieldList, func(i, j int) bool {
+		left, right := fieldList[i].Index, fieldList[j].Index
+		for index := 0; index < len(left) && index < len(right); index++ {
+			if left[index] != right[index] {
+				return left[index] < right[index]
+			}
+		}
+		return len(left) < len(right)
+	})
+	return fieldList
+}
+
+// goStructField returns the field of the struct (by index path), or false if it
+// is in an embedded struct (pointer) that is nil, unless allocate is true and the
+// embedded struct can be made (and set).
+func goStructField(value reflect.Value, index []int, allocate bool) (reflect.Value, bool) {
+	value = reflect.Indirect(value)
+	for position, fieldIndex := range index {
+		if position > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if !allocate || !value.CanSet() {
+					return reflect.Value{}, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, true
 }
 
-func (self _goStructObject) method(name string) (reflect.Method, bool) {
-	return reflect.Indirect(self.value).Type().MethodByName(name)
+func (self _goStructObject) getValue(structType *_goStructType, name string) reflect.Value {
+	if index, exists := structType.field[name]; exists {
+		// The field is invalid if it is in an embedded struct (pointer) that is nil
+		field, _ := goStructField(self.value, index, false)
+		return field
+	}
+
+	if index, exists := structType.method[name]; exists {
+		return self.value.Method(index)
+	}
+
+	return reflect.Value{}
 }
 
-func (self _goStructObject) setValue(name string, value Value) bool {
-	field, exists := self.field(name)
+func (self _goStructObject) setValue(structType *_goStructType, name string, value Value) bool {
+	index, exists := structType.field[name]
 	if !exists {
 		return false
 	}
-	fieldValue := self.getValue(name)
-	reflectValue, err := value.toReflectValue(field.Type.Kind())
+	fieldValue, valid := goStructField(self.value, index, true)
+	if !valid || !fieldValue.CanSet() {
+		return false
+	}
+	reflectValue, err := value.toReflectValue(fieldValue.Kind())
 	if err != nil {
 		panic(err)
 	}
@@ -62,7 +219,7 @@ func (self _goStructObject) setValue(name string, value Value) bool {
 
 func goStructGetOwnProperty(self *_object, name string) *_property {
 	object := self.value.(*_goStructObject)
-	value := object.getValue(name)
+	value := object.getValue(self.runtime.goStructType(object.value.Type()), name)
 	if value.IsValid() {
 		return &_property{self.runtime.toValue(value.Interface()), 0110}
 	}
@@ -72,17 +229,16 @@ func goStructGetOwnProperty(self *_object, name string) *_property {
 
 func goStructEnumerate(self *_object, all bool, each func(string) bool) {
 	object := self.value.(*_goStructObject)
+	structType := self.runtime.goStructType(object.value.Type())
 
-	// Enumerate fields
-	for index := 0; index < reflect.Indirect(object.value).NumField(); index++ {
-		if !each(reflect.Indirect(object.value).Type().Field(index).Name) {
-			return
+	// Enumerate fields, then methods
+	for _, name := range structType.nameList {
+		if index, exists := structType.field[name]; exists {
+			if _, valid := goStructField(object.value, index, false); !valid {
+				continue // In an embedded struct (pointer) that is nil
+			}
 		}
-	}
-
-	// Enumerate methods
-	for index := 0; index < object.value.NumMethod(); index++ {
-		if !each(object.value.Type().Method(index).Name) {
+		if !each(name) {
 			return
 		}
 	}
@@ -92,17 +248,29 @@ func goStructEnumerate(self *_object, all bool, each func(string) bool) {
 
 func goStructCanPut(self *_object, name string) bool {
 	object := self.value.(*_goStructObject)
-	value := object.getValue(name)
-	if value.IsValid() {
+	structType := self.runtime.goStructType(object.value.Type())
+	if _, exists := structType.field[name]; exists {
 		return true
 	}
+	if _, exists := structType.method[name]; exists {
+		return false
+	}
 
 	return objectCanPut(self, name)
 }
 
 func goStructPut(self *_object, name string, value Value, throw bool) {
 	object := self.value.(*_goStructObject)
-	if object.setValue(name, value) {
+	structType := self.runtime.goStructType(object.value.Type())
+	// A field or method is never shadowed (by an own property of the same name)
+	if _, exists := structType.field[name]; exists {
+		if !object.setValue(structType, name, value) {
+			typeErrorResult(throw)
+		}
+		return
+	}
+	if _, exists := structType.method[name]; exists {
+		typeErrorResult(throw)
 		return
 	}
 
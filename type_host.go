@@ -0,0 +1,253 @@
+package otto
+
+// HostObject is an object whose properties are provided by Go code, as they are
+// accessed, such as a record that is loaded from a database, or an object with
+// a very large (or unbounded) number of properties:
+//
+//		type record map[string]string // Loaded on demand from somewhere
+//
+//		func (self record) Get(name string) otto.Value {
+//			value, _ := otto.ToValue(self[name])
+//			return value
+//		}
+//		func (self record) Set(name string, value otto.Value) bool { return false } // Read-only
+//		func (self record) Has(name string) bool {
+//			_, exists := self[name]
+//			return exists
+//		}
+//		func (self record) Delete(name string) bool { return false }
+//		func (self record) Keys() []string {
+//			keys := []string{}
+//			for name := range self {
+//				keys = append(keys, name)
+//			}
+//			return keys
+//		}
+//
+//		Otto.Set("record", record{"name": "Xyzzy"})
+//		Otto.Run(`record.name`) // Xyzzy
+//
+// A HostObject given to Set, ToValue, etc. (or returned from a function) becomes
+// an object (not a copy) whose own properties are those of the HostObject, and
+// whose prototype is Object.prototype (or Function.prototype, see HostFunction).
+// Each property is writable, enumerable, and configurable, though Set and Delete
+// can refuse (which throws a TypeError in strict code).
+//
+// The methods are called on the goroutine running the script, and must not use
+// the runtime (e.g. calling Run or Get), except to make a Value. A panic in a
+// method is thrown as an Error, as from a native function (see FunctionCall.Throw).
+type HostObject interface {
+	// Get returns the value of the property, which is only called if Has is true.
+	Get(name string) Value
+
+	// Set sets the value of the property (making the property, if necessary), or
+	// returns false if it cannot.
+	Set(name string, value Value) bool
+
+	// Has returns whether the object has the property.
+	Has(name string) bool
+
+	// Delete deletes the property, or returns false if it cannot.
+	Delete(name string) bool
+
+	// Keys returns the name of each property, for enumeration (e.g. for-in and
+	// Object.keys).
+	Keys() []string
+}
+
+// HostFunction is a HostObject that can be called as a function (and which is a
+// function, to typeof, etc.).
+type HostFunction interface {
+	HostObject
+	Call(call FunctionCall) Value
+}
+
+// HostConstructor is a HostObject that can be called with new. The This of the
+// call is undefined, and the result should be the new object.
+//
+// A HostConstructor that is not also a HostFunction throws a TypeError when called
+// without new.
+type HostConstructor interface {
+	HostObject
+	Construct(call FunctionCall) Value
+}
+
+// _hostObject is the value of a (non-function) object of a HostObject
+type _hostObject struct {
+	host HostObject
+}
+
+func (runtime *_runtime) newHostObject(host HostObject) *_object {
+	self := runtime.newObject()
+	self.objectClass = _classHost
+	self.value = _hostObject{host}
+
+	_, function := host.(HostFunction)
+	constructor, construct := host.(HostConstructor)
+	if !function && !construct {
+		return self
+	}
+
+	self.class = "Function"
+	self.prototype = runtime.Global.FunctionPrototype
+	value := _functionObject{
+		call: _hostCallFunction{host},
+	}
+	if construct {
+		value.construct = func(self *_object, this Value, argumentList []Value) Value {
+			return self.runtime.callNative(func() Value {
+				return constructor.Construct(FunctionCall{
+					runtime:      self.runtime,
+					This:         UndefinedValue(),
+					ArgumentList: argumentList,
+					Otto:         self.runtime.Otto,
+				})
+			})
+		}
+	}
+	self.value = value
+	return self
+}
+
+// hostValue returns the HostObject of the object, or nil if it is not a host object.
+func (self *_object) hostValue() HostObject {
+	switch value := self.value.(type) {
+	case _hostObject:
+		return value.host
+	case _functionObject:
+		if call, valid := value.call.(_hostCallFunction); valid {
+			return call.host
+		}
+	}
+	return nil
+}
+
+// _hostCallFunction
+type _hostCallFunction struct {
+	host HostObject
+}
+
+func (self _hostCallFunction) Dispatch(function *_object, _ *_functionEnvironment, runtime *_runtime, this Value, argumentList []Value, _ bool) Value {
+	host, valid := self.host.(HostFunction)
+	if !valid {
+		panic(newTypeError("%v is a constructor, which must be called with new", toValue_object(function)))
+	}
+	return runtime.callNative(func() Value {
+		return host.Call(FunctionCall{
+			runtime:      runtime,
+			This:         this,
+			ArgumentList: argumentList,
+			Otto:         runtime.Otto,
+		})
+	})
+}
+
+func (self _hostCallFunction) ScopeEnvironment() _environment {
+	return nil
+}
+
+func (self _hostCallFunction) Source() string {
+	return ""
+}
+
+func (self0 _hostCallFunction) clone(clone *_clone) _callFunction {
+	return self0
+}
+
+// hostCall calls the function (which calls a method of the HostObject of the
+// object) as a native function, so that a panic becomes an Error (which can be
+// caught by the script), as with a HostFunction.
+func hostCall(self *_object, function func()) {
+	self.runtime.callNative(func() Value {
+		function()
+		return UndefinedValue()
+	})
+}
+
+func hostHas(self *_object, name string) (has bool) {
+	hostCall(self, func() {
+		has = self.hostValue().Has(name)
+	})
+	return
+}
+
+func hostGetOwnProperty(self *_object, name string) *_property {
+	if !hostHas(self, name) {
+		return nil
+	}
+	var value Value
+	hostCall(self, func() {
+		value = self.hostValue().Get(name)
+	})
+	return &_property{value, 0111}
+}
+
+func hostHasProperty(self *_object, name string) bool {
+	if hostHas(self, name) {
+		return true
+	}
+	return self.prototype != nil && self.prototype.hasProperty(name)
+}
+
+func hostHasOwnProperty(self *_object, name string) bool {
+	return hostHas(self, name)
+}
+
+// hostErrorResult is typeErrorResult, with a message naming the property.
+func hostErrorResult(throw bool, format string, name string) bool {
+	if throw {
+		panic(newTypeError(format, name))
+	}
+	return false
+}
+
+func hostSet(self *_object, name string, value Value) (set bool) {
+	hostCall(self, func() {
+		set = self.hostValue().Set(name, value)
+	})
+	return
+}
+
+func hostPut(self *_object, name string, value Value, throw bool) {
+	if !hostSet(self, name, value) {
+		hostErrorResult(throw, "Cannot assign to property '%s' of a host object", name)
+	}
+}
+
+func hostDefineOwnProperty(self *_object, name string, descriptor _property, throw bool) bool {
+	// Only a (writable, enumerable, configurable) value can be defined
+	if descriptor.mode != 0111 || !descriptor.isDataDescriptor() {
+		return hostErrorResult(throw, "Cannot define property '%s' of a host object (except as a writable, enumerable, and configurable value)", name)
+	}
+	value, _ := descriptor.value.(Value)
+	if !hostSet(self, name, value) {
+		return hostErrorResult(throw, "Cannot define property '%s' of a host object", name)
+	}
+	return true
+}
+
+func hostDelete(self *_object, name string, throw bool) bool {
+	if !hostHas(self, name) {
+		return true // As with any other object, deleting a missing property succeeds
+	}
+	deleted := false
+	hostCall(self, func() {
+		deleted = self.hostValue().Delete(name)
+	})
+	if !deleted {
+		return hostErrorResult(throw, "Cannot delete property '%s' of a host object", name)
+	}
+	return true
+}
+
+func hostEnumerate(self *_object, all bool, each func(string) bool) {
+	var keys []string
+	hostCall(self, func() {
+		keys = self.hostValue().Keys()
+	})
+	for _, name := range keys {
+		if !each(name) {
+			return
+		}
+	}
+}
@@ -30,8 +30,9 @@ func (self _referenceDefault) IsStrict() bool {
 
 type _propertyReference struct {
 	_referenceDefault
-	Base *_object
-	node _node
+	Base      *_object
+	primitive Value // The primitive base (which Base was made from), if any
+	node      _node
 }
 
 func newPropertyReference(base *_object, name string, strict bool, node _node) *_propertyReference {
@@ -45,6 +46,16 @@ func newPropertyReference(base *_object, name string, strict bool, node _node) *
 	}
 }
 
+// newPrimitivePropertyReference returns a property reference to the base, which
+// may be a primitive value (made into an object only for getting the property).
+func newPrimitivePropertyReference(runtime *_runtime, base Value, name string, strict bool, node _node) *_propertyReference {
+	self := newPropertyReference(runtime.toObject(base), name, strict, node)
+	if !base.IsObject() {
+		self.primitive = base
+	}
+	return self
+}
+
 func (self *_propertyReference) GetBase() interface{} {
 	return self.Base
 }
@@ -68,10 +79,26 @@ func (self *_propertyReference) PutValue(value Value) bool {
 	if self.Base == nil {
 		return false
 	}
+	if !self.primitive.isEmpty() {
+		self.putPrimitive(value)
+		return true
+	}
 	self.Base.put(self.name, value, self.IsStrict())
 	return true
 }
 
+// putPrimitive puts the value to the property of the primitive base (8.7.2), which
+// only calls a setter, since the object made from the primitive is thrown away, so
+// any other put throws a TypeError in strict code (and does nothing otherwise).
+func (self *_propertyReference) putPrimitive(value Value) {
+	canPut, _, setter := _objectCanPut(self.Base, self.name)
+	if canPut && setter != nil {
+		setter.callSet(self.primitive, value)
+		return
+	}
+	typeErrorResult(self.IsStrict())
+}
+
 func (self *_propertyReference) Delete() bool {
 	if self.Base == nil {
 		// TODO Throw an error if strict
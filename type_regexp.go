@@ -2,6 +2,7 @@ package otto
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -9,7 +10,7 @@ import (
 )
 
 type _regExpObject struct {
-	regularExpression *regexp.Regexp
+	regularExpression _regExpMatcher
 	global            bool
 	ignoreCase        bool
 	multiline         bool
@@ -24,7 +25,6 @@ func (runtime *_runtime) newRegExpObject(pattern string, flags string) *_object
 	global := false
 	ignoreCase := false
 	multiline := false
-	re2flags := ""
 
 	for _, chr := range flags {
 		switch chr {
@@ -38,24 +38,17 @@ func (runtime *_runtime) newRegExpObject(pattern string, flags string) *_object
 				panic(newError("SyntaxError: newRegExpObject: %s %s", pattern, flags))
 			}
 			multiline = true
-			re2flags += "m"
 		case 'i':
 			if ignoreCase {
 				panic(newError("SyntaxError: newRegExpObject: %s %s", pattern, flags))
 			}
 			ignoreCase = true
-			re2flags += "i"
 		}
 	}
 
-	re2pattern := transformRegExp(pattern)
-	if len(re2flags) > 0 {
-		re2pattern = fmt.Sprintf("(?%s:%s)", re2flags, re2pattern)
-	}
-
-	regularExpression, err := regexp.Compile(re2pattern)
+	regularExpression, err := compileRegExp(pattern, ignoreCase, multiline)
 	if err != nil {
-		panic(newSyntaxError("Invalid regular expression: %s", err.Error()[22:]))
+		panic(newSyntaxError("Invalid regular expression: %s", err.Error()))
 	}
 
 	self.value = _regExpObject{
@@ -74,6 +67,47 @@ func (runtime *_runtime) newRegExpObject(pattern string, flags string) *_object
 	return self
 }
 
+// _regExpMatcher is the engine of a RegExp, either RE2 (*regexp.Regexp) or, for a
+// pattern that RE2 cannot do, a backtracking _regExpProgram
+type _regExpMatcher interface {
+	FindStringIndex(string) []int
+	FindStringSubmatchIndex(string) []int
+	FindAllStringIndex(string, int) [][]int
+	FindAllStringSubmatchIndex(string, int) [][]int
+}
+
+// compileRegExp compiles the (ECMAScript) pattern with RE2 if it can, and with
+// the backtracking _regExpProgram otherwise (e.g. for a lookahead or backreference).
+func compileRegExp(pattern string, ignoreCase bool, multiline bool) (_regExpMatcher, error) {
+	var re2err error
+	if isValidRegExp(pattern) {
+		re2pattern := transformRegExp(pattern)
+		re2flags := ""
+		if multiline {
+			re2flags += "m"
+		}
+		if ignoreCase {
+			re2flags += "i"
+		}
+		if len(re2flags) > 0 {
+			re2pattern = fmt.Sprintf("(?%s:%s)", re2flags, re2pattern)
+		}
+		regularExpression, err := regexp.Compile(re2pattern)
+		if err == nil {
+			return regularExpression, nil
+		}
+		re2err = errors.New(err.Error()[22:]) // Skip redundant "error parsing regexp: "
+	}
+	program, err := compileRegExpProgram(pattern, ignoreCase, multiline)
+	if err != nil {
+		if re2err != nil {
+			return nil, re2err
+		}
+		return nil, err
+	}
+	return program, nil
+}
+
 func (self *_object) regExpValue() _regExpObject {
 	value, _ := self.value.(_regExpObject)
 	return value
@@ -89,9 +123,10 @@ func execRegExp(this *_object, target string) (match bool, result []int) {
 	if !global {
 		index = 0
 	}
-	if 0 > index || index > int64(len(target)) {
-	} else {
-		result = this.regExpValue().regularExpression.FindStringSubmatchIndex(target[index:])
+	// lastIndex is in UTF-16 code units (like the length of a string), not bytes
+	startIndex, valid := this.runtime.utf16ByteIndex(target, index)
+	if valid {
+		result = this.regExpValue().regularExpression.FindStringSubmatchIndex(target[startIndex:])
 	}
 	if result == nil {
 		//this.defineProperty("lastIndex", toValue_(0), 0111, true)
@@ -99,16 +134,16 @@ func execRegExp(this *_object, target string) (match bool, result []int) {
 		return // !match
 	}
 	match = true
-	startIndex := index
-	endIndex := int(lastIndex) + result[1]
 	// We do this shift here because the .FindStringSubmatchIndex above
 	// was done on a local subordinate slice of the string, not the whole string
 	for index, _ := range result {
-		result[index] += int(startIndex)
+		if result[index] != -1 {
+			result[index] += startIndex
+		}
 	}
 	if global {
 		//this.defineProperty("lastIndex", toValue_(endIndex), 0111, true)
-		this.put("lastIndex", toValue_int(endIndex), true)
+		this.put("lastIndex", toValue_int(this.runtime.utf16Index(target, result[1])), true)
 	}
 	return // match
 }
@@ -124,22 +159,81 @@ func execResultToArray(runtime *_runtime, target string, result []int) *_object
 			valueArray[index] = UndefinedValue()
 		}
 	}
-	matchIndex := result[0]
-	if matchIndex != 0 {
-		matchIndex = 0
-		// Find the rune index in the string, not the byte index
-		for index := 0; index < result[0]; {
-			_, size := utf8.DecodeRuneInString(target[index:])
-			matchIndex += 1
-			index += size
-		}
-	}
 	match := runtime.newArrayOf(valueArray)
 	match.defineProperty("input", toValue_string(target), 0111, false)
-	match.defineProperty("index", toValue_int(matchIndex), 0111, false)
+	match.defineProperty("index", toValue_int(runtime.utf16Index(target, result[0])), 0111, false)
 	return match
 }
 
+// _utf16Position is a position in a string, as both a byte offset and an index in
+// UTF-16 code units (as with the length of a string). The last position found is
+// kept by the runtime, so that the positions of successive matches (e.g. of a
+// global RegExp) are found from there, rather than from the start of the string.
+type _utf16Position struct {
+	target string
+	offset int
+	index  int64
+}
+
+// utf16Start returns the last position found in the string, or the start of the
+// string.
+func (runtime *_runtime) utf16Start(target string) _utf16Position {
+	if runtime.utf16Position.target != target {
+		return _utf16Position{target: target}
+	}
+	return runtime.utf16Position
+}
+
+// utf16Index returns the index, in UTF-16 code units (as with the length of a
+// string), of the byte offset into the string.
+func (runtime *_runtime) utf16Index(target string, offset int) int {
+	position := runtime.utf16Start(target)
+	count := func(text string) (count int64) {
+		for _, chr := range text {
+			count += 1
+			if chr > 0xFFFF {
+				count += 1 // A surrogate pair
+			}
+		}
+		return
+	}
+	if offset >= position.offset {
+		position.index += count(target[position.offset:offset])
+	} else {
+		position.index -= count(target[offset:position.offset])
+	}
+	position.offset = offset
+	runtime.utf16Position = position
+	return int(position.index)
+}
+
+// utf16ByteIndex returns the byte offset of the index (in UTF-16 code units) into
+// the string, or false if the index is out of range. An index within a surrogate
+// pair is the character after.
+func (runtime *_runtime) utf16ByteIndex(target string, index int64) (int, bool) {
+	if index < 0 {
+		return 0, false
+	}
+	position := runtime.utf16Start(target)
+	if position.index > index {
+		position = _utf16Position{target: target}
+	}
+	for width := 0; position.offset < len(target); position.offset += width {
+		if position.index >= index {
+			runtime.utf16Position = position
+			return position.offset, true
+		}
+		var chr rune
+		chr, width = utf8.DecodeRuneInString(target[position.offset:])
+		position.index += 1
+		if chr > 0xFFFF {
+			position.index += 1
+		}
+	}
+	runtime.utf16Position = position
+	return len(target), position.index >= index
+}
+
 // 0031,0032,0033,0034,0035,0036,0037,0038,0039 // 1 - 9
 // 0043,0045,0046,0047,0048,0049,004A,004B,004C,004D,004E,004F
 // 0050,0052,0054,0055,0056,0058,0059,005A
@@ -199,18 +293,76 @@ func transformRegExp(ecmaRegExp string) (goRegExp string) {
 	tmp = transformRegExp_unescape_c.ReplaceAll(tmp, []byte(`c`))
 	tmp = transformRegExp_unescapeDollar.ReplaceAll(tmp, []byte(`$1`))
 	tmp = transformRegExp_matchSlashU.ReplaceAll(tmp, []byte(`\x{$1}`))
-	return string(tmp)
+	return transformRegExp_whiteSpace(string(tmp))
 }
 
+// The white space of JavaScript (\s), which is more than that of RE2 (e.g. \v, \u00A0)
+const transformRegExp_whiteSpaceClass = `\s\v\x{A0}\x{1680}\x{180E}\x{2000}-\x{200A}\x{2028}\x{2029}\x{202F}\x{205F}\x{3000}\x{FEFF}`
+
+// transformRegExp_whiteSpace replaces \s and \S with the white space of JavaScript.
+// (\S within a class cannot be done by RE2, see isValidRegExp.)
+func transformRegExp_whiteSpace(goRegExp string) string {
+	if !strings.Contains(goRegExp, `\s`) && !strings.Contains(goRegExp, `\S`) {
+		return goRegExp
+	}
+	output := []byte{}
+	inSet := false
+	for index := 0; index < len(goRegExp); index++ {
+		chr := goRegExp[index]
+		if chr == '\\' && index+1 < len(goRegExp) {
+			index += 1
+			switch next := goRegExp[index]; {
+			case next == 's' && inSet:
+				output = append(output, transformRegExp_whiteSpaceClass...)
+			case next == 's':
+				output = append(output, "["+transformRegExp_whiteSpaceClass+"]"...)
+			case next == 'S' && !inSet:
+				output = append(output, "[^"+transformRegExp_whiteSpaceClass+"]"...)
+			default:
+				output = append(output, chr, next)
+			}
+			continue
+		}
+		switch chr {
+		case '[':
+			inSet = true
+		case ']':
+			inSet = false
+		}
+		output = append(output, chr)
+	}
+	return string(output)
+}
+
+// isValidRegExp returns whether RE2 can do the pattern, which excludes a lookahead
+// ((?= or (?!), a backreference (\1, etc.), and \S within a class.
+//
+// It also excludes a quantified group that contains a capturing group, e.g.
+// /((a)|b)+/, since RE2 keeps a capture from an earlier repetition, while in
+// ECMAScript each repetition resets the captures within it (so that the result
+// for "ab" is [ "ab", "b", undefined ], not [ "ab", "b", "a" ]).
 func isValidRegExp(ecmaRegExp string) bool {
 	shibboleth := 0 // The shibboleth in this case is (?
 	// Since we're looking for (?! / (?=
 	inSet := false // In a bracketed set, e.g. [0-9]
 	escape := false
+	// Whether each open group is capturing, and whether it contains a capturing group
+	type group struct{ capturing, capture bool }
+	groupList := []group{}
+	nestedCapture := false // The group just closed contains a capturing group
 	for _, chr := range ecmaRegExp {
+		if nestedCapture {
+			nestedCapture = false
+			if chr == '*' || chr == '+' || chr == '?' || chr == '{' {
+				return false
+			}
+		}
 		if escape {
 			escape = false
 			shibboleth = 0
+			if !inSet && '1' <= chr && chr <= '9' || inSet && chr == 'S' {
+				return false
+			}
 			continue
 		}
 		if chr == '\\' {
@@ -230,10 +382,21 @@ func isValidRegExp(ecmaRegExp string) bool {
 			continue
 		case '(':
 			shibboleth = 1
+			groupList = append(groupList, group{capturing: true})
 			continue
+		case ')':
+			if count := len(groupList); count > 0 {
+				closed := groupList[count-1]
+				groupList = groupList[:count-1]
+				nestedCapture = closed.capture
+				if count > 1 && (closed.capturing || closed.capture) {
+					groupList[count-2].capture = true
+				}
+			}
 		case '?':
 			if shibboleth == 1 {
 				shibboleth = 2
+				groupList[len(groupList)-1].capturing = false
 			}
 			continue
 		case '=', '!':
@@ -0,0 +1,851 @@
+package otto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// _regExpProgram is a backtracking implementation of ECMAScript (ES5) regular
+// expressions, for a pattern that RE2 (the regexp package) cannot do: lookahead,
+// (?=...) and (?!...), and backreferences, \1 through \N.
+//
+// It has the methods of *regexp.Regexp that are needed for a _regExpMatcher, with the
+// results as byte offsets (into the UTF-8 string), the same as RE2.
+//
+// Backtracking can take exponential time (e.g. /(a+)+b/ on "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+// so each search is limited to regExpStepLimit steps, after which a RangeError is thrown.
+type _regExpProgram struct {
+	match      _regExpTerm
+	groupCount int // The number of capturing groups (not including the whole match)
+	ignoreCase bool
+	multiline  bool
+}
+
+// regExpStepLimit is the maximum number of steps (roughly, the characters and
+// positions tried) of a search by a _regExpProgram.
+const regExpStepLimit = 10000000
+
+// _regExpTerm matches (part of) a pattern at the index of the input, then calls
+// next with the index after the match, backtracking (and trying another way to
+// match, if any) if next returns false.
+type _regExpTerm func(state *_regExpState, index int, next func(int) bool) bool
+
+// _regExpState is the state of a search by a _regExpProgram
+type _regExpState struct {
+	program *_regExpProgram
+	source  string
+	input   []rune // The characters of the source, as far as they have been decoded
+	offset  []int  // The byte offset of each (decoded) character, and of the next
+	capture []int  // The start and end (as a rune index) of the match and of each group, or -1
+	steps   int
+}
+
+// end returns whether the (rune) index is at (or beyond) the end of the input,
+// and otherwise decodes the input up to the character at the index.
+func (self *_regExpState) end(index int) bool {
+	for len(self.input) <= index {
+		offset := self.offset[len(self.offset)-1]
+		if offset >= len(self.source) {
+			return true
+		}
+		chr, width := utf8.DecodeRuneInString(self.source[offset:])
+		self.input = append(self.input, chr)
+		self.offset = append(self.offset, offset+width)
+	}
+	return false
+}
+
+// within returns whether the (rune) index is within the input, or at the end.
+func (self *_regExpState) within(index int) bool {
+	return index == 0 || !self.end(index-1)
+}
+
+func (self *_regExpState) step() {
+	self.steps += 1
+	if self.steps > regExpStepLimit {
+		panic(newRangeError("Regular expression is too complex (backtracking limit exceeded)"))
+	}
+}
+
+// _regExpSyntaxError is panicked (and recovered) while compiling a _regExpProgram
+type _regExpSyntaxError string
+
+// compileRegExpProgram compiles the (ECMAScript) pattern into a _regExpProgram.
+func compileRegExpProgram(pattern string, ignoreCase bool, multiline bool) (program *_regExpProgram, err error) {
+	defer func() {
+		if caught := recover(); caught != nil {
+			if message, ok := caught.(_regExpSyntaxError); ok {
+				program, err = nil, errors.New(string(message))
+				return
+			}
+			panic(caught)
+		}
+	}()
+
+	parser := &_regExpParser{
+		pattern:    []rune(pattern),
+		ignoreCase: ignoreCase,
+		multiline:  multiline,
+	}
+	parser.totalGroupCount = parser.countGroups()
+	match := parser.parseDisjunction()
+	if parser.index < len(parser.pattern) {
+		parser.error("unexpected ): `%s`", pattern)
+	}
+	return &_regExpProgram{
+		match:      match,
+		groupCount: parser.groupCount,
+		ignoreCase: ignoreCase,
+		multiline:  multiline,
+	}, nil
+}
+
+// find returns the match (and the groups) that begins at or after the (rune)
+// index, or nil if there is none.
+func (self *_regExpProgram) find(state *_regExpState, start int) []int {
+	state.steps = 0
+	for index := start; state.within(index); index++ {
+		for count := range state.capture {
+			state.capture[count] = -1
+		}
+		end := -1
+		if self.match(state, index, func(index int) bool {
+			end = index
+			return true
+		}) {
+			state.capture[0], state.capture[1] = index, end
+			result := make([]int, len(state.capture))
+			copy(result, state.capture)
+			return result
+		}
+	}
+	return nil
+}
+
+// newState returns the state of a search of the input, which is decoded as the
+// search goes (rather than all at once, since a match may be near the start).
+func (self *_regExpProgram) newState(input string) *_regExpState {
+	return &_regExpState{
+		program: self,
+		source:  input,
+		offset:  []int{0},
+		capture: make([]int, 2*(self.groupCount+1)),
+	}
+}
+
+// byteOffsets converts the (rune) indices of a match to byte offsets.
+func (self *_regExpState) byteOffsets(match []int) []int {
+	for index, position := range match {
+		if position != -1 {
+			match[index] = self.offset[position]
+		}
+	}
+	return match
+}
+
+func (self *_regExpProgram) FindStringSubmatchIndex(input string) []int {
+	state := self.newState(input)
+	if match := self.find(state, 0); match != nil {
+		return state.byteOffsets(match)
+	}
+	return nil
+}
+
+func (self *_regExpProgram) FindStringIndex(input string) []int {
+	if match := self.FindStringSubmatchIndex(input); match != nil {
+		return match[:2]
+	}
+	return nil
+}
+
+// FindAllStringSubmatchIndex returns successive (non-overlapping) matches, at most
+// count of them (or every one, if count is negative). As with RE2, an empty match
+// immediately after the previous match is skipped.
+func (self *_regExpProgram) FindAllStringSubmatchIndex(input string, count int) [][]int {
+	state := self.newState(input)
+	var result [][]int
+	index, previous := 0, -1
+	for (count < 0 || len(result) < count) && state.within(index) {
+		match := self.find(state, index)
+		if match == nil {
+			break
+		}
+		start, end := match[0], match[1]
+		if start == end {
+			index = end + 1
+			if end == previous {
+				continue
+			}
+		} else {
+			index = end
+		}
+		previous = end
+		result = append(result, state.byteOffsets(match))
+	}
+	return result
+}
+
+func (self *_regExpProgram) FindAllStringIndex(input string, count int) [][]int {
+	result := self.FindAllStringSubmatchIndex(input, count)
+	for index, match := range result {
+		result[index] = match[:2]
+	}
+	return result
+}
+
+// equal compares two characters, ignoring case (if the i flag is set).
+func (self *_regExpProgram) equal(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	return self.ignoreCase && regExpCanonicalize(a) == regExpCanonicalize(b)
+}
+
+// regExpCanonicalize is the Canonicalize of ES5 (15.10.2.8), the (upper) case of
+// a character for matching with the i flag.
+func regExpCanonicalize(chr rune) rune {
+	upper := unicode.ToUpper(chr)
+	if chr >= 128 && upper < 128 {
+		return chr // Not a non-ASCII character to an ASCII character
+	}
+	return upper
+}
+
+func isRegExpLineTerminator(chr rune) bool {
+	switch chr {
+	case '\n', '\r', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+func isRegExpWhiteSpace(chr rune) bool {
+	switch chr {
+	case '\t', '\n', '\v', '\f', '\r', ' ', '\u00a0', '\u1680', '\u180e', '\u2028', '\u2029', '\u202f', '\u205f', '\u3000', '\ufeff':
+		return true
+	}
+	return '\u2000' <= chr && chr <= '\u200a'
+}
+
+func isRegExpWord(chr rune) bool {
+	return 'a' <= chr && chr <= 'z' || 'A' <= chr && chr <= 'Z' || '0' <= chr && chr <= '9' || chr == '_'
+}
+
+func isRegExpDigit(chr rune) bool {
+	return '0' <= chr && chr <= '9'
+}
+
+// _regExpParser parses a pattern (15.10.1) into a _regExpTerm
+type _regExpParser struct {
+	pattern         []rune
+	index           int
+	groupCount      int // The number of capturing groups so far
+	totalGroupCount int // The number of capturing groups in the pattern
+	ignoreCase      bool
+	multiline       bool
+}
+
+func (self *_regExpParser) error(format string, argumentList ...interface{}) {
+	panic(_regExpSyntaxError(fmt.Sprintf(format, argumentList...)))
+}
+
+func (self *_regExpParser) peek(offset int) rune {
+	if index := self.index + offset; index < len(self.pattern) {
+		return self.pattern[index]
+	}
+	return -1
+}
+
+func (self *_regExpParser) next() rune {
+	chr := self.peek(0)
+	self.index += 1
+	return chr
+}
+
+// countGroups counts the capturing groups in the pattern, so a backreference can
+// refer to a later group.
+func (self *_regExpParser) countGroups() int {
+	count := 0
+	inClass := false
+	for index := 0; index < len(self.pattern); index++ {
+		switch chr := self.pattern[index]; {
+		case chr == '\\':
+			index += 1
+		case inClass:
+			inClass = chr != ']'
+		case chr == '[':
+			inClass = true
+		case chr == '(':
+			if index+1 >= len(self.pattern) || self.pattern[index+1] != '?' {
+				count += 1
+			}
+		}
+	}
+	return count
+}
+
+func (self *_regExpParser) parseDisjunction() _regExpTerm {
+	alternativeList := []_regExpTerm{self.parseAlternative()}
+	for self.peek(0) == '|' {
+		self.next()
+		alternativeList = append(alternativeList, self.parseAlternative())
+	}
+	if len(alternativeList) == 1 {
+		return alternativeList[0]
+	}
+	return func(state *_regExpState, index int, next func(int) bool) bool {
+		for _, alternative := range alternativeList {
+			if alternative(state, index, next) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (self *_regExpParser) parseAlternative() _regExpTerm {
+	termList := []_regExpTerm{}
+	for self.index < len(self.pattern) && self.peek(0) != '|' && self.peek(0) != ')' {
+		termList = append(termList, self.parseTerm())
+	}
+	return regExpSequence(termList)
+}
+
+func regExpSequence(termList []_regExpTerm) _regExpTerm {
+	switch len(termList) {
+	case 0:
+		return func(state *_regExpState, index int, next func(int) bool) bool {
+			return next(index)
+		}
+	case 1:
+		return termList[0]
+	}
+	first, rest := termList[0], regExpSequence(termList[1:])
+	return func(state *_regExpState, index int, next func(int) bool) bool {
+		return first(state, index, func(index int) bool {
+			return rest(state, index, next)
+		})
+	}
+}
+
+func (self *_regExpParser) parseTerm() _regExpTerm {
+	switch self.peek(0) {
+	case '^':
+		self.next()
+		return func(state *_regExpState, index int, next func(int) bool) bool {
+			state.step()
+			if index == 0 || state.program.multiline && isRegExpLineTerminator(state.input[index-1]) {
+				return next(index)
+			}
+			return false
+		}
+	case '$':
+		self.next()
+		return func(state *_regExpState, index int, next func(int) bool) bool {
+			state.step()
+			if state.end(index) || state.program.multiline && isRegExpLineTerminator(state.input[index]) {
+				return next(index)
+			}
+			return false
+		}
+	case '\\':
+		if chr := self.peek(1); chr == 'b' || chr == 'B' {
+			self.index += 2
+			boundary := chr == 'b'
+			return func(state *_regExpState, index int, next func(int) bool) bool {
+				state.step()
+				before := index > 0 && isRegExpWord(state.input[index-1])
+				after := !state.end(index) && isRegExpWord(state.input[index])
+				if (before != after) == boundary {
+					return next(index)
+				}
+				return false
+			}
+		}
+	}
+
+	groupStart := self.groupCount
+	atom, match := self.parseAtom()
+	groupEnd := self.groupCount
+
+	min, max, greedy, quantified := self.parseQuantifier()
+	if !quantified {
+		return atom
+	}
+	if match != nil {
+		return regExpRepeatCharacter(match, min, max, greedy)
+	}
+	return regExpRepeat(atom, min, max, greedy, groupStart, groupEnd)
+}
+
+// parseQuantifier parses a quantifier (*, +, ?, {n}, {n,}, or {n,m}, any of which
+// may be followed by ? to be lazy), if any. A max of -1 is no maximum.
+func (self *_regExpParser) parseQuantifier() (min, max int, greedy bool, quantified bool) {
+	switch self.peek(0) {
+	case '*':
+		self.next()
+		min, max = 0, -1
+	case '+':
+		self.next()
+		min, max = 1, -1
+	case '?':
+		self.next()
+		min, max = 0, 1
+	case '{':
+		var valid bool
+		min, max, valid = self.parseBraceQuantifier()
+		if !valid {
+			return 0, 0, false, false // A literal {
+		}
+	default:
+		return 0, 0, false, false
+	}
+	greedy = true
+	if self.peek(0) == '?' {
+		self.next()
+		greedy = false
+	}
+	return min, max, greedy, true
+}
+
+// parseBraceQuantifier parses {n}, {n,}, or {n,m}, or returns false (without
+// consuming anything) if the { does not begin a quantifier.
+func (self *_regExpParser) parseBraceQuantifier() (min, max int, valid bool) {
+	start := self.index
+	self.next() // {
+	min, ok := self.parseInteger()
+	if !ok {
+		self.index = start
+		return 0, 0, false
+	}
+	max = min
+	if self.peek(0) == ',' {
+		self.next()
+		max = -1
+		if isRegExpDigit(self.peek(0)) {
+			max, _ = self.parseInteger()
+		}
+	}
+	if self.peek(0) != '}' {
+		self.index = start
+		return 0, 0, false
+	}
+	self.next()
+	if max != -1 && max < min {
+		self.error("numbers out of order in {} quantifier: `%s`", string(self.pattern[start:self.index]))
+	}
+	return min, max, true
+}
+
+func (self *_regExpParser) parseInteger() (int, bool) {
+	start := self.index
+	for isRegExpDigit(self.peek(0)) {
+		self.next()
+	}
+	if start == self.index {
+		return 0, false
+	}
+	value, err := strconv.Atoi(string(self.pattern[start:self.index]))
+	if err != nil {
+		self.error("invalid repeat count: `%s`", string(self.pattern[start:self.index]))
+	}
+	return value, true
+}
+
+// parseAtom parses an atom, returning (for an atom of one character, such as a
+// literal, ., or a class) the match of the character, too.
+func (self *_regExpParser) parseAtom() (_regExpTerm, func(rune) bool) {
+	chr := self.peek(0)
+	switch chr {
+	case '.':
+		self.next()
+		return self.character(func(chr rune) bool {
+			return !isRegExpLineTerminator(chr)
+		})
+	case '(':
+		return self.parseGroup(), nil
+	case '[':
+		return self.character(self.parseClass())
+	case '\\':
+		self.next()
+		return self.parseAtomEscape()
+	case '*', '+', '?':
+		self.error("missing argument to repetition operator: `%s`", string(chr))
+	case '{':
+		if _, _, valid := self.parseBraceQuantifier(); valid {
+			self.error("missing argument to repetition operator: `%s`", string(self.pattern[:self.index]))
+		}
+	case ')':
+		self.error("unexpected ): `%s`", string(self.pattern))
+	}
+	self.next()
+	return self.literal(chr)
+}
+
+func (self *_regExpParser) character(match func(rune) bool) (_regExpTerm, func(rune) bool) {
+	return func(state *_regExpState, index int, next func(int) bool) bool {
+		state.step()
+		if !state.end(index) && match(state.input[index]) {
+			return next(index + 1)
+		}
+		return false
+	}, match
+}
+
+func (self *_regExpParser) literal(chr rune) (_regExpTerm, func(rune) bool) {
+	if self.ignoreCase {
+		canonical := regExpCanonicalize(chr)
+		return self.character(func(other rune) bool {
+			return other == chr || regExpCanonicalize(other) == canonical
+		})
+	}
+	return self.character(func(other rune) bool {
+		return other == chr
+	})
+}
+
+func (self *_regExpParser) parseGroup() _regExpTerm {
+	start := self.index
+	self.next() // (
+	kind := ""
+	if self.peek(0) == '?' {
+		switch self.peek(1) {
+		case ':', '=', '!':
+			kind = string(self.peek(1))
+			self.index += 2
+		default:
+			self.error("invalid or unsupported Perl syntax: `%s`", string(self.pattern[start:self.index+1]))
+		}
+	}
+
+	group := 0
+	if kind == "" {
+		self.groupCount += 1
+		group = self.groupCount
+	}
+	groupStart := self.groupCount
+	inner := self.parseDisjunction()
+	groupEnd := self.groupCount
+	if self.peek(0) != ')' {
+		self.error("missing closing ): `%s`", string(self.pattern[start:]))
+	}
+	self.next()
+
+	switch kind {
+	case ":":
+		return inner
+	case "=":
+		return func(state *_regExpState, index int, next func(int) bool) bool {
+			state.step()
+			saved := saveRegExpCapture(state, groupStart, groupEnd)
+			if !inner(state, index, func(int) bool { return true }) {
+				return false
+			}
+			// The groups of a lookahead are kept, but there is no backtracking into it
+			if next(index) {
+				return true
+			}
+			restoreRegExpCapture(state, groupStart, saved)
+			return false
+		}
+	case "!":
+		return func(state *_regExpState, index int, next func(int) bool) bool {
+			state.step()
+			saved := saveRegExpCapture(state, groupStart, groupEnd)
+			matched := inner(state, index, func(int) bool { return true })
+			restoreRegExpCapture(state, groupStart, saved) // The groups of a negative lookahead are always undefined
+			if matched {
+				return false
+			}
+			return next(index)
+		}
+	}
+
+	return func(state *_regExpState, start int, next func(int) bool) bool {
+		return inner(state, start, func(end int) bool {
+			previousStart, previousEnd := state.capture[2*group], state.capture[2*group+1]
+			state.capture[2*group], state.capture[2*group+1] = start, end
+			if next(end) {
+				return true
+			}
+			state.capture[2*group], state.capture[2*group+1] = previousStart, previousEnd
+			return false
+		})
+	}
+}
+
+// saveRegExpCapture copies the captures of the groups after start, up to and
+// including end.
+func saveRegExpCapture(state *_regExpState, start, end int) []int {
+	saved := make([]int, 2*(end-start))
+	copy(saved, state.capture[2*(start+1):2*(end+1)])
+	return saved
+}
+
+func restoreRegExpCapture(state *_regExpState, start int, saved []int) {
+	copy(state.capture[2*(start+1):], saved)
+}
+
+func (self *_regExpParser) parseAtomEscape() (_regExpTerm, func(rune) bool) {
+	chr := self.peek(0)
+	if '1' <= chr && chr <= '9' {
+		start := self.index
+		group, _ := self.parseInteger()
+		if group > self.totalGroupCount {
+			self.error("invalid escape sequence: `\\%s`", string(self.pattern[start:self.index]))
+		}
+		return self.backreference(group), nil
+	}
+	if match := self.parseClassEscape(); match != nil {
+		return self.character(match)
+	}
+	return self.literal(self.parseCharacterEscape())
+}
+
+func (self *_regExpParser) backreference(group int) _regExpTerm {
+	return func(state *_regExpState, index int, next func(int) bool) bool {
+		state.step()
+		start, end := state.capture[2*group], state.capture[2*group+1]
+		if start == -1 || end == -1 {
+			return next(index) // An undefined group matches the empty string
+		}
+		length := end - start
+		if length > 0 && state.end(index+length-1) {
+			return false
+		}
+		for offset := 0; offset < length; offset++ {
+			if !state.program.equal(state.input[start+offset], state.input[index+offset]) {
+				return false
+			}
+		}
+		return next(index + length)
+	}
+}
+
+// parseClassEscape parses \d, \D, \s, \S, \w, or \W (after the \), or returns nil.
+func (self *_regExpParser) parseClassEscape() func(rune) bool {
+	var match func(rune) bool
+	switch self.peek(0) {
+	case 'd', 'D':
+		match = isRegExpDigit
+	case 's', 'S':
+		match = isRegExpWhiteSpace
+	case 'w', 'W':
+		match = isRegExpWord
+	default:
+		return nil
+	}
+	if chr := self.next(); 'A' <= chr && chr <= 'Z' {
+		return func(chr rune) bool {
+			return !match(chr)
+		}
+	}
+	return match
+}
+
+// parseCharacterEscape parses the character of an escape (after the \), such as
+// \n, \cJ, \x0A, \u000A, or \0.
+func (self *_regExpParser) parseCharacterEscape() rune {
+	chr := self.next()
+	switch chr {
+	case -1:
+		self.error("trailing backslash at end of expression: ``")
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'v':
+		return '\v'
+	case 'c':
+		if letter := self.peek(0); 'a' <= letter && letter <= 'z' || 'A' <= letter && letter <= 'Z' {
+			self.next()
+			return letter % 32
+		}
+	case 'x':
+		if value, ok := self.parseHex(2); ok {
+			return value
+		}
+	case 'u':
+		if value, ok := self.parseHex(4); ok {
+			return value
+		}
+	case '0':
+		if !isRegExpDigit(self.peek(0)) {
+			return 0
+		}
+		self.error("invalid escape sequence: `\\0%s`", string(self.peek(0)))
+	}
+	return chr // An identity escape, e.g. \. or \a
+}
+
+func (self *_regExpParser) parseHex(size int) (rune, bool) {
+	if self.index+size > len(self.pattern) {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(string(self.pattern[self.index:self.index+size]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	self.index += size
+	return rune(value), true
+}
+
+// parseClass parses a character class, e.g. [a-z_] or [^\s], into the match of
+// a character.
+func (self *_regExpParser) parseClass() func(rune) bool {
+	start := self.index
+	self.next() // [
+	negate := false
+	if self.peek(0) == '^' {
+		self.next()
+		negate = true
+	}
+
+	rangeList := [][2]rune{}
+	matchList := []func(rune) bool{}
+	for self.peek(0) != ']' {
+		if self.peek(0) == -1 {
+			self.error("missing closing ]: `%s`", string(self.pattern[start:]))
+		}
+		low, match := self.parseClassAtom()
+		if match == nil && self.peek(0) == '-' && self.peek(1) != ']' && self.peek(1) != -1 {
+			self.next() // -
+			high, highMatch := self.parseClassAtom()
+			if highMatch == nil {
+				if low > high {
+					self.error("invalid character class range: `%s-%s`", string(low), string(high))
+				}
+				rangeList = append(rangeList, [2]rune{low, high})
+				continue
+			}
+			// A class escape (e.g. [a-\d]) is not a range, so the - is a literal
+			rangeList = append(rangeList, [2]rune{low, low}, [2]rune{'-', '-'})
+			matchList = append(matchList, highMatch)
+			continue
+		}
+		if match != nil {
+			matchList = append(matchList, match)
+		} else {
+			rangeList = append(rangeList, [2]rune{low, low})
+		}
+	}
+	self.next() // ]
+
+	contains := func(chr rune) bool {
+		for _, bound := range rangeList {
+			if bound[0] <= chr && chr <= bound[1] {
+				return true
+			}
+		}
+		for _, match := range matchList {
+			if match(chr) {
+				return true
+			}
+		}
+		return false
+	}
+	ignoreCase := self.ignoreCase
+	return func(chr rune) bool {
+		found := contains(chr)
+		if !found && ignoreCase {
+			found = contains(unicode.ToLower(chr)) || contains(regExpCanonicalize(chr))
+		}
+		return found != negate
+	}
+}
+
+// parseClassAtom parses a character, or a class escape (e.g. \d), within a class.
+func (self *_regExpParser) parseClassAtom() (rune, func(rune) bool) {
+	chr := self.next()
+	if chr != '\\' {
+		return chr, nil
+	}
+	switch self.peek(0) {
+	case 'b':
+		self.next()
+		return '\b', nil
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		self.error("invalid escape sequence: `\\%s`", string(self.peek(0)))
+	}
+	if match := self.parseClassEscape(); match != nil {
+		return 0, match
+	}
+	return self.parseCharacterEscape(), nil
+}
+
+// regExpRepeatCharacter repeats the match of a single character, without the
+// recursion (and steps) of regExpRepeat.
+func regExpRepeatCharacter(match func(rune) bool, min, max int, greedy bool) _regExpTerm {
+	return func(state *_regExpState, index int, next func(int) bool) bool {
+		state.step()
+		end := index
+		for !state.end(end) && (max == -1 || end-index < max) && match(state.input[end]) {
+			end += 1
+		}
+		if end-index < min {
+			return false
+		}
+		if greedy {
+			for position := end; position >= index+min; position-- {
+				state.step()
+				if next(position) {
+					return true
+				}
+			}
+		} else {
+			for position := index + min; position <= end; position++ {
+				state.step()
+				if next(position) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// regExpRepeat repeats the atom (with the groups after groupStart, up to and
+// including groupEnd) between min and max (or -1, any number of) times (15.10.2.5).
+func regExpRepeat(atom _regExpTerm, min, max int, greedy bool, groupStart, groupEnd int) _regExpTerm {
+	var repeat func(state *_regExpState, index int, count int, next func(int) bool) bool
+	repeat = func(state *_regExpState, index int, count int, next func(int) bool) bool {
+		state.step()
+		if max != -1 && count >= max {
+			return next(index)
+		}
+		again := func() bool {
+			// The groups of the atom are undefined at the start of each repetition
+			saved := saveRegExpCapture(state, groupStart, groupEnd)
+			for group := groupStart + 1; group <= groupEnd; group++ {
+				state.capture[2*group], state.capture[2*group+1] = -1, -1
+			}
+			if atom(state, index, func(end int) bool {
+				if end == index && count >= min {
+					return false // An empty repetition (which would repeat forever)
+				}
+				return repeat(state, end, count+1, next)
+			}) {
+				return true
+			}
+			restoreRegExpCapture(state, groupStart, saved)
+			return false
+		}
+		if count < min {
+			return again()
+		}
+		if greedy {
+			return again() || next(index)
+		}
+		return next(index) || again()
+	}
+	return func(state *_regExpState, index int, next func(int) bool) bool {
+		return repeat(state, index, 0, next)
+	}
+}
@@ -111,12 +111,21 @@ func (value Value) isReference() bool {
 //
 func (value Value) Call(this Value, argumentList ...interface{}) (Value, error) {
 	result := UndefinedValue()
-	err := catchPanic(func() {
+	err := value.catchPanic(func() {
 		result = value.call(this, argumentList...)
 	})
 	return result, err
 }
 
+// catchPanic will use the runtime of the value (if it is an object) to
+// catch the panic, and catchPanic otherwise.
+func (value Value) catchPanic(function func()) error {
+	if object := value._object(); object != nil {
+		return object.runtime.catchPanic(function)
+	}
+	return catchPanic(function)
+}
+
 func (value Value) call(this Value, argumentList ...interface{}) Value {
 	switch function := value.value.(type) {
 	case *_object:
@@ -127,7 +136,7 @@ func (value Value) call(this Value, argumentList ...interface{}) Value {
 
 func (value Value) constructSafe(this Value, argumentList ...interface{}) (Value, error) {
 	result := UndefinedValue()
-	err := catchPanic(func() {
+	err := value.catchPanic(func() {
 		result = value.construct(this, argumentList...)
 	})
 	return result, err
@@ -674,6 +683,9 @@ func (self Value) export() interface{} {
 		case *_goSliceObject:
 			return value.value.Interface()
 		}
+		if host := object.hostValue(); host != nil {
+			return host
+		}
 		if object.class == "Array" {
 			result := make([]interface{}, 0)
 			lengthValue := object.get("length")
@@ -758,6 +770,9 @@ func (self Value) exportNative() interface{} {
 		case *_goSliceObject:
 			return value.value.Interface()
 		}
+		if host := object.hostValue(); host != nil {
+			return host
+		}
 	}
 
 	return self